@@ -2,51 +2,145 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 
 	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/app"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/config"
 	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/driver/preview"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/led"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/power"
 	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render/post"
 	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/sequence"
-
-	calib "github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render/scenes/calib"
-	grad "github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render/scenes/grad"
-	ocean "github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render/scenes/ocean"
-	solid "github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render/scenes/solid"
 )
 
-type App struct{ core *app.Core }
+type App struct {
+	core *app.Core
+	drv  *preview.Driver     // the UI preview, fed by the "preview" output
+	out  *render.AsyncDriver // drv's writer
+}
 
 func NewApp() *App { return &App{} }
 
 // === Wails-callable methods ===
-// GetParams returns a shallow copy of the current numeric params.
-// Safe for concurrent read thanks to the engine snapshot.
-func (a *App) GetParams() map[string]float64 {
+// GetParams returns the active renderer's parameter schema (plus the post
+// params) with current values, so the UI can build its sliders from it.
+func (a *App) GetParams() []render.ParamInfo {
 	if a.core == nil {
-		return map[string]float64{}
+		return []render.ParamInfo{}
 	}
-	u := a.core.Eng.SnapshotUniforms() // add this thin wrapper if not present
-	if u == nil || u.Params == nil {
-		return map[string]float64{}
+	return a.core.Eng.DescribeParams()
+}
+
+// GetLayerParams is GetParams for an overlay layer.
+func (a *App) GetLayerParams(name string) ([]render.ParamInfo, error) {
+	if a.core == nil {
+		return nil, fmt.Errorf("core not ready")
 	}
-	out := make(map[string]float64, len(u.Params))
-	for k, v := range u.Params {
-		out[k] = v
+	return a.core.Eng.DescribeLayerParams(name)
+}
+
+// ListPostStages returns the registered post stages and their params.
+func (a *App) ListPostStages() []render.PostStageDef {
+	return render.PostStages()
+}
+
+// GetPostChain returns the output's ordered post chain.
+func (a *App) GetPostChain() []render.PostStageConfig {
+	if a.core == nil {
+		return []render.PostStageConfig{}
+	}
+	return a.core.Eng.PostChain()
+}
+
+// SetPostChain replaces the output's post chain.
+func (a *App) SetPostChain(cfg []render.PostStageConfig) error {
+	if a.core == nil {
+		return fmt.Errorf("core not ready")
+	}
+	return a.core.Eng.SetPostChain(cfg)
+}
+
+// SetPostStageParam sets one param on stage index of the post chain.
+func (a *App) SetPostStageParam(index int, key string, v float64) error {
+	if a.core == nil {
+		return fmt.Errorf("core not ready")
+	}
+	return a.core.Eng.SetPostStageParam(index, key, v)
+}
+
+// ListOutputs lists the engine's extra outputs with their counters.
+func (a *App) ListOutputs() []render.OutputInfo {
+	if a.core == nil {
+		return nil
+	}
+	return a.core.Eng.Outputs()
+}
+
+// SetOutput replaces one output's post chain, throttle and flags.
+func (a *App) SetOutput(cfg render.OutputConfig) error {
+	if a.core == nil {
+		return fmt.Errorf("core not ready")
+	}
+	return a.core.Eng.SetOutput(cfg)
+}
+
+// GetCalibration returns the active output calibration profile, or nil.
+func (a *App) GetCalibration() *render.CalibProfile {
+	if a.core == nil {
+		return nil
+	}
+	return a.core.Eng.Calibration()
+}
+
+// SetCalibration installs an output calibration profile (nil removes it).
+func (a *App) SetCalibration(p *render.CalibProfile) error {
+	if a.core == nil {
+		return fmt.Errorf("core not ready")
+	}
+	return a.core.Eng.SetCalibration(p)
+}
+
+// GetPower reports the last frame's estimated and limited supply current.
+func (a *App) GetPower() power.Report {
+	if a.core == nil {
+		return power.Report{}
+	}
+	return a.core.Power.Last()
+}
+
+// ListStrips lists the built-in LED current models config.yaml can name.
+func (a *App) ListStrips() []render.CurrentModel {
+	return render.Strips()
+}
+
+// SetPreviewDither switches temporal dithering on the preview output.
+func (a *App) SetPreviewDither(on bool) {
+	if a.drv != nil {
+		a.drv.SetDither(on)
 	}
-	log.Printf("GetParams -> %+v\n", out)
-	return out
 }
 
 // App API (export via Wails)
+
+// UISetPreview switches the UI between the tone-mapped preview look and
+// the LED look (the main output's chain); the LEDs are not affected.
 func (a *App) UISetPreview(on bool) {
-	v := 0.0
-	if on {
-		v = 1.0
+	if a.core == nil {
+		return
 	}
-	a.core.Eng.SetParam("PreviewMode", v)
+	_ = a.core.Eng.SetOutput(a.previewOutput(on))
+}
+
+// previewOutput is the engine output behind the UI preview: post.Preview
+// when look is set, else a copy of the main chain.
+func (a *App) previewOutput(look bool) render.OutputConfig {
+	cfg := render.OutputConfig{Name: "preview", Post: post.Preview(), Enabled: true, Calib: true}
+	if !look {
+		cfg.Post = a.core.Eng.PostChain()
+	}
+	return cfg
 }
 
 func (a *App) UIRenderPreset(renderer, preset string) error {
@@ -63,11 +157,6 @@ func (a *App) UIResetAll() {
 	}
 	a.core.Seq.Stop()
 	_ = a.core.Eng.SetRenderer("solid", "Black", a.core.Reg)
-
-	// Keep PreviewMode sticky for desktop; don't spam other params.
-	if a.core != nil {
-		a.core.Eng.SetParam("PreviewMode", 1)
-	}
 }
 
 func (a *App) ListRenderers() []string {
@@ -93,6 +182,59 @@ func (a *App) ArmNext(name, preset string) error {
 	return a.core.Eng.ArmNext(name, preset, a.core.Reg)
 }
 
+// AddLayer stacks an overlay renderer above the scene.
+// blend is one of alpha|add|screen|multiply|max; opacity is 0..1.
+func (a *App) AddLayer(name, renderer, preset, blend string, opacity float64) error {
+	log.Printf("AddLayer(%s=%s/%s,%s,%.2f)\n", name, renderer, preset, blend, opacity)
+	if a.core == nil {
+		return fmt.Errorf("core not ready")
+	}
+	m, ok := render.ParseBlendMode(blend)
+	if !ok {
+		return fmt.Errorf("unknown blend mode: %s", blend)
+	}
+	return a.core.Eng.AddLayer(name, renderer, preset, a.core.Reg, m, opacity)
+}
+
+func (a *App) RemoveLayer(name string) error {
+	if a.core == nil {
+		return fmt.Errorf("core not ready")
+	}
+	return a.core.Eng.RemoveLayer(name)
+}
+
+func (a *App) SetLayerOpacity(name string, opacity float64) error {
+	if a.core == nil {
+		return fmt.Errorf("core not ready")
+	}
+	return a.core.Eng.SetLayerOpacity(name, opacity)
+}
+
+func (a *App) SetLayerBlend(name, blend string) error {
+	if a.core == nil {
+		return fmt.Errorf("core not ready")
+	}
+	m, ok := render.ParseBlendMode(blend)
+	if !ok {
+		return fmt.Errorf("unknown blend mode: %s", blend)
+	}
+	return a.core.Eng.SetLayerBlend(name, m)
+}
+
+func (a *App) SetLayerParam(name, key string, val float64) error {
+	if a.core == nil {
+		return fmt.Errorf("core not ready")
+	}
+	return a.core.Eng.SetLayerParam(name, key, val)
+}
+
+func (a *App) ListLayers() []render.LayerInfo {
+	if a.core == nil {
+		return nil
+	}
+	return a.core.Eng.Layers()
+}
+
 func (a *App) SeqCmd(cmd string) error {
 	log.Println("SeqCmd:", cmd)
 	if a.core == nil {
@@ -118,25 +260,39 @@ func (a *App) LoadProgram(jsonStr string) error {
 	if a.core == nil {
 		return fmt.Errorf("core not ready")
 	}
-	var p sequence.Program
-	if err := json.Unmarshal([]byte(jsonStr), &p); err != nil {
-		return err
-	}
-	return a.core.Seq.Load(p)
+	return a.core.LoadProgram([]byte(jsonStr))
 }
 
-func (a *App) SetParam(key string, val float64) {
+// SetParam clamps to the declared range; unknown keys are an error.
+func (a *App) SetParam(key string, val float64) error {
 	log.Printf("SetParam(%s=%v)\n", key, val)
-	if a.core != nil {
-		a.core.Eng.SetParam(key, val)
+	if a.core == nil {
+		return fmt.Errorf("core not ready")
 	}
+	return a.core.Eng.SetParam(key, val)
 }
 
-func (a *App) SetBool(key string, val bool) {
+func (a *App) SetBool(key string, val bool) error {
 	log.Printf("SetBool(%s=%v)\n", key, val)
-	if a.core != nil {
-		a.core.Eng.SetBool(key, val)
+	if a.core == nil {
+		return fmt.Errorf("core not ready")
+	}
+	return a.core.Eng.SetBool(key, val)
+}
+
+// SetScopedParam targets one uniform scope: "global", "post" or a layer name.
+func (a *App) SetScopedParam(scope, key string, val float64) error {
+	if a.core == nil {
+		return fmt.Errorf("core not ready")
+	}
+	return a.core.Eng.SetScopedParam(scope, key, val)
+}
+
+func (a *App) SetScopedBool(scope, key string, val bool) error {
+	if a.core == nil {
+		return fmt.Errorf("core not ready")
 	}
+	return a.core.Eng.SetScopedBool(scope, key, val)
 }
 
 // Convenience for your “Tests” menu
@@ -183,46 +339,71 @@ func (a *App) startup(ctx context.Context) {
 
 	// Preview driver for desktop
 	drv := preview.New(ctx, dim)
+	a.drv = drv
 
 	uniforms := &render.Uniforms{
 		GlobalBrightness: 0.8,
 		TimeScale:        1.0,
 		Params: map[string]float64{
 			"OutputGamma": 2.2,
-			"LEDChan_mA":  25, "Budget_mA": 5000,
+			"LEDChan_mA":  25,
 			"LimiterKnee": 0.9, "WhiteCap": 3.0,
 			"GlobalBrightness": 1.0,
 		},
 		Bools: map[string]bool{},
 	}
 
-	registrar := func(reg *render.Registry) {
-		reg.Register(solid.New("solid", render.Color{R: 1})) // 🔴 solid red
-		reg.Register(grad.New("grad"))                       // 🌈 gradient
-		reg.Register(calib.New("calib"))
-		reg.Register(ocean.New("ocean"))
+	// Power budget from config.yaml when present (same file as the headless server)
+	var pcfg power.Config
+	order := ""
+	if c, err := config.Load("config.yaml"); err == nil {
+		order = c.ColorOrder
+		if pcfg, err = power.FromConfig(c.Power); err != nil {
+			log.Printf("config.yaml power: %v; no power limits", err)
+			pcfg = power.Config{}
+		}
+		// RGBW strips: preview what the white die shows, estimate its current
+		if w, err := app.WhiteFromConfig(c.White); err != nil {
+			log.Printf("config.yaml white: %v; using the min split", err)
+		} else {
+			pcfg = pcfg.WithWhite(w)
+			if o, err := led.ParseOrder(c.ColorOrder); err == nil && o.Layout() == render.LayoutRGBW {
+				drv.ShowWhite(&w)
+			}
+		}
+	}
+
+	// The main output is the LED frame (LED chain, power limits) on a
+	// simulated strip; the UI watches the "preview" output added below.
+	leds, err := led.NewAdapter(led.NewSim(dim.X*dim.Y*dim.Z, order))
+	if err != nil {
+		log.Printf("config.yaml color_order: %v; simulating RGB", err)
+		leds, _ = led.NewAdapter(led.NewSim(dim.X*dim.Y*dim.Z, "RGB"))
 	}
 
 	core, err := app.InitCore(ctx, app.HWConfig{
-		Dim: dim,
-		Drv: drv, // 👈 no LEDs required
+		Dim:   dim,
+		Drv:   leds, // 👈 no LEDs required
+		Power: pcfg,
 		// TODO: wire your Order/Pitch/Gap as needed for BuildLUT
-	}, "solid", uniforms, &render.Resources{}, registrar)
+	}, "solid", uniforms, &render.Resources{}, app.RegisterDefaultRenderers)
 	if err != nil {
 		panic(err)
 	}
 
 	a.core = core
 
-	// Desktop preview: bypass Filmic/Limiter by default
-	a.core.Eng.SetParam("PreviewMode", 1) // desktop sim
-	a.core.Eng.SetParam("ExposureEV", 2)  // modest lift
+	// The UI preview gets its own tone-mapped chain; emits off the render loop
+	a.out = render.NewAsyncDriver(drv, render.WriteDrop)
+	if err := a.core.Eng.AddOutput(a.previewOutput(true), a.out); err != nil {
+		panic(err)
+	}
+	a.core.Eng.SetParam("ExposureEV", 2) // modest lift
 	a.core.Eng.SetParam("OutputGamma", 2.2)
 
-	// sensible LED caps (ignored in preview)
+	// sensible LED caps (the LED chain only; the preview look has no limiter)
 	a.core.Eng.SetParam("WhiteCap", 2.2)
 	a.core.Eng.SetParam("LEDChan_mA", 20)
-	a.core.Eng.SetParam("Budget_mA", 3000)
 	a.core.Eng.SetParam("LimiterKnee", 0.9)
 
 	// ⛔️ Don’t auto-start the sequencer in desktop:
@@ -244,6 +425,14 @@ func (a *App) startup(ctx context.Context) {
 
 func (a *App) shutdown(ctx context.Context) {
 	if a.core != nil {
-		a.core.Seq.Stop()
+		a.core.Close() // returns once the frame loop has stopped
+		if drv := a.core.Eng.Drv; drv != nil {
+			_ = drv.Blank()
+			_ = drv.Close()
+		}
+		if a.out != nil {
+			_ = a.out.Blank()
+			_ = a.out.Close() // stops the async writer
+		}
 	}
 }
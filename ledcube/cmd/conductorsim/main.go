@@ -56,8 +56,8 @@ func (c *Conductor) Run(ctx context.Context, fps int) {
 func main() {
 	// Registry with fake renderers
 	reg := render.NewRegistry()
-	reg.Register(solid.New("solid", render.Color{R:1, G:0, B:0}))
-	reg.Register(grad.New("grad"))
+	reg.Register("solid", func() render.Renderer { return solid.New("solid", render.Color{R:1, G:0, B:0}) })
+	reg.Register("grad", func() render.Renderer { return grad.New("grad") })
 
 	// Fake hardware config
 	dim := render.Dimensions{X: 5, Y: 5, Z: 5}
@@ -67,7 +67,7 @@ func main() {
 	// Engine + post
 	u := &render.Uniforms{GlobalBrightness: 1, TimeScale: 1, Params: map[string]float64{"OutputGamma":2.2, "ExposureEV":0}, Bools: map[string]bool{}}
 	eng, _ := render.NewEngine(dim, lut, drv, mustGet(reg, "solid"), u, &render.Resources{})
-	eng.UseFilmicPost()
+	_ = eng.SetPostChain(render.DefaultPostChain())
 
 	// Conductor
 	c := NewConductor(eng, reg)
@@ -93,7 +93,7 @@ func main() {
 }
 
 func mustGet(reg *render.Registry, name string) render.Renderer {
-	rr, ok := reg.Get(name)
+	rr, ok := reg.New(name)
 	if !ok { panic("renderer not in registry: " + name) }
 	return rr
 }
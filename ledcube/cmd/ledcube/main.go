@@ -13,9 +13,13 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/app"
 	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/config"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/dmx"
 	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/layout"
 	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/led"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/power"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
 	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/ws"
 )
 
@@ -31,12 +35,15 @@ func main() {
 		panelGapMM = flag.Float64("panel-gap-mm", 50, "panel gap (mm) along Z")
 		fps        = flag.Int("fps", 60, "target frames per second")
 		brightness = flag.Float64("brightness", 0.8, "global brightness 0..1")
-		driver     = flag.String("driver", "spi", "driver: spi | pwm | sim")
+		driver     = flag.String("driver", "spi", "driver: spi | pwm | apa102 | sk9822 | serial | artnet | sacn | sim")
 		gpio       = flag.Int("gpio", 18, "PWM data pin (BCM number) for rpi_ws281x")
 		colorOrder = flag.String("color", "GRB", "LED color order (e.g. GRB, RGB)")
 		addr       = flag.String("addr", ":8080", "HTTP listen address")
 		configPath = flag.String("config", "config.yaml", "path to config.yaml")
 		simOnly    = flag.Bool("sim-only", false, "force simulation (no hardware output)")
+		renderer   = flag.String("renderer", "ocean", "renderer to start with (solid | grad | calib | ocean)")
+		preset     = flag.String("preset", "CalmDawn", "preset for -renderer")
+		program    = flag.String("program", "", "optional Program JSON to load and start at boot")
 	)
 	flag.Parse()
 
@@ -98,6 +105,10 @@ func main() {
 	// ---- State ----
 	state := ws.NewState(l, eFPS, eBright, *simOnly)
 	state.ConfigPath = *configPath
+	state.Power = config.DefaultPower()
+	if cfg != nil {
+		state.Power = cfg.Power
+	}
 
 	// ---- Driver selection: -sim-only overrides; otherwise config.driver then -driver ----
 	selected := *driver
@@ -108,19 +119,24 @@ func main() {
 		selected = "sim"
 	}
 
+	spiDev := "/dev/spidev0.0"
+	if cfg != nil && cfg.SPI.Dev != "" {
+		spiDev = cfg.SPI.Dev
+	}
+	var (
+		drv render.Driver
+		err error
+	)
 	switch selected {
 	case "sim":
-		state.Driver = led.NewSim()
+		drv, err = adapt(led.NewSim(l.Count(), eColor), nil)
 
 	case "spi":
-		// Defaults if YAML not filled
-		spiDev := "/dev/spidev0.0"
-		speedHz := 2400000
+		// Defaults if YAML not filled (speed: led.NewSPI picks it per
+		// strip type)
+		speedHz := 0
 		resetUs := 300
 		if cfg != nil {
-			if cfg.SPI.Dev != "" {
-				spiDev = cfg.SPI.Dev
-			}
 			if cfg.SPI.SpeedHz != 0 {
 				speedHz = cfg.SPI.SpeedHz
 			}
@@ -128,29 +144,102 @@ func main() {
 				resetUs = cfg.SPI.ResetUs
 			}
 		}
-		drv, err := led.NewSPI(spiDev, l.Count(), eColor, speedHz, resetUs)
-		if err != nil {
-			log.Warn().Err(err).
-				Str("driver", "spi").
-				Str("dev", spiDev).
-				Int("speed_hz", speedHz).
-				Msg("SPI init failed; falling back to SIM")
-			state.Driver = led.NewSim()
-		} else {
-			state.Driver = drv
+		if cfg != nil && len(cfg.Outputs) > 0 {
+			// one bus per outputs entry, written in parallel
+			drv, err = adapt(app.SPIBuses(cfg, render.Dimensions{X: l.Dim.X, Y: l.Dim.Y, Z: l.Dim.Z}, eColor))
+			break
 		}
+		drv, err = adapt(led.NewSPI(spiDev, l.Count(), eColor, speedHz, resetUs))
 
 	case "pwm":
-		// Avoid compile errors if PWM isn't built in this snapshot.
-		_ = gpio // keep flag in place without unused warning
-		log.Warn().Msg("driver=pwm requested, but PWM is not compiled in this build; using SIM instead")
-		state.Driver = led.NewSim()
+		pin := *gpio
+		if cfg != nil && cfg.GPIO != 0 {
+			pin = cfg.GPIO
+		}
+		drv, err = adapt(led.NewPWM(pin, l.Count(), eColor))
+
+	case led.VariantAPA102, led.VariantSK9822:
+		// clocked LEDs: own color order (BGR by default) and speed
+		var ac config.APA102Cfg
+		if cfg != nil {
+			ac = cfg.APA102
+		}
+		drv, err = led.NewAPA102(spiDev, l.Count(), ac.ColorOrder, ac.SpeedHz, selected)
+
+	case "serial":
+		// USB-serial controller (Adalight or TPM2 firmware)
+		var sc config.SerialCfg
+		if cfg != nil {
+			sc = cfg.Serial
+		}
+		if sc.Dev == "" {
+			sc.Dev = "/dev/ttyUSB0"
+		}
+		drv, err = adapt(led.NewSerial(sc.Dev, l.Count(), sc.ColorOrder, sc.Baud, sc.Protocol))
+
+	case dmx.ArtNet, dmx.SACN:
+		// remote pixel controllers over the network
+		var dc config.DMXCfg
+		if cfg != nil {
+			dc = cfg.DMX
+		}
+		drv, err = adapt(app.DMXSender(selected, dc, render.Dimensions{X: l.Dim.X, Y: l.Dim.Y, Z: l.Dim.Z}))
 
 	default:
 		log.Warn().Str("driver", selected).Msg("unknown driver; using SIM")
-		state.Driver = led.NewSim()
+		drv, err = adapt(led.NewSim(l.Count(), eColor), nil)
+	}
+	state.Driver = openDriver(selected, drv, err, l.Count(), eColor)
+	state.ColorOrder = eColor
+	if cfg != nil {
+		state.Writer, state.Outputs, state.APA102, state.Serial = cfg.Writer, cfg.Outputs, cfg.APA102, cfg.Serial
+		state.DMX = cfg.DMX
+		state.SPI = cfg.SPI
+		if _, err := app.WhiteFromConfig(cfg.White); err != nil {
+			log.Warn().Err(err).Msg("config white section rejected; using the min split")
+		} else {
+			state.White = cfg.White
+		}
 	}
+	policy, err := render.ParseWritePolicy(state.Writer.Policy)
+	if err != nil {
+		log.Warn().Err(err).Msg("config writer section rejected; dropping late frames")
+		policy = render.WriteDrop
+	}
+	state.Driver = render.WithWritePolicy(state.Driver, policy)
 	state.CurrentDriver = selected
+	if cfg != nil {
+		state.DitherCfg = cfg.Dither
+	}
+	state.ApplyDither()
+
+	// ---- Render engine + sequencer (same catalog as the desktop app) ----
+	state.NewCore = func(l layout.Layout) (*app.Core, error) {
+		return newCore(l, *renderer, *preset, cfg)
+	}
+	core, err := state.NewCore(l)
+	if err != nil {
+		log.Fatal().Err(err).Msg("render engine init failed")
+	}
+	if *program != "" {
+		if err := loadProgram(core, *program); err != nil {
+			log.Warn().Err(err).Str("program", *program).Msg("program load failed; holding start renderer")
+		} else {
+			core.Seq.Start()
+		}
+	}
+	core.Power.SetOutputGain(state.Brightness)
+	state.SetCore(core)
+
+	// ---- Console input (Art-Net / sACN) ----
+	if cfg != nil && cfg.DMXIn.Mode != "" {
+		state.DMXIn = cfg.DMXIn
+		if err := state.StartDMXInput(); err != nil {
+			log.Warn().Err(err).Msg("config dmx_in section rejected; console input off")
+		} else {
+			log.Info().Str("mode", cfg.DMXIn.Mode).Msg("listening for a lighting console")
+		}
+	}
 
 	// ---- HTTP routes ----
 	mux := http.NewServeMux()
@@ -184,11 +273,106 @@ func main() {
 	log.Info().Str("signal", s.String()).Msg("shutting down")
 
 	_ = srv.Close()
+	state.Stop() // no frame may reach the driver after Blank
+	if state.Core != nil {
+		state.Core.Close()
+	}
 	if state.Driver != nil {
+		_ = state.Driver.Blank()
 		_ = state.Driver.Close()
 	}
 }
 
+// adapt wraps a byte transport (built with err) in the shared quantizer.
+func adapt(dev led.ByteDriver, err error) (render.Driver, error) {
+	if err != nil {
+		return nil, err
+	}
+	return led.NewAdapter(dev)
+}
+
+// openDriver opens drv (built with err), falling back to the simulator when
+// either step fails.
+func openDriver(name string, drv render.Driver, err error, count int, colorOrder string) render.Driver {
+	if err == nil {
+		err = drv.Open()
+	}
+	if err == nil {
+		return drv
+	}
+	log.Warn().Err(err).Str("driver", name).Msg("driver init failed; falling back to SIM")
+	a, err := led.NewAdapter(led.NewSim(count, colorOrder))
+	if err != nil {
+		a, _ = led.NewAdapter(led.NewSim(count, "RGB"))
+	}
+	return a
+}
+
+// newCore builds the engine for l with LED-path post (limiter on, no preview)
+// and the config's post chain, if any.
+func newCore(l layout.Layout, renderer, preset string, cfg *config.Config) (*app.Core, error) {
+	uniforms := &render.Uniforms{
+		GlobalBrightness: 1.0,
+		TimeScale:        1.0,
+		Params:           map[string]float64{},
+		Bools:            map[string]bool{},
+	}
+	pc := config.DefaultPower()
+	if cfg != nil {
+		pc = cfg.Power
+	}
+	pcfg, err := power.FromConfig(pc)
+	if err != nil {
+		log.Warn().Err(err).Msg("config power section rejected; using defaults")
+		pcfg, _ = power.FromConfig(config.DefaultPower())
+	}
+	if cfg != nil {
+		if w, err := app.WhiteFromConfig(cfg.White); err == nil {
+			pcfg = pcfg.WithWhite(w)
+		}
+	}
+	core, err := app.NewCore(app.HWConfig{
+		Dim:     render.Dimensions{X: l.Dim.X, Y: l.Dim.Y, Z: l.Dim.Z},
+		Order:   led.Order{XFlipEveryRow: l.Order.XFlipEveryRow, YFlipEveryPanel: l.Order.YFlipEveryPanel},
+		PitchMM: l.PitchMM,
+		GapMM:   l.PanelGapMM,
+		Power:   pcfg,
+	}, renderer, uniforms, &render.Resources{}, app.RegisterDefaultRenderers)
+	if err != nil {
+		return nil, err
+	}
+	if err := core.Eng.SetRenderer(renderer, preset, core.Reg); err != nil {
+		log.Warn().Err(err).Str("renderer", renderer).Msg("start renderer unavailable; using registry default")
+	}
+	if cfg != nil && len(cfg.Post) > 0 {
+		chain := make([]render.PostStageConfig, 0, len(cfg.Post))
+		for _, st := range cfg.Post {
+			chain = append(chain, render.PostStageConfig{Stage: st.Stage, Params: st.Params})
+		}
+		if err := core.Eng.SetPostChain(chain); err != nil {
+			log.Warn().Err(err).Msg("config post chain rejected; using default chain")
+		}
+	}
+	if cfg != nil && cfg.Calibration != nil {
+		p, err := app.CalibFromConfig(cfg.Calibration)
+		if err == nil {
+			err = core.Eng.SetCalibration(p)
+		}
+		if err != nil {
+			log.Warn().Err(err).Msg("config calibration rejected; output uncalibrated")
+		}
+	}
+	return core, nil
+}
+
+func loadProgram(core *app.Core, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return core.LoadProgram(b)
+}
+
 func withCORS(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
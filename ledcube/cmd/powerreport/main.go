@@ -0,0 +1,161 @@
+// Command powerreport renders a whole program offline, faster than real time,
+// through the same engine, post chain and power manager as the headless
+// server, and prints what the supply would see: idle, peak and mean current,
+// time spent limiting per zone and thermal throttling per panel.
+//
+//	go run ./cmd/powerreport -config config.yaml -program show.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/app"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/config"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/power"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/sequence"
+)
+
+func main() {
+	var (
+		configPath = flag.String("config", "config.yaml", "path to config.yaml (dim, power, post, calibration)")
+		program    = flag.String("program", "", "Program JSON (seq.v1 or seq.v2)")
+		fps        = flag.Int("fps", 30, "simulated frames per second")
+		seconds    = flag.Float64("seconds", 0, "length to simulate; 0 = one pass of the program")
+		brightness = flag.Float64("brightness", 0, "output brightness; 0 = config value or 1")
+		asJSON     = flag.Bool("json", false, "print the summary as JSON")
+	)
+	flag.Parse()
+	if *program == "" {
+		log.Fatal("provide -program path to a Program JSON")
+	}
+	if *fps <= 0 {
+		*fps = 30
+	}
+
+	cfg := &config.Config{Power: config.DefaultPower()}
+	if c, err := config.Load(*configPath); err != nil {
+		log.Printf("config %s: %v; using defaults", *configPath, err)
+	} else {
+		cfg = c
+	}
+	dim := render.Dimensions{X: 5, Y: 26, Z: 5}
+	if cfg.Dim.X > 0 && cfg.Dim.Y > 0 && cfg.Dim.Z > 0 {
+		dim = render.Dimensions{X: cfg.Dim.X, Y: cfg.Dim.Y, Z: cfg.Dim.Z}
+	}
+	pcfg, err := power.FromConfig(cfg.Power)
+	if err != nil {
+		log.Fatalf("power: %v", err)
+	}
+	white, err := app.WhiteFromConfig(cfg.White)
+	if err != nil {
+		log.Fatalf("white: %v", err)
+	}
+	pcfg = pcfg.WithWhite(white)
+
+	uniforms := &render.Uniforms{GlobalBrightness: 1, TimeScale: 1, Params: map[string]float64{}, Bools: map[string]bool{}}
+	core, err := app.NewCore(app.HWConfig{Dim: dim, Power: pcfg}, "solid", uniforms, &render.Resources{}, app.RegisterDefaultRenderers)
+	if err != nil {
+		log.Fatalf("engine: %v", err)
+	}
+	if len(cfg.Post) > 0 {
+		chain := make([]render.PostStageConfig, 0, len(cfg.Post))
+		for _, st := range cfg.Post {
+			chain = append(chain, render.PostStageConfig{Stage: st.Stage, Params: st.Params})
+		}
+		if err := core.Eng.SetPostChain(chain); err != nil {
+			log.Fatalf("post chain: %v", err)
+		}
+	}
+	if cfg.Calibration != nil {
+		p, err := app.CalibFromConfig(cfg.Calibration)
+		if err == nil {
+			err = core.Eng.SetCalibration(p)
+		}
+		if err != nil {
+			log.Fatalf("calibration: %v", err)
+		}
+	}
+	gain := *brightness
+	if gain <= 0 {
+		gain = cfg.Brightness
+	}
+	if gain <= 0 {
+		gain = 1
+	}
+	core.Power.SetOutputGain(gain)
+
+	data, err := os.ReadFile(*program)
+	if err != nil {
+		log.Fatalf("read program: %v", err)
+	}
+	if err := core.LoadProgram(data); err != nil {
+		log.Fatalf("program: %v", err)
+	}
+	length := *seconds
+	if length <= 0 {
+		prog, _ := sequence.ParseProgram(data) // already validated by LoadProgram
+		for _, c := range prog.Clips {
+			length += c.DurationS
+		}
+	}
+
+	// simulated time for the sequencer, the renderers and the power manager
+	t0 := time.Unix(0, 0)
+	now := t0
+	core.Power.SetClock(func() time.Time { return now })
+	core.Seq.Start()
+
+	var sum power.Summary
+	dt := 1 / float64(*fps)
+	frames := int(length * float64(*fps))
+	for i := 0; i < frames; i++ {
+		t := float64(i) * dt
+		now = t0.Add(time.Duration(t * float64(time.Second)))
+		core.Seq.Tick(dt)
+		if err := core.Eng.RenderOnce(t); err != nil {
+			log.Fatalf("frame %d: %v", i, err)
+		}
+		sum.Add(core.Power.Last(), dt)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(sum)
+		return
+	}
+	printSummary(sum, pcfg.LimitAmps)
+}
+
+func printSummary(s power.Summary, limitA float64) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "frames\t%d (%.1f s)\n", s.Frames, s.Seconds)
+	fmt.Fprintf(w, "strip\t%s\n", s.Strip)
+	fmt.Fprintf(w, "idle\t%.2f A\n", s.IdleAmps)
+	fmt.Fprintf(w, "estimated\tpeak %.2f A\tmean %.2f A\n", s.PeakEstimatedAmps, s.MeanEstimatedAmps)
+	fmt.Fprintf(w, "sent\tpeak %.2f A\tmean %.2f A\t%.3f Ah\n", s.PeakLimitedAmps, s.MeanLimitedAmps, s.Ah)
+	if limitA > 0 {
+		fmt.Fprintf(w, "limit %.1f A\tlimiting %.1f s\t(%.0f%%)\n", limitA, s.LimitingS, pct(s.LimitingS, s.Seconds))
+	}
+	for _, z := range s.Zones {
+		fmt.Fprintf(w, "zone %s\tpeak %.2f A\tmean %.2f A\tlimiting %.1f s (limit %.1f A)\n", z.Name, z.PeakLimitedAmps, z.MeanLimitedAmps, z.LimitingS, z.LimitAmps)
+	}
+	for _, p := range s.Panels {
+		fmt.Fprintf(w, "panel %d\tpeak avg %.2f A\tmin scale %.2f\tthrottling %.1f s\n", p.Panel, p.PeakAvgAmps, p.MinScale, p.ThrottlingS)
+	}
+	_ = w.Flush()
+}
+
+func pct(part, whole float64) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return 100 * part / whole
+}
@@ -3,7 +3,6 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -17,7 +16,7 @@ import (
 func main() {
 	var programPath string
 	var fps int
-	flag.StringVar(&programPath, "program", "", "Path to Program JSON (seq.v1)")
+	flag.StringVar(&programPath, "program", "", "Path to Program JSON (seq.v1 or seq.v2)")
 	flag.IntVar(&fps, "fps", 60, "Simulation frames per second")
 	flag.Parse()
 
@@ -30,39 +29,10 @@ func main() {
 		log.Fatalf("read program: %v", err)
 	}
 
-	// Parse minimally: We only need fields used by the player;
-	// Envelopes/Params are typically loaded by your higher-level config layer.
-	type ProgIn struct {
-		Version string `json:"version"`
-		Loop    bool   `json:"loop"`
-		Seed    int64  `json:"seed"`
-		Clips   []struct {
-			Name      string  `json:"name"`
-			Renderer  string  `json:"renderer"`
-			Preset    string  `json:"preset"`
-			DurationS float64 `json:"durationS"`
-			XFadeS    float64 `json:"xFadeS"`
-		} `json:"clips"`
-	}
-	var in ProgIn
-	if err := json.Unmarshal(data, &in); err != nil {
-		log.Fatalf("json: %v", err)
-	}
-
-	var prog sequence.Program
-	prog.Version = in.Version
-	prog.Loop = in.Loop
-	prog.Seed = in.Seed
-	for _, c := range in.Clips {
-		prog.Clips = append(prog.Clips, sequence.Clip{
-			Name:      c.Name,
-			Renderer:  c.Renderer,
-			Preset:    c.Preset,
-			DurationS: c.DurationS,
-			XFadeS:    c.XFadeS,
-			Params:    map[string]sequence.Envelope{},
-			Bools:     map[string]sequence.Envelope{},
-		})
+	// Validates against docs/seq.v1|v2.schema.json; v2 clips carry their envelopes.
+	prog, err := sequence.ParseProgram(data)
+	if err != nil {
+		log.Fatalf("program: %v", err)
 	}
 
 	// simple logger hooks
@@ -76,8 +46,12 @@ func main() {
 		SetCrossfade: func(alpha float64) {
 			fmt.Printf("[Crossfade] alpha=%.3f\n", alpha)
 		},
-		SetParam: func(name string, v float64) {},
-		SetBool: func(name string, b bool) {},
+		SetParam: func(name string, v float64) {
+			fmt.Printf("[SetParam] %s=%.3f\n", name, v)
+		},
+		SetBool: func(name string, b bool) {
+			fmt.Printf("[SetBool] %s=%v\n", name, b)
+		},
 	}
 	player := sequence.NewPlayer(h)
 	if err := player.Load(prog); err != nil {
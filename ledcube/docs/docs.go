@@ -0,0 +1,8 @@
+// Package docs embeds the published program schemas so the sequence loader
+// validates against exactly the files documented here.
+package docs
+
+import "embed"
+
+//go:embed seq.v1.schema.json seq.v2.schema.json
+var Schemas embed.FS
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/led"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/power"
 	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
 	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/sequence"
 )
@@ -14,7 +15,9 @@ type Core struct {
 	Eng    *render.Engine
 	Reg    *render.Registry
 	Seq    *sequence.Player
+	Power  *power.Manager
 	cancel context.CancelFunc
+	done   chan struct{} // closed when the InitCore frame loop returns
 }
 
 type HWConfig struct {
@@ -23,11 +26,11 @@ type HWConfig struct {
 	PitchMM float64
 	GapMM   float64
 	Drv     render.Driver
+	Power   power.Config // supply budget; zero = no limit, no soft start
 }
 
 func applyPostDefaults(eng *render.Engine) {
 	for k, v := range map[string]float64{
-		"Budget_mA":   3000,
 		"LEDChan_mA":  20,
 		"LimiterKnee": 0.9,
 		"WhiteCap":    2.2,
@@ -38,29 +41,32 @@ func applyPostDefaults(eng *render.Engine) {
 	}
 }
 
-func InitCore(
-	ctx context.Context,
+// NewCore builds the registry, engine (filmic + limiter post) and sequencer
+// without starting a frame loop. Callers either drive it with Tick from their
+// own loop (headless server) or hand it to Run.
+// A nil registrar registers the default renderer catalog.
+func NewCore(
 	hw HWConfig,
 	startRenderer string,
 	uniforms *render.Uniforms,
 	resources *render.Resources,
 	registrar func(*render.Registry),
 ) (*Core, error) {
-	// 1) Registry (register your real renderers elsewhere & import here)
+	// 1) Registry
 	reg := render.NewRegistry()
-	// 👇 Register anything the caller wants (e.g., solid, grad)
-	if registrar != nil {
-		registrar(reg)
+	if registrar == nil {
+		registrar = RegisterDefaultRenderers
 	}
+	registrar(reg)
 
-	rr, ok := reg.Get(startRenderer)
+	rr, ok := reg.New(startRenderer)
 	if !ok {
 		// Fallback to any renderer in the registry
 		names := reg.List()
 		if len(names) == 0 {
 			return nil, fmt.Errorf("no renderers registered")
 		}
-		rr, _ = reg.Get(names[0])
+		rr, _ = reg.New(names[0])
 	}
 
 	// 2) LUT from your physical layout
@@ -72,41 +78,140 @@ func InitCore(
 		return nil, err
 	}
 
-	// 4) Post pipeline (filmic + limiter) — see next section
-	eng.UseFilmicPost() // <— tiny helper (shown below)
+	// 4) Post chain (exposure → ACES → gamma → limiter → clamp)
+	if err := eng.SetPostChain(render.DefaultPostChain()); err != nil {
+		return nil, err
+	}
 	// Safer than touching the map directly:
 	applyPostDefaults(eng)
 
-	// Desktop preview defaults: limiter OFF, filmic ON, gamma ON
-	eng.SetParam("PreviewMode", 1)   // limiter off, tonemap on
-	eng.SetParam("ExposureEV", 1.5)  // slightly less hot than 2
-	eng.SetParam("OutputGamma", 2.2)
-
 	// 5) Sequencer wiring (hooks → engine)
 	hooks := sequence.Hooks{
 		SetRenderer:  func(name, preset string) { _ = eng.SetRenderer(name, preset, reg) },
 		ArmNext:      func(name, preset string) { _ = eng.ArmNext(name, preset, reg) },
 		SetCrossfade: func(a float64) { eng.SetCrossfade(a) },
-		SetParam:     eng.SetParam,
-		SetBool:      eng.SetBool,
+		SetTransition: func(t *sequence.Transition) {
+			eng.SetTransition(RenderTransition(t))
+		},
+		// programs are validated on load; live clamping covers the rest
+		SetParam:       func(k string, v float64) { _ = eng.SetParam(k, v) },
+		SetBool:        func(k string, b bool) { _ = eng.SetBool(k, b) },
+		SetScopedParam: func(scope, k string, v float64) { _ = eng.SetScopedParam(scope, k, v) },
+		SetScopedBool:  func(scope, k string, b bool) { _ = eng.SetScopedBool(scope, k, b) },
 	}
 	seq := sequence.NewPlayer(hooks)
 
-	// 6) Frame/timeline loop
+	// 6) Power: white cap, soft start and amp limit on the final frame
+	pm, err := power.New(hw.Power, hw.Dim)
+	if err != nil {
+		return nil, err
+	}
+	eng.SetFrameLimiter(pm)
+	core := &Core{Eng: eng, Reg: reg, Seq: seq, Power: pm}
+	core.shareModel(hw.Power)
+
+	return core, nil
+}
+
+// SetPower swaps the power settings and hands the LED model to the engine's
+// limiter stage, so both estimate with the same strip.
+func (c *Core) SetPower(cfg power.Config) error {
+	if err := c.Power.SetConfig(cfg); err != nil {
+		return err
+	}
+	c.shareModel(cfg)
+	return nil
+}
+
+func (c *Core) shareModel(cfg power.Config) {
+	if cfg.Model.IsZero() {
+		c.Eng.SetCurrentModel(nil)
+		return
+	}
+	c.Eng.SetCurrentModel(&cfg.Model)
+}
+
+// RenderTransition converts a program transition into the engine's spatial mask.
+// nil (or "fade") yields nil, the uniform crossfade.
+func RenderTransition(t *sequence.Transition) *render.Transition {
+	if t == nil || t.Kind == "" || t.Kind == string(render.TransFade) {
+		return nil
+	}
+	tr := &render.Transition{
+		Kind:     render.TransitionKind(t.Kind),
+		Normal:   render.WipeNormal(t.Axis, t.AzimuthDeg, t.ElevationDeg),
+		Center:   render.Vec3{X: 0.5, Y: 0.5, Z: 0.5},
+		Softness: t.Softness,
+		Seed:     t.Seed,
+		Reverse:  t.Reverse,
+	}
+	if len(t.Center) == 3 {
+		tr.Center = render.Vec3{X: t.Center[0], Y: t.Center[1], Z: t.Center[2]}
+	}
+	return tr
+}
+
+// InitCore builds a Core with desktop preview defaults and starts a 60 FPS
+// frame/timeline loop that runs until ctx is cancelled or Close is called.
+func InitCore(
+	ctx context.Context,
+	hw HWConfig,
+	startRenderer string,
+	uniforms *render.Uniforms,
+	resources *render.Resources,
+	registrar func(*render.Registry),
+) (*Core, error) {
+	core, err := NewCore(hw, startRenderer, uniforms, resources, registrar)
+	if err != nil {
+		return nil, err
+	}
+
+	// Desktop defaults: scenes write linear light and each output's chain
+	// adds its look (tone map and gamma for the preview)
+	core.Eng.SetParam("PreviewMode", 1)
+	core.Eng.SetParam("ExposureEV", 1.5) // slightly less hot than 2
+	core.Eng.SetParam("OutputGamma", 2.2)
+
 	ctx, cancel := context.WithCancel(ctx)
+	core.cancel, core.done = cancel, make(chan struct{})
 	go func() {
-		tick := time.NewTicker(time.Second / 60)
-		defer tick.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-tick.C:
-				seq.Tick(1.0 / 60.0)
-				_ = eng.RenderOnce(-1) // uses eng.Now()
-			}
-		}
+		defer close(core.done)
+		core.Run(ctx, 60)
 	}()
 
-	return &Core{Eng: eng, Reg: reg, Seq: seq, cancel: cancel}, nil
+	return core, nil
+}
+
+// Tick advances the sequencer by dt seconds and renders one frame.
+func (c *Core) Tick(dt float64) error {
+	c.Seq.Tick(dt)
+	return c.Eng.RenderOnce(-1) // uses eng.Now()
+}
+
+// Run drives Tick at fps until ctx is cancelled.
+func (c *Core) Run(ctx context.Context, fps int) {
+	if fps <= 0 {
+		fps = 60
+	}
+	tick := time.NewTicker(time.Second / time.Duration(fps))
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			_ = c.Tick(1.0 / float64(fps))
+		}
+	}
+}
+
+// Close stops the sequencer and any frame loop started by InitCore, and
+// waits for that loop's last frame, so the caller can blank and close the
+// driver.
+func (c *Core) Close() {
+	c.Seq.Stop()
+	if c.cancel != nil {
+		c.cancel()
+		<-c.done
+	}
 }
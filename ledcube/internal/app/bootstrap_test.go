@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+// slowDriver takes a while per frame and notes writes after Close.
+type slowDriver struct {
+	writing, late atomic.Bool
+	closed        atomic.Bool
+	frames        atomic.Int32
+}
+
+func (d *slowDriver) Caps() render.Caps { return render.Caps{} }
+func (d *slowDriver) Open() error       { return nil }
+func (d *slowDriver) Blank() error      { return nil }
+func (d *slowDriver) Close() error      { d.closed.Store(true); return nil }
+func (d *slowDriver) Write(_ []render.Color) error {
+	if d.closed.Load() {
+		d.late.Store(true)
+	}
+	d.writing.Store(true)
+	time.Sleep(10 * time.Millisecond)
+	d.writing.Store(false)
+	d.frames.Add(1)
+	return nil
+}
+
+func TestCoreCloseWaitsForFrameLoop(t *testing.T) {
+	drv := &slowDriver{}
+	core, err := InitCore(context.Background(), HWConfig{Dim: render.Dimensions{X: 2, Y: 2, Z: 2}, Drv: drv},
+		"solid", &render.Uniforms{GlobalBrightness: 1, TimeScale: 1}, &render.Resources{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for drv.frames.Load() < 2 || !drv.writing.Load() {
+		time.Sleep(100 * time.Microsecond)
+	}
+	core.Close()
+	if drv.writing.Load() {
+		t.Fatal("frame loop still writing after Close")
+	}
+	_ = drv.Close()
+	time.Sleep(40 * time.Millisecond)
+	if drv.late.Load() {
+		t.Fatal("frame written after Close returned")
+	}
+}
+
+func TestTimeScaleIsGlobalUnderOcean(t *testing.T) {
+	core, err := NewCore(HWConfig{Dim: render.Dimensions{X: 2, Y: 2, Z: 2}}, "ocean",
+		&render.Uniforms{GlobalBrightness: 1, TimeScale: 1}, &render.Resources{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := core.Eng.SetParam("TimeScale", 2); err != nil {
+		t.Fatal(err)
+	}
+	if u := core.Eng.SnapshotUniforms(); u.TimeScale != 2 {
+		t.Fatalf("engine TimeScale = %v, want 2; the scene took the key", u.TimeScale)
+	}
+	if err := core.Eng.SetParam("SimSpeed", 0.5); err != nil {
+		t.Fatalf("ocean's own speed knob: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/config"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+// CalibFromConfig converts the config.yaml calibration section. Matrices
+// must have 0 or 9 entries; gamma 0, 1 (all channels) or 3.
+func CalibFromConfig(c *config.Calibration) (*render.CalibProfile, error) {
+	if c == nil {
+		return nil, nil
+	}
+	p := &render.CalibProfile{}
+	if c.Global != nil {
+		e, err := calibEntry("global", *c.Global)
+		if err != nil {
+			return nil, err
+		}
+		p.Global = &e
+	}
+	if len(c.Panels) > 0 {
+		p.Panels = map[int]render.CalibEntry{}
+		for z, ce := range c.Panels {
+			e, err := calibEntry(fmt.Sprintf("panels[%d]", z), ce)
+			if err != nil {
+				return nil, err
+			}
+			p.Panels[z] = e
+		}
+	}
+	if len(c.LEDs) > 0 {
+		p.LEDs = map[int]render.CalibEntry{}
+		for i, ce := range c.LEDs {
+			e, err := calibEntry(fmt.Sprintf("leds[%d]", i), ce)
+			if err != nil {
+				return nil, err
+			}
+			p.LEDs[i] = e
+		}
+	}
+	return p, nil
+}
+
+func calibEntry(path string, c config.CalibEntry) (render.CalibEntry, error) {
+	var e render.CalibEntry
+	switch len(c.Matrix) {
+	case 0:
+	case 9:
+		copy(e.Matrix[:], c.Matrix)
+	default:
+		return e, fmt.Errorf("calibration.%s.matrix: want 9 values, got %d", path, len(c.Matrix))
+	}
+	switch len(c.Gamma) {
+	case 0:
+	case 1:
+		e.Gamma = [3]float64{c.Gamma[0], c.Gamma[0], c.Gamma[0]}
+	case 3:
+		copy(e.Gamma[:], c.Gamma)
+	default:
+		return e, fmt.Errorf("calibration.%s.gamma: want 1 or 3 values, got %d", path, len(c.Gamma))
+	}
+	return e, nil
+}
+
+// CalibToConfig is the inverse of CalibFromConfig, for saving.
+func CalibToConfig(p *render.CalibProfile) *config.Calibration {
+	if p == nil {
+		return nil
+	}
+	out := &config.Calibration{}
+	if p.Global != nil {
+		e := calibCfg(*p.Global)
+		out.Global = &e
+	}
+	if len(p.Panels) > 0 {
+		out.Panels = map[int]config.CalibEntry{}
+		for z, e := range p.Panels {
+			out.Panels[z] = calibCfg(e)
+		}
+	}
+	if len(p.LEDs) > 0 {
+		out.LEDs = map[int]config.CalibEntry{}
+		for i, e := range p.LEDs {
+			out.LEDs[i] = calibCfg(e)
+		}
+	}
+	return out
+}
+
+func calibCfg(e render.CalibEntry) config.CalibEntry {
+	var c config.CalibEntry
+	if e.Matrix != [9]float64{} {
+		c.Matrix = append([]float64(nil), e.Matrix[:]...)
+	}
+	if e.Gamma != [3]float64{} {
+		c.Gamma = append([]float64(nil), e.Gamma[:]...)
+	}
+	return c
+}
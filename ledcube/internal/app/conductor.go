@@ -21,8 +21,13 @@ func NewConductor(eng *render.Engine, reg *render.Registry) *Conductor {
 		SetRenderer:  func(name, preset string) { _ = eng.SetRenderer(name, preset, reg) },
 		ArmNext:      func(name, preset string) { _ = eng.ArmNext(name, preset, reg) },
 		SetCrossfade: func(a float64) { eng.SetCrossfade(a) },
-		SetParam:     func(k string, v float64) { eng.SetParam(k, v) },
-		SetBool:      func(k string, b bool) { eng.SetBool(k, b) },
+		SetTransition: func(t *sequence.Transition) {
+			eng.SetTransition(RenderTransition(t))
+		},
+		SetParam:       func(k string, v float64) { _ = eng.SetParam(k, v) },
+		SetBool:        func(k string, b bool) { _ = eng.SetBool(k, b) },
+		SetScopedParam: func(scope, k string, v float64) { _ = eng.SetScopedParam(scope, k, v) },
+		SetScopedBool:  func(scope, k string, b bool) { _ = eng.SetScopedBool(scope, k, b) },
 	}
 	c.Seq = sequence.NewPlayer(hooks)
 	return c
@@ -0,0 +1,361 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/config"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/dmx"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/layout"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/led"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/sequence"
+)
+
+// Console input modes (config dmx_in.mode).
+const (
+	DMXInPixels  = "pixels"
+	DMXInFixture = "fixture"
+)
+
+// DMXInLayer is the overlay layer (and renderer name) pixel-mapped console
+// input is drawn on; it is opaque while the console is live and hidden
+// otherwise, so the local show keeps running underneath.
+const DMXInLayer = "dmx"
+
+// universeReader is the merged console data (a dmx.Receiver).
+type universeReader interface {
+	Universe(u uint16, dst []byte, now time.Time) bool
+}
+
+// DMXInput lets a lighting console drive a Core: as pixels on the
+// DMXInLayer overlay, or as a fixture selecting the scene, its preset and
+// parameters and a master dimmer. Update and the overlay's renderer run on
+// the render goroutine.
+type DMXInput struct {
+	Mode string
+
+	rx   *dmx.Receiver
+	src  universeReader
+	core *Core
+	live bool
+
+	// pixels: the console frame in wire order, unpacked, then placed by
+	// index (console pixel k -> LED index)
+	plan  *dmx.Plan
+	order led.ChannelOrder
+	bufs  [][]byte
+	wire  []byte
+	rgb   []byte
+	index []int
+	px    []render.Color
+
+	// fixture
+	fix    config.DMXFixtureCfg
+	uni    []byte
+	dimmer float64
+	sel    [2]byte // renderer and preset select last applied
+	scene  string  // renderer the console selected; "" = the local one
+	sent   []int   // param channel values last applied; -1 = none
+	saved  struct {
+		renderer, preset string
+		paused           bool
+		params           map[string]float64
+	}
+}
+
+// NewDMXInput builds the console input for c on a cube laid out as l.
+// Open starts listening; Attach hands it a Core.
+func NewDMXInput(c config.DMXInCfg, l layout.Layout) (*DMXInput, error) {
+	rc := dmx.ReceiverConfig{Merge: c.Merge, Timeout: time.Duration(c.TimeoutMs) * time.Millisecond}
+	protos := c.Protocols
+	if len(protos) == 0 {
+		protos = []string{dmx.ArtNet, dmx.SACN}
+	}
+	maxU := dmx.MaxSACNUniverse
+	for _, p := range protos {
+		switch p {
+		case dmx.ArtNet:
+			rc.ArtNet = fmt.Sprintf("%s:%d", c.Listen, dmx.ArtNetPort)
+			maxU = dmx.MaxArtNetUniverse
+		case dmx.SACN:
+			rc.SACN = fmt.Sprintf("%s:%d", c.Listen, dmx.SACNPort)
+		default:
+			return nil, fmt.Errorf("dmx_in: unknown protocol %q (artnet or sacn)", p)
+		}
+	}
+	if c.Mode == DMXInPixels && c.StartUniverse == 0 && len(c.Panels) == 0 && rc.SACN != "" {
+		c.StartUniverse = 1
+	}
+	return newDMXInput(c, l, rc, maxU)
+}
+
+func newDMXInput(c config.DMXInCfg, l layout.Layout, rc dmx.ReceiverConfig, maxU int) (*DMXInput, error) {
+	d := &DMXInput{Mode: c.Mode, dimmer: 1}
+	switch c.Mode {
+	case DMXInPixels:
+		order := c.ColorOrder
+		if order == "" {
+			order = "RGB"
+		}
+		o, err := led.ParseOrder(order)
+		if err != nil {
+			return nil, fmt.Errorf("dmx_in: %w", err)
+		}
+		patch := dmx.Patch{StartUniverse: c.StartUniverse, Channels: c.Channels}
+		for _, p := range c.Panels {
+			patch.Panels = append(patch.Panels, dmx.PanelPatch{Panel: p.Panel, Universe: p.Universe, Offset: p.Offset})
+		}
+		plan, err := patch.Plan(l.Count(), l.Dim.X*l.Dim.Y, len(o), maxU)
+		if err != nil {
+			return nil, fmt.Errorf("dmx_in: %w", err)
+		}
+		d.plan, d.order = plan, o
+		d.bufs = make([][]byte, len(plan.Universes))
+		for i := range d.bufs {
+			d.bufs[i] = make([]byte, dmx.UniverseSize)
+		}
+		d.wire = make([]byte, l.Count()*len(o))
+		d.rgb = make([]byte, l.Count()*3)
+		d.px = make([]render.Color, l.Count())
+		d.index = make([]int, 0, l.Count())
+		for z := 0; z < l.Dim.Z; z++ {
+			for y := 0; y < l.Dim.Y; y++ {
+				for x := 0; x < l.Dim.X; x++ {
+					d.index = append(d.index, l.Index(x, y, z))
+				}
+			}
+		}
+		rc.Universes = plan.Universes
+	case DMXInFixture:
+		f := c.Fixture
+		if f.Address == 0 {
+			f.Address = 1
+		}
+		if f.Universe < 0 || f.Universe > maxU {
+			return nil, fmt.Errorf("dmx_in: fixture universe %d outside 0..%d", f.Universe, maxU)
+		}
+		if f.Address < 1 || f.Address+2+len(f.Params) > dmx.UniverseSize {
+			return nil, fmt.Errorf("dmx_in: fixture at %d with %d params does not fit a universe", f.Address, len(f.Params))
+		}
+		d.fix = f
+		d.uni = make([]byte, dmx.UniverseSize)
+		d.sent = make([]int, len(f.Params))
+		rc.Universes = []uint16{uint16(f.Universe)}
+	default:
+		return nil, fmt.Errorf("dmx_in: unknown mode %q (pixels or fixture)", c.Mode)
+	}
+	rx, err := dmx.NewReceiver(rc)
+	if err != nil {
+		return nil, err
+	}
+	d.rx, d.src = rx, rx
+	return d, nil
+}
+
+// Open starts listening for the console.
+func (d *DMXInput) Open() error { return d.rx.Open() }
+
+// Close stops listening; the caller hands the Core back with Attach(nil)
+// first if the console may still be live.
+func (d *DMXInput) Close() error { return d.rx.Close() }
+
+// Universes lists the universes the console is heard on.
+func (d *DMXInput) Universes() []uint16 { return d.rx.Universes() }
+
+// Attach makes core the one the console drives, releasing the previous one
+// to its local show. In pixel mode it adds the DMXInLayer overlay, hidden
+// until the console is live; its renderer comes from a registry of its own,
+// so it is never offered as a scene.
+func (d *DMXInput) Attach(core *Core) error {
+	if d.core != nil && d.live {
+		d.release()
+	}
+	d.core, d.live = core, false
+	if core == nil || d.Mode != DMXInPixels {
+		return nil
+	}
+	reg := render.NewRegistry()
+	reg.Register(DMXInLayer, func() render.Renderer { return dmxPixels{d} })
+	_ = core.Eng.RemoveLayer(DMXInLayer)
+	return core.Eng.AddLayer(DMXInLayer, DMXInLayer, "", reg, render.BlendAlpha, 0)
+}
+
+// Update reads the console's latest data into the attached Core and
+// reports whether the console is live; once it times out the local show
+// is restored.
+func (d *DMXInput) Update(now time.Time) bool {
+	if d.core == nil {
+		return false
+	}
+	if d.Mode == DMXInPixels {
+		return d.updatePixels(now)
+	}
+	return d.updateFixture(now)
+}
+
+// Dimmer is the console's master dimmer: 0..1 while a live fixture, else 1.
+func (d *DMXInput) Dimmer() float64 { return d.dimmer }
+
+func (d *DMXInput) updatePixels(now time.Time) bool {
+	live := false
+	for i, u := range d.plan.Universes {
+		if d.src.Universe(u, d.bufs[i], now) {
+			live = true
+		}
+	}
+	if live {
+		d.plan.Extract(d.wire, d.bufs)
+		d.order.Unpack(d.rgb, d.wire)
+		for k, i := range d.index {
+			d.px[i] = render.Color{R: float32(d.rgb[k*3]) / 255, G: float32(d.rgb[k*3+1]) / 255, B: float32(d.rgb[k*3+2]) / 255}
+		}
+	}
+	if live != d.live {
+		opacity := 0.0
+		if live {
+			opacity = 1
+		}
+		_ = d.core.Eng.SetLayerOpacity(DMXInLayer, opacity)
+		d.live = live
+	}
+	return live
+}
+
+func (d *DMXInput) updateFixture(now time.Time) bool {
+	if !d.src.Universe(uint16(d.fix.Universe), d.uni, now) {
+		if d.live {
+			d.release()
+		}
+		return false
+	}
+	if !d.live {
+		d.takeOver()
+	}
+	ch := d.uni[d.fix.Address-1:]
+	d.dimmer = float64(ch[0]) / 255
+	if sel := [2]byte{ch[1], ch[2]}; sel != d.sel {
+		d.sel = sel
+		d.selectScene(sel[0], sel[1])
+	}
+	eng := d.core.Eng
+	var specs []render.ParamInfo
+	for i, name := range d.fix.Params {
+		v := int(ch[3+i])
+		if v == d.sent[i] {
+			continue
+		}
+		if specs == nil {
+			specs = eng.DescribeParams()
+		}
+		k := slices.IndexFunc(specs, func(p render.ParamInfo) bool { return p.Name == name })
+		if k < 0 {
+			continue // not declared by this scene; retried after the next change
+		}
+		sp := specs[k]
+		if err := eng.SetParam(name, sp.Min+float64(v)/255*(sp.Max-sp.Min)); err == nil {
+			d.sent[i] = v
+		}
+	}
+	return true
+}
+
+// selectScene applies the renderer and preset selects: 1..255 split evenly
+// over the registered renderers and the renderer's presets; renderer 0
+// hands the scene back to the local show.
+func (d *DMXInput) selectScene(rv, pv byte) {
+	c := d.core
+	names := c.Reg.List()
+	if rv == 0 || len(names) == 0 {
+		if d.scene == "" {
+			return // the local show keeps its scene and sequencer
+		}
+		d.scene = ""
+		d.setScene(d.saved.renderer, d.saved.preset)
+		if d.saved.paused {
+			c.Seq.Resume()
+			d.saved.paused = false
+		}
+		return
+	}
+	name, preset := names[(int(rv)-1)*len(names)/255], ""
+	if r, ok := c.Reg.New(name); ok {
+		if ps := r.Presets(); pv > 0 && len(ps) > 0 {
+			preset = ps[(int(pv)-1)*len(ps)/255]
+		}
+		if rel, ok := r.(render.Releaser); ok {
+			rel.Release()
+		}
+	}
+	if d.scene == "" && c.Seq.State == sequence.Running {
+		c.Seq.Pause() // the console's scene wins over the program
+		d.saved.paused = true
+	}
+	d.scene = name
+	d.setScene(name, preset)
+}
+
+// setScene swaps the scene renderer; the param channels apply again to the
+// new instance.
+func (d *DMXInput) setScene(name, preset string) {
+	if err := d.core.Eng.SetRenderer(name, preset, d.core.Reg); err != nil {
+		return
+	}
+	for i := range d.sent {
+		d.sent[i] = -1
+	}
+}
+
+// takeOver notes what the local show is playing so release can put it back.
+func (d *DMXInput) takeOver() {
+	c := d.core
+	for _, l := range c.Eng.Layers() {
+		if l.Name == render.SceneLayer {
+			d.saved.renderer, d.saved.preset = l.Renderer, l.Preset
+		}
+	}
+	d.saved.paused = false
+	d.saved.params = map[string]float64{}
+	for _, p := range c.Eng.DescribeParams() {
+		if slices.Contains(d.fix.Params, p.Name) {
+			d.saved.params[p.Name] = p.Value
+		}
+	}
+	d.scene, d.sel = "", [2]byte{}
+	for i := range d.sent {
+		d.sent[i] = -1
+	}
+	d.live = true
+}
+
+// release hands the Core back to the local show: its scene, sequencer,
+// parameters and full brightness.
+func (d *DMXInput) release() {
+	c := d.core
+	if d.Mode == DMXInPixels {
+		_ = c.Eng.SetLayerOpacity(DMXInLayer, 0)
+		d.live = false
+		return
+	}
+	if d.scene != "" {
+		_ = c.Eng.SetRenderer(d.saved.renderer, d.saved.preset, c.Reg)
+	}
+	for name, v := range d.saved.params {
+		_ = c.Eng.SetParam(name, v)
+	}
+	if d.saved.paused {
+		c.Seq.Resume()
+	}
+	d.scene, d.dimmer, d.live = "", 1, false
+}
+
+// dmxPixels is the DMXInLayer renderer: the console's latest pixels.
+type dmxPixels struct{ in *DMXInput }
+
+func (p dmxPixels) Name() string                                { return DMXInLayer }
+func (p dmxPixels) Presets() []string                           { return nil }
+func (p dmxPixels) ApplyPreset(name string, u *render.Uniforms) {}
+func (p dmxPixels) Render(dst []render.Color, _ []render.Vec3, _ render.Dimensions, _ float64, _ *render.Uniforms, _ *render.Resources) {
+	copy(dst, p.in.px)
+}
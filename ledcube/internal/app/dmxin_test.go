@@ -0,0 +1,171 @@
+package app
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/config"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/dmx"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/layout"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/led"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/sequence"
+)
+
+// console stands in for the receiver: fixed data per universe, live until
+// cut.
+type console struct {
+	unis map[uint16][]byte
+	cut  bool
+}
+
+func (c *console) Universe(u uint16, dst []byte, _ time.Time) bool {
+	clear(dst)
+	data, ok := c.unis[u]
+	if !ok || c.cut {
+		return false
+	}
+	copy(dst, data)
+	return true
+}
+
+func testCore(t *testing.T, l layout.Layout, renderer string) *Core {
+	t.Helper()
+	core, err := NewCore(HWConfig{
+		Dim:   render.Dimensions{X: l.Dim.X, Y: l.Dim.Y, Z: l.Dim.Z},
+		Order: led.Order{XFlipEveryRow: l.Order.XFlipEveryRow, YFlipEveryPanel: l.Order.YFlipEveryPanel},
+	}, renderer, &render.Uniforms{GlobalBrightness: 1, TimeScale: 1}, &render.Resources{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := core.Eng.SetRenderer(renderer, "", core.Reg); err != nil {
+		t.Fatal(err)
+	}
+	return core
+}
+
+func layerOpacity(core *Core, name string) float64 {
+	for _, l := range core.Eng.Layers() {
+		if l.Name == name {
+			return l.Opacity
+		}
+	}
+	return -1
+}
+
+func scene(core *Core) (string, string) {
+	for _, l := range core.Eng.Layers() {
+		if l.Name == render.SceneLayer {
+			return l.Renderer, l.Preset
+		}
+	}
+	return "", ""
+}
+
+func TestDMXInputMapsPixelsThroughLayout(t *testing.T) {
+	l := layout.Layout{Dim: layout.Dim{X: 2, Y: 2, Z: 2}, Order: layout.Serpentine{XFlipEveryRow: true, YFlipEveryPanel: true}}
+	d, err := newDMXInput(config.DMXInCfg{Mode: DMXInPixels, StartUniverse: 1, ColorOrder: "GRB"}, l,
+		dmx.ReceiverConfig{ArtNet: ":0"}, dmx.MaxArtNetUniverse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// panels start fresh universes: pixels 0..3 on 1, 4..7 on 2, sent GRB
+	con := &console{unis: map[uint16][]byte{1: nil, 2: nil}}
+	for k := 0; k < 8; k++ {
+		con.unis[uint16(1+k/4)] = append(con.unis[uint16(1+k/4)], 0, byte(k*10+5), 0)
+	}
+	d.src = con
+	core := testCore(t, l, "solid")
+	if err := d.Attach(core); err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(core.Reg.List(), DMXInLayer) {
+		t.Fatal("overlay renderer offered as a scene")
+	}
+	if op := layerOpacity(core, DMXInLayer); op != 0 {
+		t.Fatalf("overlay opacity %v before input, want 0", op)
+	}
+	if !d.Update(time.Now()) || layerOpacity(core, DMXInLayer) != 1 {
+		t.Fatal("console input did not take over")
+	}
+	k := 0
+	for z := 0; z < 2; z++ {
+		for y := 0; y < 2; y++ {
+			for x := 0; x < 2; x++ {
+				got := d.px[l.Index(x, y, z)].R
+				if want := float32(k*10+5) / 255; got != want {
+					t.Fatalf("pixel (%d,%d,%d) R = %v, want %v", x, y, z, got, want)
+				}
+				k++
+			}
+		}
+	}
+	con.cut = true
+	if d.Update(time.Now()) || layerOpacity(core, DMXInLayer) != 0 {
+		t.Fatal("local show not back after the console went quiet")
+	}
+}
+
+func TestDMXInputFixture(t *testing.T) {
+	l := layout.Layout{Dim: layout.Dim{X: 2, Y: 2, Z: 1}}
+	d, err := newDMXInput(config.DMXInCfg{Mode: DMXInFixture, Fixture: config.DMXFixtureCfg{Universe: 4, Address: 10, Params: []string{"PulseHz"}}}, l,
+		dmx.ReceiverConfig{ArtNet: ":0"}, dmx.MaxArtNetUniverse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	con := &console{unis: map[uint16][]byte{4: make([]byte, 13)}}
+	d.src = con
+	core := testCore(t, l, "ocean")
+	if err := core.LoadProgram([]byte(`{"version":"seq.v2","clips":[{"name":"a","renderer":"grad","durationS":60}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	core.Seq.Start()
+	_ = d.Attach(core)
+
+	// dimmer only: the local show keeps its scene and program
+	ch := con.unis[4][9:]
+	ch[0] = 128
+	if !d.Update(time.Now()) || math.Abs(d.Dimmer()-128.0/255) > 1e-9 {
+		t.Fatalf("dimmer = %v", d.Dimmer())
+	}
+	if name, _ := scene(core); name != "grad" || core.Seq.State != sequence.Running {
+		t.Fatalf("scene %s, sequencer %s; want the local show", name, core.Seq.State)
+	}
+
+	// renderers sorted calib, grad, ocean, solid: 255 picks solid, preset 1 Red
+	ch[1], ch[2], ch[3] = 255, 1, 51
+	d.Update(time.Now())
+	if name, preset := scene(core); name != "solid" || preset != "Red" {
+		t.Fatalf("scene %s/%s, want solid/Red", name, preset)
+	}
+	if core.Seq.State != sequence.Paused {
+		t.Fatalf("sequencer %s while the console picks the scene", core.Seq.State)
+	}
+	if u := core.Eng.SnapshotUniforms(); math.Abs(u.Params["PulseHz"]-2) > 1e-9 {
+		t.Fatalf("PulseHz = %v, want 2 (51/255 of 0..10)", u.Params["PulseHz"])
+	}
+
+	con.cut = true
+	if d.Update(time.Now()) {
+		t.Fatal("live after the console went quiet")
+	}
+	if name, _ := scene(core); name != "grad" || core.Seq.State != sequence.Running || d.Dimmer() != 1 {
+		t.Fatalf("after timeout: scene %s, sequencer %s, dimmer %v", name, core.Seq.State, d.Dimmer())
+	}
+}
+
+func TestDMXInputRejects(t *testing.T) {
+	l := layout.Layout{Dim: layout.Dim{X: 2, Y: 2, Z: 2}}
+	for _, c := range []config.DMXInCfg{
+		{Mode: "wash"},
+		{Mode: DMXInPixels, Protocols: []string{"kinet"}},
+		{Mode: DMXInPixels, ColorOrder: "RGX"},
+		{Mode: DMXInFixture, Fixture: config.DMXFixtureCfg{Address: 510, Params: []string{"a"}}},
+	} {
+		if _, err := NewDMXInput(c, l); err == nil {
+			t.Errorf("%+v accepted", c)
+		}
+	}
+}
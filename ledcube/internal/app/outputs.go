@@ -0,0 +1,109 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/config"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/dmx"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/led"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+// SPIBuses builds the multi-bus SPI driver for cfg.Outputs: one led.SPI per
+// entry, carrying its panels and ranges of a dim-sized cube. Speed and reset
+// default to cfg.SPI, then to the led.NewSPI defaults.
+func SPIBuses(cfg *config.Config, dim render.Dimensions, colorOrder string) (*led.Composite, error) {
+	count := dim.X * dim.Y * dim.Z
+	segs := make([]led.Segment, 0, len(cfg.Outputs))
+	for i, o := range cfg.Outputs {
+		name := o.Name
+		if name == "" {
+			name = o.Dev
+		}
+		spans, err := busSpans(dim, o.Panels, o.Ranges)
+		if err != nil {
+			return nil, fmt.Errorf("outputs[%d] %s: %w", i, name, err)
+		}
+		n := 0
+		for _, sp := range spans {
+			n += sp[1] - sp[0]
+		}
+		if n == 0 || n > count {
+			return nil, fmt.Errorf("outputs[%d] %s: covers %d of %d LEDs", i, name, n, count)
+		}
+		speed, reset := o.SpeedHz, o.ResetUs
+		if speed == 0 {
+			speed = cfg.SPI.SpeedHz
+		}
+		if reset == 0 {
+			reset = cfg.SPI.ResetUs
+		}
+		dev, err := led.NewSPI(o.Dev, n, colorOrder, speed, reset)
+		if err != nil {
+			return nil, fmt.Errorf("outputs[%d] %s: %w", i, name, err)
+		}
+		segs = append(segs, led.Segment{Name: name, Dev: dev, Spans: spans})
+	}
+	return led.NewComposite(count, segs)
+}
+
+// DMXSender builds the Art-Net or sACN driver (protocol dmx.ArtNet or
+// dmx.SACN) for c, one panel per Z slice of a dim-sized cube.
+func DMXSender(protocol string, c config.DMXCfg, dim render.Dimensions) (*dmx.Sender, error) {
+	cfg := dmx.Config{
+		Protocol:     protocol,
+		Targets:      c.Targets,
+		Patch:        dmx.Patch{StartUniverse: c.StartUniverse, Channels: c.Channels},
+		Sync:         c.Sync,
+		SyncUniverse: c.SyncUniverse,
+		Source:       c.Source,
+		Priority:     c.Priority,
+	}
+	for _, p := range c.Panels {
+		cfg.Patch.Panels = append(cfg.Patch.Panels, dmx.PanelPatch{Panel: p.Panel, Universe: p.Universe, Offset: p.Offset})
+	}
+	return dmx.NewSender(cfg, dim.X*dim.Y*dim.Z, dim.X*dim.Y, c.ColorOrder)
+}
+
+// busSpans turns panels (whole Z slices) and ranges into LED index spans
+// in index order, the order the bus is wired and sent in, joining the ones
+// that touch. Each list must already be ascending, so a wiring order that
+// differs is an error rather than silently reordered; so is an LED listed
+// twice, as it is across buses.
+func busSpans(dim render.Dimensions, panels []int, ranges [][2]int) ([][2]int, error) {
+	per := dim.X * dim.Y
+	var spans [][2]int
+	for i, p := range panels {
+		if i > 0 && p <= panels[i-1] {
+			return nil, fmt.Errorf("panels %v not in ascending (wiring) order", panels)
+		}
+		if p < 0 || p >= dim.Z {
+			return nil, fmt.Errorf("panel %d outside 0..%d", p, dim.Z-1)
+		}
+		spans = append(spans, [2]int{p * per, (p + 1) * per})
+	}
+	for i, r := range ranges {
+		if i > 0 && r[0] < ranges[i-1][0] {
+			return nil, fmt.Errorf("ranges %v not in ascending (wiring) order", ranges)
+		}
+		if r[0] < 0 || r[1] > per*dim.Z || r[0] >= r[1] {
+			return nil, fmt.Errorf("range [%d, %d) outside [0, %d)", r[0], r[1], per*dim.Z)
+		}
+		spans = append(spans, r)
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	out := spans[:0]
+	for _, sp := range spans {
+		k := len(out) - 1
+		if k >= 0 && sp[0] < out[k][1] {
+			return nil, fmt.Errorf("LEDs %d-%d listed twice", sp[0], min(sp[1], out[k][1])-1)
+		}
+		if k >= 0 && sp[0] == out[k][1] {
+			out[k][1] = sp[1]
+			continue
+		}
+		out = append(out, sp)
+	}
+	return out, nil
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+func TestBusSpans(t *testing.T) {
+	dim := render.Dimensions{X: 2, Y: 5, Z: 4} // 10 LEDs per panel
+	spans, err := busSpans(dim, []int{0, 1, 3}, [][2]int{{20, 22}, {25, 28}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]int{{0, 22}, {25, 28}, {30, 40}}
+	if len(spans) != len(want) {
+		t.Fatalf("spans %v, want %v", spans, want)
+	}
+	for i := range want {
+		if spans[i] != want[i] {
+			t.Fatalf("spans %v, want %v", spans, want)
+		}
+	}
+	if _, err := busSpans(dim, []int{3, 1}, nil); err == nil {
+		t.Fatal("panels out of wiring order accepted")
+	}
+	if _, err := busSpans(dim, nil, [][2]int{{25, 28}, {20, 22}}); err == nil {
+		t.Fatal("ranges out of wiring order accepted")
+	}
+	if _, err := busSpans(dim, []int{0, 1, 3}, [][2]int{{18, 22}}); err == nil {
+		t.Fatal("range overlapping panel 1 accepted")
+	}
+	if _, err := busSpans(dim, []int{2}, [][2]int{{20, 30}}); err == nil {
+		t.Fatal("panel listed twice accepted")
+	}
+	if _, err := busSpans(dim, []int{4}, nil); err == nil {
+		t.Fatal("panel past the cube accepted")
+	}
+	if _, err := busSpans(dim, nil, [][2]int{{30, 41}}); err == nil {
+		t.Fatal("range past the cube accepted")
+	}
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/sequence"
+)
+
+// LoadProgram parses a seq.v1/seq.v2 document, checks it against the
+// registered renderers' parameter schemas and hands it to the sequencer.
+func (c *Core) LoadProgram(data []byte) error {
+	prog, err := sequence.ParseProgram(data)
+	if err != nil {
+		return err
+	}
+	if err := ValidateProgram(prog, c.Reg); err != nil {
+		return err
+	}
+	return c.Seq.Load(prog)
+}
+
+// ValidateProgram checks every clip's renderer exists and that its param and
+// bool tracks name declared parameters with keyframes inside their ranges.
+// Unlike live SetParam, which clamps, authored values out of range are errors
+// so a show never silently plays something other than what was written.
+func ValidateProgram(p sequence.Program, reg *render.Registry) error {
+	var errs []sequence.FieldError
+	add := func(path, format string, a ...any) {
+		errs = append(errs, sequence.FieldError{Path: path, Msg: fmt.Sprintf(format, a...)})
+	}
+	for i, clip := range p.Clips {
+		base := fmt.Sprintf("clips[%d]", i)
+		rr, ok := reg.New(clip.Renderer)
+		if !ok {
+			add(base+".renderer", "unknown renderer %q", clip.Renderer)
+			continue
+		}
+		specs, declared := render.SchemaOf(rr)
+		// lookup resolves a track key to its spec; strict means an
+		// undeclared key is an error in that scope.
+		lookup := func(key string) (spec render.ParamSpec, ok, strict bool) {
+			scope, name := sequence.SplitScope(key)
+			switch scope {
+			case "":
+				if spec, ok = render.LookupParam(specs, name); ok {
+					return spec, true, declared
+				}
+				if spec, ok = render.LookupParam(render.PostParams(), name); ok {
+					return spec, true, true
+				}
+				spec, ok = render.LookupParam(render.GlobalParams(), name)
+				return spec, ok, declared
+			case render.SceneLayer:
+				spec, ok = render.LookupParam(specs, name)
+				return spec, ok, declared
+			case render.ScopePost:
+				spec, ok = render.LookupParam(render.PostParams(), name)
+				return spec, ok, true
+			case render.ScopeGlobal:
+				spec, ok = render.LookupParam(render.GlobalParams(), name)
+				return spec, ok, false
+			}
+			return spec, false, false // overlay layers only exist at run time
+		}
+		for _, key := range sortedKeys(clip.Params) {
+			path := base + ".params." + key
+			s, ok, strict := lookup(key)
+			switch {
+			case !ok && strict:
+				add(path, "unknown param for renderer %s", clip.Renderer)
+				continue
+			case !ok:
+				continue
+			case s.Type == render.ParamBool:
+				add(path, "is a bool; use bools")
+				continue
+			}
+			for j, k := range clip.Params[key].Keys {
+				if s.Min < s.Max && (k.V < s.Min || k.V > s.Max) {
+					add(fmt.Sprintf("%s.keys[%d].v", path, j), "must be within [%v, %v], got %v", s.Min, s.Max, k.V)
+				}
+			}
+		}
+		for _, key := range sortedKeys(clip.Bools) {
+			path := base + ".bools." + key
+			s, ok, strict := lookup(key)
+			switch {
+			case ok && s.Type != render.ParamBool:
+				add(path, "param is %s, not bool", s.Type)
+			case !ok && strict:
+				add(path, "unknown bool for renderer %s", clip.Renderer)
+			}
+		}
+		if rel, ok := rr.(render.Releaser); ok {
+			rel.Release()
+		}
+	}
+	if len(errs) > 0 {
+		return &sequence.ValidationError{Version: p.Version, Errors: errs}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]sequence.Envelope) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/sequence"
+)
+
+func defaultRegistry() *render.Registry {
+	reg := render.NewRegistry()
+	RegisterDefaultRenderers(reg)
+	return reg
+}
+
+func TestValidateProgramAcceptsDemo(t *testing.T) {
+	b, err := os.ReadFile("../../docs/examples/seq-v2-demo.json")
+	if err != nil {
+		t.Fatalf("read demo: %v", err)
+	}
+	p, err := sequence.ParseProgram(b)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if err := ValidateProgram(p, defaultRegistry()); err != nil {
+		t.Fatalf("demo should validate: %v", err)
+	}
+}
+
+func TestValidateProgramRejectsAgainstSchema(t *testing.T) {
+	p, err := sequence.ParseProgram([]byte(`{
+		"version": "seq.v2",
+		"clips": [
+			{"name": "a", "renderer": "ocean", "durationS": 4,
+			 "params": {
+				"TideAmp": {"keys": [{"t": 0, "v": 0.2}, {"t": 2, "v": 7}]},
+				"Nope":    {"keys": [{"t": 0, "v": 1}]},
+				"ExposureEV": {"keys": [{"t": 0, "v": 1}]},
+				"post.OutputGamma": {"keys": [{"t": 0, "v": 2.2}]},
+				"global.TimeScale": {"keys": [{"t": 0, "v": 9}]},
+				"post.Gamma": {"keys": [{"t": 0, "v": 1}]},
+				"fx.Speed": {"keys": [{"t": 0, "v": 1}]}
+			 },
+			 "bools": {"WaveSpeed": {"keys": [{"t": 0, "v": true}]}}},
+			{"name": "b", "renderer": "warp", "durationS": 4}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	err = ValidateProgram(p, defaultRegistry())
+	var ve *sequence.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	want := []string{
+		"clips[0].params.Nope",
+		"clips[0].params.TideAmp.keys[1].v",
+		"clips[0].params.global.TimeScale.keys[0].v",
+		"clips[0].params.post.Gamma",
+		"clips[0].bools.WaveSpeed",
+		"clips[1].renderer",
+	}
+	if len(ve.Errors) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), ve.Errors)
+	}
+	for i, w := range want {
+		if ve.Errors[i].Path != w {
+			t.Fatalf("error %d: want path %s, got %s", i, w, ve.Errors[i])
+		}
+	}
+	if !strings.Contains(ve.Error(), "[0, 1]") {
+		t.Fatalf("expected range in message: %v", ve)
+	}
+}
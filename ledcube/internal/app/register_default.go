@@ -1,7 +1,18 @@
-//go:build !wails
-
 package app
 
-import "github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+import (
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+	calib "github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render/scenes/calib"
+	grad "github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render/scenes/grad"
+	ocean "github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render/scenes/ocean"
+	solid "github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render/scenes/solid"
+)
 
-func registerDefaultRenderers(reg *render.Registry) {}
+// RegisterDefaultRenderers registers the renderer catalog shared by the Wails
+// desktop app and the headless server, so both builds expose the same scenes.
+func RegisterDefaultRenderers(reg *render.Registry) {
+	reg.Register("solid", func() render.Renderer { return solid.New("solid", render.Color{R: 1}) })
+	reg.Register("grad", func() render.Renderer { return grad.New("grad") })
+	reg.Register("calib", func() render.Renderer { return calib.New("calib") })
+	reg.Register("ocean", func() render.Renderer { return ocean.New("ocean") })
+}
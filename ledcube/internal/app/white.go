@@ -0,0 +1,12 @@
+package app
+
+import (
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/config"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+// WhiteFromConfig converts the config.yaml white section.
+func WhiteFromConfig(c config.WhiteCfg) (render.White, error) {
+	w := render.White{Mode: c.Mode, Kelvin: c.Kelvin, Amount: c.Amount}
+	return w, w.Validate()
+}
@@ -6,10 +6,61 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// PowerCfg is the supply budget enforced by the power manager on every
+// output: LimitAmps total (0 = none), WhiteCap per LED as a fraction of full
+// white (0 or 1 = off) and a SoftStartMs ramp at boot and after blackout.
 type PowerCfg struct {
 	LimitAmps   float64 `yaml:"limit_amps"`
 	WhiteCap    float64 `yaml:"white_cap"`
 	SoftStartMs int     `yaml:"soft_start_ms"`
+
+	// Strip names the LED current model: a built-in (linear, ws2812,
+	// ws2812b, sk6812) or one of Strips. Empty = ws2812b.
+	Strip  string                `yaml:"strip,omitempty"`
+	Strips map[string]StripModel `yaml:"strips,omitempty"`
+
+	// Zones are injection points with their own supply; each is limited
+	// independently before the global LimitAmps cap.
+	Zones []PowerZone `yaml:"zones,omitempty"`
+
+	Thermal ThermalCfg `yaml:"thermal,omitempty"`
+}
+
+// ThermalCfg throttles panels whose average current over WindowS stays above
+// PanelLimitAmps; they recover once below PanelLimitAmps*(1-Hysteresis).
+type ThermalCfg struct {
+	PanelLimitAmps float64 `yaml:"panel_limit_amps"` // 0 = off
+	WindowS        float64 `yaml:"window_s,omitempty"`
+	Hysteresis     float64 `yaml:"hysteresis,omitempty"`
+	MinScale       float64 `yaml:"min_scale,omitempty"`
+	RatePerS       float64 `yaml:"rate_per_s,omitempty"`
+}
+
+// StripModel is a custom LED current model: full-scale mA per channel (one
+// value for all, or R, G, B), of the white die on RGBW parts (0 = none),
+// quiescent mA per LED and the exponent of the current curve (0 = linear).
+type StripModel struct {
+	ChanMilliamps  []float64 `yaml:"chan_ma,flow"`
+	WhiteMilliamps float64   `yaml:"w_ma,omitempty"`
+	IdleMilliamps  float64   `yaml:"idle_ma"`
+	Exponent       float64   `yaml:"exponent,omitempty"`
+}
+
+// PowerZone covers whole panels (Z index) and/or LED index ranges [from, to).
+// Knee is the fraction of LimitAmps where soft limiting starts (0 = hard).
+// Strip overrides the section's strip for the zone's LEDs.
+type PowerZone struct {
+	Name      string   `yaml:"name"`
+	Panels    []int    `yaml:"panels,omitempty,flow"`
+	Ranges    [][2]int `yaml:"ranges,omitempty,flow"`
+	LimitAmps float64  `yaml:"limit_amps"`
+	Knee      float64  `yaml:"knee,omitempty"`
+	Strip     string   `yaml:"strip,omitempty"`
+}
+
+// DefaultPower is used when there is no config.yaml.
+func DefaultPower() PowerCfg {
+	return PowerCfg{LimitAmps: 35, WhiteCap: 0.85, SoftStartMs: 800}
 }
 
 type Dim struct {
@@ -24,8 +75,136 @@ type SPI struct {
 	ResetUs int    `yaml:"reset_us"` // e.g. 300
 }
 
+// Output is one LED data line of a multi-bus SPI setup: the spidev it is
+// wired to and the panels (Z index) and/or LED index ranges [from, to) it
+// carries, chained in index order. Unset speed/reset use the spi section.
+type Output struct {
+	Name    string   `yaml:"name,omitempty"`
+	Dev     string   `yaml:"dev"`
+	SpeedHz int      `yaml:"speed_hz,omitempty"`
+	ResetUs int      `yaml:"reset_us,omitempty"`
+	Panels  []int    `yaml:"panels,omitempty,flow"`
+	Ranges  [][2]int `yaml:"ranges,omitempty,flow"`
+}
+
+// APA102Cfg configures the clocked-LED driver (driver: apa102 or sk9822),
+// which runs on spi.dev.
+type APA102Cfg struct {
+	SpeedHz    int    `yaml:"speed_hz,omitempty"`    // 0 = 8 MHz
+	ColorOrder string `yaml:"color_order,omitempty"` // channels after the brightness byte; "" = BGR
+}
+
+// SerialCfg configures the USB-serial controller driver (driver: serial):
+// the tty, its baud rate and the framing the firmware expects.
+type SerialCfg struct {
+	Dev        string `yaml:"dev,omitempty"`         // e.g. /dev/ttyUSB0, /dev/cu.usbserial-0001
+	Baud       int    `yaml:"baud,omitempty"`        // 0 = 1000000
+	Protocol   string `yaml:"protocol,omitempty"`    // "adalight" (default) | "tpm2"
+	ColorOrder string `yaml:"color_order,omitempty"` // order the firmware expects; "" = RGB
+}
+
+// DMXCfg configures the network drivers (driver: artnet or sacn), which
+// send the frame to remote pixel controllers as DMX universes. Each panel
+// starts a fresh universe after the previous one, from start_universe,
+// unless Panels places it; pixels never straddle two universes.
+type DMXCfg struct {
+	Targets       []string   `yaml:"targets,omitempty,flow"` // host[:port]; none = Art-Net broadcast / sACN multicast
+	StartUniverse int        `yaml:"start_universe,omitempty"`
+	Channels      int        `yaml:"channels,omitempty"` // used per universe; 0 = 510
+	Panels        []DMXPanel `yaml:"panels,omitempty"`
+	Sync          bool       `yaml:"sync,omitempty"`          // ArtSync / E1.31 sync after each frame
+	SyncUniverse  int        `yaml:"sync_universe,omitempty"` // sACN; 0 = first universe
+	Source        string     `yaml:"source,omitempty"`        // sACN source name
+	Priority      int        `yaml:"priority,omitempty"`      // sACN 1..200; 0 = 100
+	ColorOrder    string     `yaml:"color_order,omitempty"`   // order the controller expects; "" = RGB
+}
+
+// DMXPanel starts a panel (Z index) at a universe and 0-based channel offset.
+type DMXPanel struct {
+	Panel    int `yaml:"panel"`
+	Universe int `yaml:"universe"`
+	Offset   int `yaml:"offset,omitempty"`
+}
+
+// DMXInCfg lets a lighting console drive the cube over Art-Net and/or sACN
+// (dmx_in). Mode "pixels" shows the console's RGB pixels, patched like the
+// dmx section and numbered x fastest, then y, then panel by panel; the
+// layout maps them onto the LEDs. Mode "fixture" reads a small profile at
+// fixture.address instead. The sources of a universe merge HTP (default) or
+// LTP; timeout_ms (0 = 2500) after the last packet the local show is back.
+type DMXInCfg struct {
+	Mode          string        `yaml:"mode,omitempty"`           // "" = off | "pixels" | "fixture"
+	Protocols     []string      `yaml:"protocols,omitempty,flow"` // artnet, sacn; empty = both
+	Listen        string        `yaml:"listen,omitempty"`         // interface address; "" = all, with sACN multicast
+	Merge         string        `yaml:"merge,omitempty"`          // "htp" | "ltp"
+	TimeoutMs     int           `yaml:"timeout_ms,omitempty"`
+	StartUniverse int           `yaml:"start_universe,omitempty"` // pixels; 0 = 1 with sACN, else 0
+	Channels      int           `yaml:"channels,omitempty"`       // pixels; used per universe, 0 = 510
+	Panels        []DMXPanel    `yaml:"panels,omitempty"`         // pixels
+	ColorOrder    string        `yaml:"color_order,omitempty"`    // pixels; "" = RGB
+	Fixture       DMXFixtureCfg `yaml:"fixture,omitempty"`
+}
+
+// DMXFixtureCfg is the fixture profile: from the 1-based address, master
+// dimmer, renderer select, preset select, then one channel per Params
+// entry scaled over the parameter's range. The selects split 1..255 evenly
+// over the renderers (by name) and the renderer's presets; 0 keeps the
+// local show's renderer or the renderer's defaults.
+type DMXFixtureCfg struct {
+	Universe int      `yaml:"universe"`
+	Address  int      `yaml:"address,omitempty"` // 0 = 1
+	Params   []string `yaml:"params,omitempty,flow"`
+}
+
+// WhiteCfg sets how RGBW strips (a four-channel color_order such as
+// "GRBW") use their white die: mode "min" (default) moves the common part
+// of R, G and B to W, "cct" the largest amount of the die's own color
+// (kelvin, 0 = 4500) and "off" leaves W dark. Amount scales the moved part
+// (0 = 1). The power model and the previews use the same split.
+type WhiteCfg struct {
+	Mode   string  `yaml:"mode,omitempty"`
+	Kelvin float64 `yaml:"kelvin,omitempty"`
+	Amount float64 `yaml:"amount,omitempty"`
+}
+
+// Writer sets how frames reach the LED driver: "drop" (default) and
+// "block" hand them to a writer goroutine so the next frame renders during
+// transmission (drop keeps only the newest queued frame, block waits for
+// the device); "sync" writes inline.
+type Writer struct {
+	Policy string `yaml:"policy,omitempty"`
+}
+
+// DitherCfg switches temporal dithering for one output driver.
+type DitherCfg struct {
+	Enabled   bool    `yaml:"enabled"`
+	Threshold float64 `yaml:"threshold,omitempty"` // 8-bit code units; 0 = led.DefaultDitherThreshold
+}
+
+// CalibEntry is a 3x3 color matrix (row-major; empty = identity) plus
+// per-channel output gamma (0 = 1). See render.CalibEntry.
+type CalibEntry struct {
+	Matrix []float64 `yaml:"matrix,omitempty,flow"`
+	Gamma  []float64 `yaml:"gamma,omitempty,flow"`
+}
+
+// Calibration is applied after the post chain. The most specific entry
+// wins: leds (by output index), then panels (by Z index), then global.
+type Calibration struct {
+	Global *CalibEntry        `yaml:"global,omitempty"`
+	Panels map[int]CalibEntry `yaml:"panels,omitempty"`
+	LEDs   map[int]CalibEntry `yaml:"leds,omitempty"`
+}
+
+// PostStage is one entry of the output post chain (see render.PostStages for
+// the stage names and their params).
+type PostStage struct {
+	Stage  string             `yaml:"stage"`
+	Params map[string]float64 `yaml:"params,omitempty"`
+}
+
 type Config struct {
-	Driver     string  `yaml:"driver"` // "pwm" | "sim"
+	Driver     string  `yaml:"driver"` // "spi" | "pwm" | "apa102" | "sk9822" | "serial" | "artnet" | "sacn" | "sim"
 	GPIO       int     `yaml:"gpio"`
 	ColorOrder string  `yaml:"color_order"`
 	Brightness float64 `yaml:"brightness"`
@@ -39,6 +218,27 @@ type Config struct {
 
 	Power PowerCfg `yaml:"power"`
 	SPI   SPI      `yaml:"spi,omitempty"`
+
+	// Outputs splits the cube across several SPI buses written in
+	// parallel (driver: spi); empty = one bus on spi.dev.
+	Outputs []Output `yaml:"outputs,omitempty"`
+
+	APA102 APA102Cfg `yaml:"apa102,omitempty"`
+	Serial SerialCfg `yaml:"serial,omitempty"`
+	DMX    DMXCfg    `yaml:"dmx,omitempty"`
+	DMXIn  DMXInCfg  `yaml:"dmx_in,omitempty"`
+	White  WhiteCfg  `yaml:"white,omitempty"`
+
+	Writer Writer `yaml:"writer,omitempty"`
+
+	// Post is the ordered output post chain; empty keeps the default
+	// (exposure, tonemap, gamma, limiter, clamp).
+	Post []PostStage `yaml:"post,omitempty"`
+
+	Calibration *Calibration `yaml:"calibration,omitempty"`
+
+	// Dither is keyed by driver name ("spi", "pwm", "sim", "preview").
+	Dither map[string]DitherCfg `yaml:"dither,omitempty"`
 }
 
 func Load(path string) (*Config, error) {
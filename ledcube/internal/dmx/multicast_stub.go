@@ -0,0 +1,12 @@
+//go:build !linux && !darwin
+
+package dmx
+
+import (
+	"fmt"
+	"net"
+)
+
+func joinGroups(c *net.UDPConn, groups []*net.UDPAddr) error {
+	return fmt.Errorf("not supported on this platform; listen on an interface address and send unicast")
+}
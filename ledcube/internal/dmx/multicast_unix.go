@@ -0,0 +1,30 @@
+//go:build linux || darwin
+
+package dmx
+
+import (
+	"net"
+	"syscall"
+)
+
+// joinGroups adds c to the multicast groups on the default interface.
+func joinGroups(c *net.UDPConn, groups []*net.UDPAddr) error {
+	rc, err := c.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var serr error
+	err = rc.Control(func(fd uintptr) {
+		for _, g := range groups {
+			var mreq syscall.IPMreq
+			copy(mreq.Multiaddr[:], g.IP.To4())
+			if serr = syscall.SetsockoptIPMreq(int(fd), syscall.IPPROTO_IP, syscall.IP_ADD_MEMBERSHIP, &mreq); serr != nil {
+				return
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return serr
+}
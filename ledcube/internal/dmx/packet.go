@@ -0,0 +1,236 @@
+// Package dmx speaks the two DMX-over-IP protocols that pixel controllers
+// and lighting consoles use: Art-Net 4 (ArtDmx, ArtSync) and sACN (ANSI
+// E1.31 data and universe sync). It builds and parses their packets,
+// places LED frames into universes (see Patch and Sender) and merges what
+// consoles send (see Receiver).
+package dmx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Protocol names, as used in config.Driver.
+const (
+	ArtNet = "artnet"
+	SACN   = "sacn"
+)
+
+// Default UDP ports.
+const (
+	ArtNetPort = 6454
+	SACNPort   = 5568
+)
+
+// UniverseSize is the number of channel slots in one DMX universe.
+const UniverseSize = 512
+
+// Universe number limits: Art-Net port-addresses are 15 bits, sACN data
+// universes 1..63999.
+const (
+	MaxArtNetUniverse = 0x7fff
+	MaxSACNUniverse   = 63999
+)
+
+var (
+	artID  = []byte("Art-Net\x00")
+	acnID  = []byte("ASC-E1.17\x00\x00\x00")
+	errBad = errors.New("not an Art-Net or E1.31 packet")
+)
+
+const (
+	artOpDmx  = 0x5000
+	artOpSync = 0x5200
+	artProto  = 14
+
+	e131RootData     = 0x00000004
+	e131RootExtended = 0x00000008
+	e131FrameData    = 0x00000002
+	e131FrameSync    = 0x00000001
+	e131DataHeader   = 126 // bytes before the first channel slot
+	e131SyncLen      = 49
+)
+
+// AppendArtDmx appends an ArtDmx packet for universe (the 15-bit
+// port-address) to dst. Art-Net wants an even length of at least 2, so data
+// is padded with a zero slot when needed. seq 0 disables resequencing at
+// the receiver; senders count 1..255.
+func AppendArtDmx(dst []byte, universe uint16, seq uint8, data []byte) []byte {
+	n := len(data)
+	if n < 2 {
+		n = 2
+	}
+	n += n & 1
+	dst = append(dst, artID...)
+	dst = binary.LittleEndian.AppendUint16(dst, artOpDmx)
+	dst = binary.BigEndian.AppendUint16(dst, artProto)
+	dst = append(dst, seq, 0, byte(universe), byte(universe>>8)&0x7f)
+	dst = binary.BigEndian.AppendUint16(dst, uint16(n))
+	dst = append(dst, data...)
+	for i := len(data); i < n; i++ {
+		dst = append(dst, 0)
+	}
+	return dst
+}
+
+// AppendArtSync appends an ArtSync packet: receivers that saw one output
+// the ArtDmx data they hold when the next arrives.
+func AppendArtSync(dst []byte) []byte {
+	dst = append(dst, artID...)
+	dst = binary.LittleEndian.AppendUint16(dst, artOpSync)
+	dst = binary.BigEndian.AppendUint16(dst, artProto)
+	return append(dst, 0, 0)
+}
+
+// E131Source identifies an sACN sender: its component ID, name (at most 63
+// bytes are sent) and the priority of its data (0..200, receivers pick the
+// highest).
+type E131Source struct {
+	CID      [16]byte
+	Name     string
+	Priority uint8
+}
+
+// AppendE131 appends an E1.31 data packet (start code 0) for universe to
+// dst. A non-zero sync universe makes receivers hold the data until a sync
+// packet for it arrives.
+func AppendE131(dst []byte, src E131Source, universe, sync uint16, seq uint8, data []byte) []byte {
+	total := e131DataHeader + len(data)
+	dst = appendRoot(dst, e131RootData, src.CID, total)
+	dst = appendFlagsLen(dst, total-38)
+	dst = binary.BigEndian.AppendUint32(dst, e131FrameData)
+	var name [64]byte
+	copy(name[:63], src.Name)
+	dst = append(dst, name[:]...)
+	dst = append(dst, src.Priority)
+	dst = binary.BigEndian.AppendUint16(dst, sync)
+	dst = append(dst, seq, 0)
+	dst = binary.BigEndian.AppendUint16(dst, universe)
+	// DMP layer: set property, 1-byte values from address 0 step 1
+	dst = appendFlagsLen(dst, total-115)
+	dst = append(dst, 0x02, 0xa1, 0, 0, 0, 1)
+	dst = binary.BigEndian.AppendUint16(dst, uint16(len(data)+1))
+	dst = append(dst, 0) // start code
+	return append(dst, data...)
+}
+
+// AppendE131Sync appends an E1.31 universe synchronization packet for the
+// sync universe.
+func AppendE131Sync(dst []byte, cid [16]byte, sync uint16, seq uint8) []byte {
+	dst = appendRoot(dst, e131RootExtended, cid, e131SyncLen)
+	dst = appendFlagsLen(dst, e131SyncLen-38)
+	dst = binary.BigEndian.AppendUint32(dst, e131FrameSync)
+	dst = append(dst, seq)
+	dst = binary.BigEndian.AppendUint16(dst, sync)
+	return append(dst, 0, 0)
+}
+
+func appendRoot(dst []byte, vector uint32, cid [16]byte, total int) []byte {
+	dst = append(dst, 0x00, 0x10, 0x00, 0x00)
+	dst = append(dst, acnID...)
+	dst = appendFlagsLen(dst, total-16)
+	dst = binary.BigEndian.AppendUint32(dst, vector)
+	return append(dst, cid[:]...)
+}
+
+func appendFlagsLen(dst []byte, n int) []byte {
+	return binary.BigEndian.AppendUint16(dst, 0x7000|uint16(n&0x0fff))
+}
+
+// SACNMulticast is the multicast group an sACN universe is sent to.
+func SACNMulticast(universe uint16) *net.UDPAddr {
+	return &net.UDPAddr{IP: net.IPv4(239, 255, byte(universe>>8), byte(universe)), Port: SACNPort}
+}
+
+// Packet is a parsed ArtDmx, ArtSync, E1.31 data or E1.31 sync packet.
+// Sync packets carry no data; for E1.31 Universe is then the sync
+// universe. Data aliases the parsed buffer.
+type Packet struct {
+	Proto    string // ArtNet or SACN
+	Sync     bool
+	Universe uint16
+	Seq      uint8
+	Data     []byte
+
+	// E1.31 only
+	CID          [16]byte
+	Source       string
+	Priority     uint8
+	SyncUniverse uint16 // data held for this sync universe; 0 = none
+	Terminated   bool   // the source stopped sending this universe
+}
+
+// Parse decodes b. Other Art-Net opcodes, E1.31 extended discovery and
+// non-zero start codes are errors, so callers can skip them.
+func Parse(b []byte) (Packet, error) {
+	switch {
+	case bytes.HasPrefix(b, artID):
+		return parseArtNet(b)
+	case len(b) >= 38 && bytes.Equal(b[4:16], acnID):
+		return parseE131(b)
+	}
+	return Packet{}, errBad
+}
+
+func parseArtNet(b []byte) (Packet, error) {
+	if len(b) < 14 {
+		return Packet{}, fmt.Errorf("art-net: %d-byte packet", len(b))
+	}
+	p := Packet{Proto: ArtNet}
+	switch op := binary.LittleEndian.Uint16(b[8:]); op {
+	case artOpSync:
+		p.Sync = true
+		return p, nil
+	case artOpDmx:
+	default:
+		return p, fmt.Errorf("art-net: opcode %#04x", op)
+	}
+	if len(b) < 18 {
+		return p, fmt.Errorf("art-net: %d-byte ArtDmx", len(b))
+	}
+	n := int(binary.BigEndian.Uint16(b[16:]))
+	if n > UniverseSize || 18+n > len(b) {
+		return p, fmt.Errorf("art-net: length %d in a %d-byte packet", n, len(b))
+	}
+	p.Seq = b[12]
+	p.Universe = uint16(b[14]) | uint16(b[15]&0x7f)<<8
+	p.Data = b[18 : 18+n]
+	return p, nil
+}
+
+func parseE131(b []byte) (Packet, error) {
+	p := Packet{Proto: SACN}
+	copy(p.CID[:], b[22:38])
+	switch root := binary.BigEndian.Uint32(b[18:]); root {
+	case e131RootExtended:
+		if len(b) < e131SyncLen || binary.BigEndian.Uint32(b[40:]) != e131FrameSync {
+			return p, errors.New("e1.31: unsupported extended packet")
+		}
+		p.Sync, p.Seq, p.Universe = true, b[44], binary.BigEndian.Uint16(b[45:])
+		return p, nil
+	case e131RootData:
+	default:
+		return p, fmt.Errorf("e1.31: root vector %#x", root)
+	}
+	if len(b) < e131DataHeader || binary.BigEndian.Uint32(b[40:]) != e131FrameData {
+		return p, fmt.Errorf("e1.31: %d-byte data packet", len(b))
+	}
+	p.Source = string(bytes.TrimRight(b[44:108], "\x00"))
+	p.Priority = b[108]
+	p.SyncUniverse = binary.BigEndian.Uint16(b[109:])
+	p.Seq = b[111]
+	p.Terminated = b[112]&0x40 != 0
+	p.Universe = binary.BigEndian.Uint16(b[113:])
+	n := int(binary.BigEndian.Uint16(b[123:])) - 1
+	if b[117] != 0x02 || n < 0 || n > UniverseSize || e131DataHeader+n > len(b) {
+		return p, fmt.Errorf("e1.31: %d slots in a %d-byte packet", n, len(b))
+	}
+	if b[125] != 0 {
+		return p, fmt.Errorf("e1.31: start code %#02x", b[125])
+	}
+	p.Data = b[e131DataHeader : e131DataHeader+n]
+	return p, nil
+}
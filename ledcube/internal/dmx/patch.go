@@ -0,0 +1,132 @@
+package dmx
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Patch places a frame's channel bytes into universes, panel by panel
+// (panel p holds LEDs [p*perPanel, (p+1)*perPanel)). Pixels are never split
+// across universes: when the next one does not fit, it starts the next
+// universe at channel 0. A panel listed in Panels starts at its universe
+// and offset; the others start a fresh universe after the previous panel.
+type Patch struct {
+	StartUniverse int          // first universe of panel 0
+	Channels      int          // used per universe; 0 = 510 (170 RGB pixels)
+	Panels        []PanelPatch // per-panel starts, any order
+}
+
+// PanelPatch starts a panel at a universe and a 0-based channel offset.
+type PanelPatch struct {
+	Panel    int
+	Universe int
+	Offset   int
+}
+
+// Plan is a Patch resolved for one frame size: the universes in ascending
+// order, the data length of each and how the frame's bytes are copied in.
+type Plan struct {
+	Universes []uint16
+	Lengths   []int // channels used in each universe
+	runs      []run
+}
+
+// run copies n frame bytes from src to channel dst of universe u (an index
+// into Plan.Universes).
+type run struct{ src, u, dst, n int }
+
+// Plan resolves p for count pixels of chans channels, perPanel to a panel;
+// maxUniverse is the highest universe number the protocol allows.
+func (p Patch) Plan(count, perPanel, chans, maxUniverse int) (*Plan, error) {
+	per := p.Channels
+	if per <= 0 {
+		per = 510
+	}
+	if per > UniverseSize {
+		return nil, fmt.Errorf("dmx: %d channels per universe, at most %d", per, UniverseSize)
+	}
+	if per < chans {
+		return nil, fmt.Errorf("dmx: %d channels per universe cannot hold a %d-channel pixel", per, chans)
+	}
+	if perPanel <= 0 || count <= 0 || count%perPanel != 0 {
+		return nil, fmt.Errorf("dmx: %d LEDs do not make panels of %d", count, perPanel)
+	}
+	panels := count / perPanel
+	starts := map[int]PanelPatch{}
+	for _, pp := range p.Panels {
+		if pp.Panel < 0 || pp.Panel >= panels {
+			return nil, fmt.Errorf("dmx: panel %d outside 0..%d", pp.Panel, panels-1)
+		}
+		if pp.Offset < 0 || pp.Offset+chans > per {
+			return nil, fmt.Errorf("dmx: panel %d: offset %d leaves no room for a pixel in %d channels", pp.Panel, pp.Offset, per)
+		}
+		starts[pp.Panel] = pp
+	}
+
+	type slot struct{ uni, ch, px int } // one placed pixel
+	var slots []slot
+	uni, ch := p.StartUniverse, 0
+	for panel := 0; panel < panels; panel++ {
+		if pp, ok := starts[panel]; ok {
+			uni, ch = pp.Universe, pp.Offset
+		} else if panel > 0 && ch > 0 {
+			uni, ch = uni+1, 0
+		}
+		for i := panel * perPanel; i < (panel+1)*perPanel; i++ {
+			if ch+chans > per {
+				uni, ch = uni+1, 0
+			}
+			if uni < 0 || uni > maxUniverse {
+				return nil, fmt.Errorf("dmx: panel %d needs universe %d, outside 0..%d", panel, uni, maxUniverse)
+			}
+			slots = append(slots, slot{uni, ch, i})
+			ch += chans
+		}
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		if slots[i].uni != slots[j].uni {
+			return slots[i].uni < slots[j].uni
+		}
+		return slots[i].ch < slots[j].ch
+	})
+	plan := &Plan{}
+	for k, s := range slots {
+		if k > 0 && slots[k-1].uni == s.uni && slots[k-1].ch+chans > s.ch {
+			return nil, fmt.Errorf("dmx: LEDs %d and %d overlap in universe %d", slots[k-1].px, s.px, s.uni)
+		}
+		u := len(plan.Universes) - 1
+		if u < 0 || int(plan.Universes[u]) != s.uni {
+			plan.Universes = append(plan.Universes, uint16(s.uni))
+			plan.Lengths = append(plan.Lengths, 0)
+			u++
+		}
+		plan.Lengths[u] = s.ch + chans
+		// extend the previous run when both sides are contiguous
+		if r := len(plan.runs) - 1; r >= 0 {
+			last := &plan.runs[r]
+			if last.u == u && last.src+last.n == s.px*chans && last.dst+last.n == s.ch {
+				last.n += chans
+				continue
+			}
+		}
+		plan.runs = append(plan.runs, run{src: s.px * chans, u: u, dst: s.ch, n: chans})
+	}
+	return plan, nil
+}
+
+// Fill copies frame (wire-order channel bytes) into bufs, one per universe
+// sized to Lengths.
+func (pl *Plan) Fill(bufs [][]byte, frame []byte) {
+	for _, r := range pl.runs {
+		copy(bufs[r.u][r.dst:r.dst+r.n], frame[r.src:r.src+r.n])
+	}
+}
+
+// Extract is the inverse of Fill: it copies the patched channels of bufs
+// back into frame, for input from a console patched the same way.
+func (pl *Plan) Extract(frame []byte, bufs [][]byte) {
+	for _, r := range pl.runs {
+		copy(frame[r.src:r.src+r.n], bufs[r.u][r.dst:r.dst+r.n])
+	}
+}
@@ -0,0 +1,67 @@
+package dmx
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestPatchFillsUniversesByPanel(t *testing.T) {
+	// 2 panels of 200 RGB pixels: 170 + 30 per panel, each panel on fresh universes
+	pl, err := Patch{StartUniverse: 3}.Plan(400, 200, 3, MaxArtNetUniverse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint16{3, 4, 5, 6}; !slices.Equal(pl.Universes, want) {
+		t.Fatalf("universes %v, want %v", pl.Universes, want)
+	}
+	if want := []int{510, 90, 510, 90}; !slices.Equal(pl.Lengths, want) {
+		t.Fatalf("lengths %v, want %v", pl.Lengths, want)
+	}
+	frame := make([]byte, 1200)
+	for i := range frame {
+		frame[i] = byte(i / 3)
+	}
+	bufs := [][]byte{make([]byte, 510), make([]byte, 90), make([]byte, 510), make([]byte, 90)}
+	pl.Fill(bufs, frame)
+	if !bytes.Equal(bufs[1], frame[510:600]) || !bytes.Equal(bufs[2], frame[600:1110]) {
+		t.Fatal("panel 1 not at the start of universe 5")
+	}
+	back := make([]byte, len(frame))
+	pl.Extract(back, bufs)
+	if !bytes.Equal(back, frame) {
+		t.Fatal("Extract does not invert Fill")
+	}
+
+	// panel 1 placed after panel 0 in the same universe at a channel
+	// offset; 4-channel pixels never straddle a universe
+	pl, err = Patch{Channels: 512, Panels: []PanelPatch{{Panel: 1, Universe: 0, Offset: 40}}}.Plan(20, 10, 4, MaxArtNetUniverse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(pl.Universes, []uint16{0}) || pl.Lengths[0] != 80 {
+		t.Fatalf("universes %v lengths %v", pl.Universes, pl.Lengths)
+	}
+	pl, err = Patch{Channels: 510}.Plan(130, 130, 4, MaxArtNetUniverse)
+	if err != nil || !slices.Equal(pl.Lengths, []int{508, 12}) {
+		t.Fatalf("rgbw lengths %v (%v)", pl.Lengths, err)
+	}
+}
+
+func TestPatchRejects(t *testing.T) {
+	for name, c := range map[string]struct {
+		p                      Patch
+		count, perPanel, chans int
+	}{
+		"overlap":         {Patch{Panels: []PanelPatch{{Panel: 1, Universe: 0, Offset: 3}}}, 4, 2, 3},
+		"panel range":     {Patch{Panels: []PanelPatch{{Panel: 2}}}, 4, 2, 3},
+		"offset":          {Patch{Panels: []PanelPatch{{Panel: 0, Offset: 509}}}, 4, 2, 3},
+		"universe range":  {Patch{StartUniverse: MaxArtNetUniverse}, 400, 200, 3},
+		"partial panel":   {Patch{}, 5, 2, 3},
+		"oversized slots": {Patch{Channels: 600}, 4, 2, 3},
+	} {
+		if _, err := c.p.Plan(c.count, c.perPanel, c.chans, MaxArtNetUniverse); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package dmx
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Merge modes for several sources sending the same universe.
+const (
+	MergeHTP = "htp" // highest takes precedence, channel by channel
+	MergeLTP = "ltp" // latest takes precedence: the most recent change, channel by channel
+)
+
+// DefaultTimeout is how long a source stays live after its last packet:
+// the E1.31 network data loss timeout.
+const DefaultTimeout = 2500 * time.Millisecond
+
+// artNetPriority is the sACN priority Art-Net sources merge at.
+const artNetPriority = 100
+
+// ReceiverConfig sets up a Receiver.
+type ReceiverConfig struct {
+	// ArtNet and SACN are the UDP addresses to listen on ("" = off), e.g.
+	// ":6454" and ":5568". An sACN socket on the unspecified address also
+	// joins the multicast group of every universe.
+	ArtNet string
+	SACN   string
+
+	Universes []uint16      // accepted; packets for others are dropped
+	Merge     string        // MergeHTP (default) or MergeLTP
+	Timeout   time.Duration // source loss; 0 = DefaultTimeout
+}
+
+// Receiver takes DMX data from lighting consoles over Art-Net and sACN and
+// merges the sources of each universe. Only sources with the highest sACN
+// priority (Art-Net counts as 100) take part; a source drops out when it
+// terminates its stream or has been silent for the timeout. Sync packets
+// are ignored: data counts when it arrives.
+type Receiver struct {
+	cfg ReceiverConfig
+
+	mu    sync.Mutex
+	conns []*net.UDPConn
+	unis  map[uint16]map[string]*source
+	wg    sync.WaitGroup
+}
+
+// source is one sender's latest data for one universe.
+type source struct {
+	data    [UniverseSize]byte
+	changed [UniverseSize]time.Time // when each channel last took a new value (LTP)
+	n       int
+	prio    uint8
+	seq     uint8
+	seen    time.Time
+}
+
+// NewReceiver checks cfg; Open starts listening.
+func NewReceiver(cfg ReceiverConfig) (*Receiver, error) {
+	switch cfg.Merge {
+	case "":
+		cfg.Merge = MergeHTP
+	case MergeHTP, MergeLTP:
+	default:
+		return nil, fmt.Errorf("dmx: unknown merge mode %q (htp or ltp)", cfg.Merge)
+	}
+	if cfg.ArtNet == "" && cfg.SACN == "" {
+		return nil, errors.New("dmx: receiver listens on neither Art-Net nor sACN")
+	}
+	if len(cfg.Universes) == 0 {
+		return nil, errors.New("dmx: receiver has no universes")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	r := &Receiver{cfg: cfg, unis: map[uint16]map[string]*source{}}
+	for _, u := range cfg.Universes {
+		r.unis[u] = map[string]*source{}
+	}
+	return r, nil
+}
+
+// Open binds the sockets and starts reading; it is a no-op while open.
+func (r *Receiver) Open() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conns != nil {
+		return nil
+	}
+	var conns []*net.UDPConn
+	fail := func(err error) error {
+		for _, c := range conns {
+			_ = c.Close()
+		}
+		return err
+	}
+	for _, l := range []struct{ proto, addr string }{{ArtNet, r.cfg.ArtNet}, {SACN, r.cfg.SACN}} {
+		if l.addr == "" {
+			continue
+		}
+		a, err := net.ResolveUDPAddr("udp4", l.addr)
+		if err != nil {
+			return fail(fmt.Errorf("%s listen %s: %w", l.proto, l.addr, err))
+		}
+		c, err := net.ListenUDP("udp4", a)
+		if err != nil {
+			return fail(fmt.Errorf("%s listen %s: %w", l.proto, l.addr, err))
+		}
+		conns = append(conns, c)
+		if l.proto == SACN && (a.IP == nil || a.IP.IsUnspecified()) {
+			var groups []*net.UDPAddr
+			for _, u := range r.cfg.Universes {
+				groups = append(groups, SACNMulticast(u))
+			}
+			if err := joinGroups(c, groups); err != nil {
+				return fail(fmt.Errorf("sacn multicast: %w", err))
+			}
+		}
+	}
+	r.conns = conns
+	for _, c := range conns {
+		r.wg.Add(1)
+		go r.read(c)
+	}
+	return nil
+}
+
+// Close stops listening and forgets every source.
+func (r *Receiver) Close() error {
+	r.mu.Lock()
+	conns := r.conns
+	r.conns = nil
+	r.mu.Unlock()
+	var first error
+	for _, c := range conns {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	r.wg.Wait()
+	r.mu.Lock()
+	for _, srcs := range r.unis {
+		clear(srcs)
+	}
+	r.mu.Unlock()
+	return first
+}
+
+// Addrs lists the bound addresses, Art-Net first.
+func (r *Receiver) Addrs() []net.Addr {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []net.Addr
+	for _, c := range r.conns {
+		out = append(out, c.LocalAddr())
+	}
+	return out
+}
+
+func (r *Receiver) read(c *net.UDPConn) {
+	defer r.wg.Done()
+	buf := make([]byte, 1500)
+	for {
+		n, from, err := c.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		p, err := Parse(buf[:n])
+		if err != nil {
+			continue
+		}
+		r.handle(p, from.String(), time.Now())
+	}
+}
+
+// handle merges one parsed packet from the sender at addr into its
+// universe's sources.
+func (r *Receiver) handle(p Packet, addr string, now time.Time) {
+	if p.Sync {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	srcs, ok := r.unis[p.Universe]
+	if !ok {
+		return
+	}
+	key, prio := p.Proto+" "+addr, uint8(artNetPriority)
+	if p.Proto == SACN {
+		key, prio = p.Proto+" "+string(p.CID[:]), p.Priority
+	}
+	s := srcs[key]
+	if p.Terminated {
+		delete(srcs, key)
+		return
+	}
+	if s == nil {
+		s = &source{}
+		srcs[key] = s
+	} else if p.Seq != 0 && now.Sub(s.seen) < r.cfg.Timeout {
+		// E1.31 6.7.2: drop late and duplicate packets
+		if d := int8(p.Seq - s.seq); d <= 0 && d > -20 {
+			return
+		}
+	}
+	n := min(len(p.Data), UniverseSize)
+	for i, v := range p.Data[:n] {
+		if i >= s.n || s.data[i] != v {
+			s.data[i], s.changed[i] = v, now
+		}
+	}
+	s.n = n
+	s.prio, s.seq, s.seen = prio, p.Seq, now
+}
+
+// Universe writes the merged data of universe u into dst (channel 1 at
+// dst[0]; channels no source sent are 0) and reports whether any source
+// is live. Sources past the timeout are dropped.
+func (r *Receiver) Universe(u uint16, dst []byte, now time.Time) bool {
+	clear(dst)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	srcs := r.unis[u]
+	var top uint8
+	for k, s := range srcs {
+		if now.Sub(s.seen) >= r.cfg.Timeout {
+			delete(srcs, k)
+			continue
+		}
+		top = max(top, s.prio)
+	}
+	if len(srcs) == 0 {
+		return false
+	}
+	if r.cfg.Merge == MergeLTP {
+		// a source resending the same values does not take a channel
+		// back; the one that moved it last keeps it
+		for i := range dst {
+			var at time.Time
+			for _, s := range srcs {
+				if s.prio == top && i < s.n && (at.IsZero() || s.changed[i].After(at)) {
+					dst[i], at = s.data[i], s.changed[i]
+				}
+			}
+		}
+		return true
+	}
+	for _, s := range srcs {
+		if s.prio != top {
+			continue
+		}
+		for i, v := range s.data[:min(s.n, len(dst))] {
+			dst[i] = max(dst[i], v)
+		}
+	}
+	return true
+}
+
+// Live reports whether any universe has a live source.
+func (r *Receiver) Live(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, srcs := range r.unis {
+		for _, s := range srcs {
+			if now.Sub(s.seen) < r.cfg.Timeout {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Universes lists the accepted universes.
+func (r *Receiver) Universes() []uint16 { return slices.Clone(r.cfg.Universes) }
@@ -0,0 +1,165 @@
+package dmx
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+func artDmx(t *testing.T, u uint16, seq uint8, data ...byte) Packet {
+	t.Helper()
+	p, err := Parse(AppendArtDmx(nil, u, seq, data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func e131(t *testing.T, id byte, prio uint8, u uint16, seq uint8, data ...byte) Packet {
+	t.Helper()
+	src := E131Source{Name: "console", Priority: prio}
+	src.CID[0] = id
+	p, err := Parse(AppendE131(nil, src, u, 0, seq, data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func newTestReceiver(t *testing.T, merge string) *Receiver {
+	t.Helper()
+	r, err := NewReceiver(ReceiverConfig{ArtNet: ":0", Universes: []uint16{1, 2}, Merge: merge, Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func universe(r *Receiver, u uint16, now time.Time) ([]byte, bool) {
+	buf := make([]byte, 4)
+	live := r.Universe(u, buf, now)
+	return buf, live
+}
+
+func TestReceiverMergesHTP(t *testing.T) {
+	r := newTestReceiver(t, "")
+	t0 := time.Unix(100, 0)
+	r.handle(artDmx(t, 1, 1, 10, 200, 30, 0), "10.0.0.1:6454", t0)
+	r.handle(artDmx(t, 1, 1, 50, 100, 0, 0), "10.0.0.2:6454", t0.Add(10*time.Millisecond))
+	r.handle(artDmx(t, 9, 1, 255, 255), "10.0.0.2:6454", t0) // not ours
+	got, live := universe(r, 1, t0.Add(20*time.Millisecond))
+	if !live || !bytes.Equal(got, []byte{50, 200, 30, 0}) {
+		t.Fatalf("HTP = %v (live %v), want [50 200 30 0]", got, live)
+	}
+	if _, live := universe(r, 2, t0); live {
+		t.Fatal("universe 2 live without a source")
+	}
+	// the first source times out; the second is all that is left
+	got, _ = universe(r, 1, t0.Add(1005*time.Millisecond))
+	if !bytes.Equal(got, []byte{50, 100, 0, 0}) {
+		t.Fatalf("after timeout = %v, want [50 100 0 0]", got)
+	}
+	if r.Live(t0.Add(2 * time.Second)) {
+		t.Fatal("live after every source timed out")
+	}
+}
+
+func TestReceiverMergesLTP(t *testing.T) {
+	r := newTestReceiver(t, MergeLTP)
+	t0 := time.Unix(100, 0)
+	r.handle(artDmx(t, 1, 1, 10, 200), "10.0.0.1:6454", t0)
+	r.handle(artDmx(t, 1, 1, 50, 100), "10.0.0.2:6454", t0.Add(time.Millisecond))
+	if got, _ := universe(r, 1, t0); !bytes.Equal(got, []byte{50, 100, 0, 0}) {
+		t.Fatalf("LTP = %v, want the later source", got)
+	}
+	// streaming the same values again takes nothing back
+	r.handle(artDmx(t, 1, 2, 10, 200), "10.0.0.1:6454", t0.Add(2*time.Millisecond))
+	if got, _ := universe(r, 1, t0); !bytes.Equal(got, []byte{50, 100, 0, 0}) {
+		t.Fatalf("LTP = %v, want the unchanged repeat ignored", got)
+	}
+	// a change wins only the channel that moved
+	r.handle(artDmx(t, 1, 3, 1, 200), "10.0.0.1:6454", t0.Add(3*time.Millisecond))
+	if got, _ := universe(r, 1, t0); !bytes.Equal(got, []byte{1, 100, 0, 0}) {
+		t.Fatalf("LTP = %v, want [1 100 0 0]", got)
+	}
+}
+
+func TestReceiverSACNPriorityAndSequence(t *testing.T) {
+	r := newTestReceiver(t, "")
+	t0 := time.Unix(100, 0)
+	r.handle(e131(t, 1, 100, 2, 7, 255, 255), "10.0.0.1:5568", t0)
+	r.handle(e131(t, 2, 150, 2, 1, 10, 20), "10.0.0.2:5568", t0)
+	if got, _ := universe(r, 2, t0); !bytes.Equal(got, []byte{10, 20, 0, 0}) {
+		t.Fatalf("priority = %v, want only the priority 150 source", got)
+	}
+	// a duplicate or late packet is dropped, a new one taken
+	r.handle(e131(t, 2, 150, 2, 1, 99, 99), "10.0.0.2:5568", t0)
+	r.handle(e131(t, 2, 150, 2, 250, 99, 99), "10.0.0.2:5568", t0)
+	if got, _ := universe(r, 2, t0); !bytes.Equal(got, []byte{10, 20, 0, 0}) {
+		t.Fatalf("stale packets applied: %v", got)
+	}
+	r.handle(e131(t, 2, 150, 2, 2, 30, 40), "10.0.0.2:5568", t0)
+	if got, _ := universe(r, 2, t0); !bytes.Equal(got, []byte{30, 40, 0, 0}) {
+		t.Fatalf("next packet = %v, want [30 40 0 0]", got)
+	}
+	// terminating the stream hands the universe back to the lower priority
+	p := e131(t, 2, 150, 2, 3)
+	p.Terminated = true
+	r.handle(p, "10.0.0.2:5568", t0)
+	if got, _ := universe(r, 2, t0); !bytes.Equal(got, []byte{255, 255, 0, 0}) {
+		t.Fatalf("after termination = %v, want the priority 100 source", got)
+	}
+}
+
+func TestReceiverOverUDP(t *testing.T) {
+	r, err := NewReceiver(ReceiverConfig{ArtNet: "127.0.0.1:0", SACN: "127.0.0.1:0", Universes: []uint16{3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Open(); err != nil {
+		t.Skipf("no local UDP: %v", err)
+	}
+	defer r.Close()
+	addrs := r.Addrs()
+	if len(addrs) != 2 {
+		t.Fatalf("bound %v, want Art-Net and sACN", addrs)
+	}
+	c, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Skipf("no local UDP: %v", err)
+	}
+	defer c.Close()
+	send := func(pkt []byte, to net.Addr) {
+		if _, err := c.WriteTo(pkt, to); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wait := func(want []byte) {
+		t.Helper()
+		buf := make([]byte, len(want))
+		deadline := time.Now().Add(2 * time.Second)
+		for !r.Universe(3, buf, time.Now()) || !bytes.Equal(buf, want) {
+			if time.Now().After(deadline) {
+				t.Fatalf("universe 3 = %v, want %v", buf, want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	send(AppendArtDmx(nil, 3, 1, []byte{1, 2, 3, 4}), addrs[0])
+	wait([]byte{1, 2, 3, 4})
+	send(AppendE131(nil, E131Source{Priority: 100}, 3, 0, 1, []byte{9, 0, 9, 0}), addrs[1])
+	wait([]byte{9, 2, 9, 4})
+}
+
+func TestReceiverRejects(t *testing.T) {
+	for _, cfg := range []ReceiverConfig{
+		{Universes: []uint16{1}},
+		{ArtNet: ":0"},
+		{ArtNet: ":0", Universes: []uint16{1}, Merge: "avg"},
+	} {
+		if _, err := NewReceiver(cfg); err == nil {
+			t.Errorf("%+v accepted", cfg)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package dmx
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/led"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+// Config sets up a Sender.
+type Config struct {
+	Protocol string   // ArtNet or SACN
+	Targets  []string // host or host:port; empty = broadcast (Art-Net) or per-universe multicast (sACN)
+	Patch    Patch
+
+	// Sync sends ArtSync / E1.31 sync after each frame so every universe
+	// latches together. SyncUniverse is the sACN sync address; 0 = the
+	// first data universe.
+	Sync         bool
+	SyncUniverse int
+
+	Source   string // sACN source name; "" = "ledcube"
+	Priority int    // sACN priority 1..200; 0 = 100
+}
+
+// Sender is a led.ByteDriver that sends each frame to remote pixel
+// controllers (Falcon, ESPixelStick, WLED, ...) as DMX universes over
+// Art-Net or sACN, optionally followed by a sync packet.
+type Sender struct {
+	cfg   Config
+	count int
+	order string
+	chans int
+	plan  *Plan
+	src   E131Source
+	syncU uint16
+
+	mu     sync.Mutex
+	conn   *net.UDPConn
+	addrs  []*net.UDPAddr // unicast/broadcast targets
+	group  []*net.UDPAddr // sACN multicast group per universe, without targets
+	syncTo []*net.UDPAddr // where the sync packet goes
+	bufs   [][]byte       // channel data per universe
+	seq    []uint8        // per universe
+	pkt    []byte         // packet scratch
+	sseq   uint8          // sync sequence
+}
+
+// NewSender patches count pixels (perPanel to a panel) in colorOrder ("" =
+// RGB, the usual order for pixel controllers; four-channel orders send
+// RGBW) for cfg. Open resolves the targets and opens the socket.
+func NewSender(cfg Config, count, perPanel int, colorOrder string) (*Sender, error) {
+	if colorOrder == "" {
+		colorOrder = "RGB"
+	}
+	o, err := led.ParseOrder(colorOrder)
+	if err != nil {
+		return nil, err
+	}
+	maxU := MaxArtNetUniverse
+	switch cfg.Protocol {
+	case ArtNet:
+	case SACN:
+		maxU = MaxSACNUniverse
+		if cfg.Patch.StartUniverse == 0 && len(cfg.Patch.Panels) == 0 {
+			cfg.Patch.StartUniverse = 1
+		}
+	default:
+		return nil, fmt.Errorf("dmx: unknown protocol %q (artnet or sacn)", cfg.Protocol)
+	}
+	plan, err := cfg.Patch.Plan(count, perPanel, len(o), maxU)
+	if err != nil {
+		return nil, err
+	}
+	s := &Sender{cfg: cfg, count: count, order: strings.ToUpper(colorOrder), chans: len(o), plan: plan}
+	if cfg.Protocol == SACN {
+		if plan.Universes[0] < 1 {
+			return nil, errors.New("dmx: sACN universes start at 1")
+		}
+		s.src = E131Source{Name: cfg.Source, Priority: 100}
+		if s.src.Name == "" {
+			s.src.Name = "ledcube"
+		}
+		if cfg.Priority != 0 {
+			if cfg.Priority < 1 || cfg.Priority > 200 {
+				return nil, fmt.Errorf("dmx: sACN priority %d outside 1..200", cfg.Priority)
+			}
+			s.src.Priority = uint8(cfg.Priority)
+		}
+		_, _ = rand.Read(s.src.CID[:])
+		if cfg.Sync {
+			s.syncU = plan.Universes[0]
+			if cfg.SyncUniverse != 0 {
+				if cfg.SyncUniverse < 1 || cfg.SyncUniverse > MaxSACNUniverse {
+					return nil, fmt.Errorf("dmx: sync universe %d outside 1..%d", cfg.SyncUniverse, MaxSACNUniverse)
+				}
+				s.syncU = uint16(cfg.SyncUniverse)
+			}
+		}
+	}
+	s.bufs = make([][]byte, len(plan.Universes))
+	for i, n := range plan.Lengths {
+		s.bufs[i] = make([]byte, n)
+	}
+	s.seq = make([]uint8, len(plan.Universes))
+	return s, nil
+}
+
+// Caps reports 8-bit pixels in the configured order; the network sets no
+// frame-rate bound.
+func (s *Sender) Caps() render.Caps {
+	layout := render.LayoutRGB
+	if s.chans == 4 {
+		layout = render.LayoutRGBW
+	}
+	return render.Caps{Pixels: s.count, Layout: layout, BitDepth: 8, Order: s.order}
+}
+
+// Universes lists the universes a frame is sent on.
+func (s *Sender) Universes() []uint16 { return append([]uint16(nil), s.plan.Universes...) }
+
+// Open resolves the targets and opens the sending socket.
+func (s *Sender) Open() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		return nil
+	}
+	port := ArtNetPort
+	if s.cfg.Protocol == SACN {
+		port = SACNPort
+	}
+	targets := s.cfg.Targets
+	if len(targets) == 0 && s.cfg.Protocol == ArtNet {
+		targets = []string{"255.255.255.255"}
+	}
+	var addrs []*net.UDPAddr
+	for _, t := range targets {
+		if _, _, err := net.SplitHostPort(t); err != nil {
+			t = net.JoinHostPort(t, strconv.Itoa(port))
+		}
+		a, err := net.ResolveUDPAddr("udp4", t)
+		if err != nil {
+			return fmt.Errorf("%s target %s: %w", s.cfg.Protocol, t, err)
+		}
+		addrs = append(addrs, a)
+	}
+	var group []*net.UDPAddr
+	if len(addrs) == 0 {
+		for _, u := range s.plan.Universes {
+			group = append(group, SACNMulticast(u))
+		}
+	}
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", s.cfg.Protocol, err)
+	}
+	s.conn, s.addrs, s.group, s.syncTo = conn, addrs, group, addrs
+	if len(addrs) == 0 {
+		s.syncTo = []*net.UDPAddr{SACNMulticast(s.syncU)}
+	}
+	return nil
+}
+
+func (s *Sender) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// WriteBytes patches px (count pixels in wire order) into the universes and
+// sends one packet per universe to every target, then the sync packet. A
+// failed send does not stop the others; the first error is returned.
+func (s *Sender) WriteBytes(px []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return fmt.Errorf("%s closed", s.cfg.Protocol)
+	}
+	if len(px) != s.count*s.chans {
+		return fmt.Errorf("frame length %d does not match count %d", len(px), s.count)
+	}
+	s.plan.Fill(s.bufs, px)
+	var first error
+	send := func(pkt []byte, to *net.UDPAddr) {
+		if _, err := s.conn.WriteToUDP(pkt, to); err != nil && first == nil {
+			first = fmt.Errorf("%s send to %s: %w", s.cfg.Protocol, to, err)
+		}
+	}
+	for i, u := range s.plan.Universes {
+		s.seq[i]++
+		if s.cfg.Protocol == ArtNet {
+			if s.seq[i] == 0 {
+				s.seq[i] = 1 // 0 turns resequencing off
+			}
+			s.pkt = AppendArtDmx(s.pkt[:0], u, s.seq[i], s.bufs[i])
+		} else {
+			s.pkt = AppendE131(s.pkt[:0], s.src, u, s.syncU, s.seq[i], s.bufs[i])
+		}
+		for _, a := range s.addrs {
+			send(s.pkt, a)
+		}
+		if s.group != nil {
+			send(s.pkt, s.group[i])
+		}
+	}
+	if !s.cfg.Sync {
+		return first
+	}
+	if s.cfg.Protocol == ArtNet {
+		s.pkt = AppendArtSync(s.pkt[:0])
+	} else {
+		s.sseq++
+		s.pkt = AppendE131Sync(s.pkt[:0], s.src.CID, s.syncU, s.sseq)
+	}
+	for _, a := range s.syncTo {
+		send(s.pkt, a)
+	}
+	return first
+}
@@ -0,0 +1,168 @@
+package dmx
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+// listen opens a local UDP receiver standing in for a pixel controller.
+func listen(t *testing.T) (*net.UDPConn, string) {
+	t.Helper()
+	c, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Skipf("no local UDP: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c, c.LocalAddr().String()
+}
+
+// receive parses the next n packets.
+func receive(t *testing.T, c *net.UDPConn, n int) []Packet {
+	t.Helper()
+	var out []Packet
+	for len(out) < n {
+		buf := make([]byte, 1500)
+		_ = c.SetReadDeadline(time.Now().Add(2 * time.Second))
+		k, _, err := c.ReadFromUDP(buf)
+		if err != nil {
+			t.Fatalf("after %d packets: %v", len(out), err)
+		}
+		p, err := Parse(buf[:k])
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+func testFrame(n int) []byte {
+	px := make([]byte, n)
+	for i := range px {
+		px[i] = byte(i * 7)
+	}
+	return px
+}
+
+func TestArtNetSender(t *testing.T) {
+	rx, addr := listen(t)
+	s, err := NewSender(Config{Protocol: ArtNet, Targets: []string{addr}, Patch: Patch{StartUniverse: 0x123}, Sync: true}, 400, 200, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	px := testFrame(1200)
+	for frame := 1; frame <= 2; frame++ {
+		if err := s.WriteBytes(px); err != nil {
+			t.Fatal(err)
+		}
+		got := receive(t, rx, 5)
+		wantU := []uint16{0x123, 0x124, 0x125, 0x126}
+		wantData := [][]byte{px[0:510], px[510:600], px[600:1110], px[1110:1200]}
+		for i, p := range got[:4] {
+			if p.Proto != ArtNet || p.Sync || p.Universe != wantU[i] || p.Seq != uint8(frame) {
+				t.Fatalf("frame %d packet %d = %+v", frame, i, p)
+			}
+			if !bytes.Equal(p.Data, wantData[i]) {
+				t.Fatalf("frame %d universe %#x data differs", frame, p.Universe)
+			}
+		}
+		if !got[4].Sync {
+			t.Fatalf("frame %d: last packet %+v, want ArtSync", frame, got[4])
+		}
+	}
+}
+
+func TestSACNSender(t *testing.T) {
+	rx, addr := listen(t)
+	cfg := Config{
+		Protocol: SACN, Targets: []string{addr}, Source: "cube", Priority: 150, Sync: true, SyncUniverse: 999,
+		Patch: Patch{Panels: []PanelPatch{{Panel: 0, Universe: 7, Offset: 12}, {Panel: 1, Universe: 9}}},
+	}
+	s, err := NewSender(cfg, 20, 10, "GRBW")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	px := testFrame(80)
+	_ = s.WriteBytes(px)
+	_ = s.WriteBytes(px)
+	got := receive(t, rx, 6)
+	for i, p := range got {
+		frame, k := i/3, i%3
+		if k == 2 {
+			if !p.Sync || p.Universe != 999 || p.Seq != uint8(frame+1) {
+				t.Fatalf("packet %d = %+v, want sync on 999", i, p)
+			}
+			continue
+		}
+		if p.Proto != SACN || p.Source != "cube" || p.Priority != 150 || p.SyncUniverse != 999 || p.Seq != uint8(frame+1) {
+			t.Fatalf("packet %d = %+v", i, p)
+		}
+		if p.CID != got[0].CID || p.CID == ([16]byte{}) {
+			t.Fatalf("packet %d: CID %x", i, p.CID)
+		}
+		switch p.Universe {
+		case 7:
+			if len(p.Data) != 52 || !bytes.Equal(p.Data[12:], px[:40]) || !bytes.Equal(p.Data[:12], make([]byte, 12)) {
+				t.Fatalf("universe 7 = % x", p.Data)
+			}
+		case 9:
+			if !bytes.Equal(p.Data, px[40:]) {
+				t.Fatalf("universe 9 = % x", p.Data)
+			}
+		default:
+			t.Fatalf("packet on universe %d", p.Universe)
+		}
+	}
+}
+
+func TestSenderRejects(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"protocol":      {Protocol: "kinet"},
+		"priority":      {Protocol: SACN, Priority: 201},
+		"universe 0":    {Protocol: SACN, Patch: Patch{Panels: []PanelPatch{{Panel: 0, Universe: 0}}}},
+		"sync universe": {Protocol: SACN, Sync: true, SyncUniverse: 64000},
+	} {
+		if _, err := NewSender(cfg, 10, 10, ""); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+	s, err := NewSender(Config{Protocol: ArtNet}, 10, 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteBytes(make([]byte, 30)); err == nil {
+		t.Error("write before Open accepted")
+	}
+}
+
+func TestParseRejectsOddPackets(t *testing.T) {
+	dmx := AppendArtDmx(nil, 1, 1, []byte{1, 2, 3})
+	if p, err := Parse(dmx); err != nil || len(p.Data) != 4 {
+		t.Fatalf("odd ArtDmx padded to %d (%v)", len(p.Data), err)
+	}
+	for name, b := range map[string][]byte{
+		"short":     dmx[:16],
+		"truncated": dmx[:len(dmx)-1],
+		"garbage":   []byte("hello world"),
+		"art poll":  append(append([]byte(nil), dmx[:8]...), 0x00, 0x20, 0, 14, 0, 0),
+		"start code": func() []byte {
+			b := AppendE131(nil, E131Source{Priority: 100}, 1, 0, 1, []byte{1})
+			b[125] = 0xdd
+			return b
+		}(),
+	} {
+		if _, err := Parse(b); err == nil {
+			t.Errorf("%s: parsed", name)
+		}
+	}
+}
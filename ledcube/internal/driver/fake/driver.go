@@ -11,6 +11,15 @@ type Driver struct {
 	Count int
 }
 
+func (d *Driver) Caps() render.Caps { return render.Caps{Layout: render.LayoutRGB, BitDepth: 32} }
+func (d *Driver) Open() error       { return nil }
+func (d *Driver) Close() error      { return nil }
+
+func (d *Driver) Blank() error {
+	fmt.Printf("[frame %04d] blank\n", d.Count)
+	return nil
+}
+
 func (d *Driver) Write(buf []render.Color) error {
 	d.Count++
 	// compute simple average for log
@@ -6,15 +6,22 @@ import (
 	"sync"
 	"time"
 
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/led"
 	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// Driver emits throttled 8-bit frames to the Wails UI as "preview:frame"
+// events.
 type Driver struct {
 	ctx      context.Context
 	dim      render.Dimensions
 	throttle time.Duration
 	lastEmit time.Time
+	dither   *led.Dither   // nil = plain rounding
+	white    *render.White // RGBW split to show; nil = RGB strip
+	show     []render.Color
+	rgb      []byte // reused for every emitted frame
 	mu       sync.Mutex
 }
 
@@ -26,14 +33,45 @@ func New(ctx context.Context, dim render.Dimensions) *Driver {
 	}
 }
 
-func clamp255(x float32) byte {
-	if x <= 0 {
-		return 0
+// SetDither switches temporal dithering of emitted frames on or off.
+func (d *Driver) SetDither(on bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !on {
+		d.dither = nil
+	} else if d.dither == nil {
+		d.dither = led.NewDither(led.DefaultDitherThreshold)
 	}
-	if x >= 1 {
-		return 255
+}
+
+// ShowWhite has emitted frames look like an RGBW strip splitting colors
+// with w: the white die adds its own tint (see render.White.Emit). nil
+// shows plain RGB.
+func (d *Driver) ShowWhite(w *render.White) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.white = w
+}
+
+func (d *Driver) Caps() render.Caps {
+	return render.Caps{
+		Pixels:   d.dim.X * d.dim.Y * d.dim.Z,
+		Layout:   render.LayoutRGB,
+		BitDepth: 8,
+		MaxFPS:   float64(time.Second / d.throttle),
+		Order:    "RGB",
 	}
-	return byte(x * 255.0)
+}
+
+func (d *Driver) Open() error  { return nil }
+func (d *Driver) Close() error { return nil }
+
+// Blank emits an all-off frame, bypassing the throttle.
+func (d *Driver) Blank() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.emit(make([]byte, d.dim.X*d.dim.Y*d.dim.Z*3))
+	return nil
 }
 
 func (d *Driver) Write(buf []render.Color) error {
@@ -46,16 +84,27 @@ func (d *Driver) Write(buf []render.Color) error {
 	}
 	d.lastEmit = now
 
-	rgb := make([]byte, len(buf)*3)
-	for i := range buf {
-		rgb[i*3+0] = clamp255(buf[i].R)
-		rgb[i*3+1] = clamp255(buf[i].G)
-		rgb[i*3+2] = clamp255(buf[i].B)
+	if len(d.rgb) != len(buf)*3 {
+		d.rgb = make([]byte, len(buf)*3)
+	}
+	if d.white != nil {
+		if len(d.show) != len(buf) {
+			d.show = make([]render.Color, len(buf))
+		}
+		for i, c := range buf {
+			d.show[i] = d.white.Emit(c)
+		}
+		buf = d.show
 	}
+	d.dither.ToRGB(d.rgb, buf, 1) // nil-safe
+	d.emit(d.rgb)
+	return nil
+}
+
+func (d *Driver) emit(rgb []byte) {
 	payload := map[string]any{
 		"x": d.dim.X, "y": d.dim.Y, "z": d.dim.Z,
 		"rgb": base64.StdEncoding.EncodeToString(rgb),
 	}
 	runtime.EventsEmit(d.ctx, "preview:frame", payload)
-	return nil
 }
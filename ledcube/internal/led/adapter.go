@@ -0,0 +1,223 @@
+package led
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+// ByteDriver is a transport that takes quantized frames: Caps().Layout
+// channels per pixel, already in Caps().Order. SPI, PWM and Sim implement
+// it; Adapter turns one into a render.Driver.
+type ByteDriver interface {
+	Caps() render.Caps
+	Open() error
+	WriteBytes(px []byte) error
+	Close() error
+}
+
+// ChannelOrder maps each wire slot to its source channel (0=R 1=G 2=B 3=W).
+type ChannelOrder []uint8
+
+// ParseOrder parses a wire order such as "GRB" or "GRBW" (case-insensitive).
+func ParseOrder(s string) (ChannelOrder, error) {
+	s = strings.ToUpper(s)
+	if len(s) != 3 && len(s) != 4 {
+		return nil, fmt.Errorf("color order %q: want 3 or 4 of R, G, B, W", s)
+	}
+	o := make(ChannelOrder, len(s))
+	seen := 0
+	for i := 0; i < len(s); i++ {
+		ch := strings.IndexByte("RGBW", s[i])
+		if ch < 0 || seen&(1<<ch) != 0 {
+			return nil, fmt.Errorf("color order %q: want 3 or 4 of R, G, B, W", s)
+		}
+		seen |= 1 << ch
+		o[i] = uint8(ch)
+	}
+	if seen&7 != 7 {
+		return nil, fmt.Errorf("color order %q: R, G and B are required", s)
+	}
+	return o, nil
+}
+
+// Layout returns RGBW for a four-slot order, RGB otherwise.
+func (o ChannelOrder) Layout() render.ChannelLayout {
+	if len(o) == 4 {
+		return render.LayoutRGBW
+	}
+	return render.LayoutRGB
+}
+
+// Pack reorders 8-bit RGB triplets into dst in wire order, len(o) bytes per
+// pixel. For RGBW the common part of R, G and B moves to the white channel
+// (the Adapter splits before quantizing instead, see PackRGBW).
+// Pixels that do not fit dst are dropped; dst bytes past rgb are zeroed.
+func (o ChannelOrder) Pack(dst, rgb []byte) {
+	n := len(o)
+	var v [4]byte
+	i := 0
+	for ; i*n+n <= len(dst) && i*3+2 < len(rgb); i++ {
+		v[0], v[1], v[2], v[3] = rgb[i*3], rgb[i*3+1], rgb[i*3+2], 0
+		if n == 4 {
+			w := min(v[0], v[1], v[2])
+			v[0], v[1], v[2], v[3] = v[0]-w, v[1]-w, v[2]-w, w
+		}
+		for k, ch := range o {
+			dst[i*n+k] = v[ch]
+		}
+	}
+	clear(dst[i*n:])
+}
+
+// PackRGBW reorders R, G, B, W quadruplets (as written by ToRGBW) into dst
+// in wire order; a three-slot order drops W. Like Pack, pixels that do not
+// fit are dropped and dst bytes past rgbw are zeroed.
+func (o ChannelOrder) PackRGBW(dst, rgbw []byte) {
+	n := len(o)
+	i := 0
+	for ; i*n+n <= len(dst) && i*4+3 < len(rgbw); i++ {
+		for k, ch := range o {
+			dst[i*n+k] = rgbw[i*4+int(ch)]
+		}
+	}
+	clear(dst[i*n:])
+}
+
+// Unpack reads wire-order pixels from src back into 8-bit RGB triplets in
+// dst, the inverse of Pack: W adds to R, G and B, saturating. Pixels that
+// do not fit dst are dropped; dst bytes past src are zeroed.
+func (o ChannelOrder) Unpack(dst, src []byte) {
+	n := len(o)
+	var v [4]byte
+	i := 0
+	for ; i*3+2 < len(dst) && i*n+n <= len(src); i++ {
+		for k, ch := range o {
+			v[ch] = src[i*n+k]
+		}
+		for c := 0; c < 3; c++ {
+			dst[i*3+c] = byte(min(255, int(v[c])+int(v[3])))
+		}
+	}
+	clear(dst[i*3:])
+}
+
+// FromRGB converts 8-bit RGB triplets back to linear colors, for byte
+// sources such as the test patterns.
+func FromRGB(dst []render.Color, rgb []byte) {
+	for i := range dst {
+		if i*3+2 >= len(rgb) {
+			return
+		}
+		dst[i] = render.Color{R: float32(rgb[i*3]) / 255, G: float32(rgb[i*3+1]) / 255, B: float32(rgb[i*3+2]) / 255}
+	}
+}
+
+// Adapter is the render.Driver for a ByteDriver: it scales frames by the
+// output gain, splits off white for RGBW devices (see SetWhite), quantizes
+// them (optionally with temporal dithering) and packs them in the device's
+// channel order. Frames longer than Caps().Pixels are cut; shorter ones are
+// padded with black.
+type Adapter struct {
+	dev   ByteDriver
+	caps  render.Caps
+	order ChannelOrder
+
+	mu     sync.Mutex
+	gain   float64
+	dither *Dither
+	white  render.White
+	rgb    []byte // quantized RGB (RGBW for RGBW devices) scratch
+	px     []byte // wire-order scratch
+}
+
+// NewAdapter wraps dev; it fails if dev reports an unknown channel order.
+func NewAdapter(dev ByteDriver) (*Adapter, error) {
+	caps := dev.Caps()
+	order, err := ParseOrder(caps.Order)
+	if err != nil {
+		return nil, err
+	}
+	if caps.Layout == "" {
+		caps.Layout = order.Layout()
+	}
+	if caps.Layout != order.Layout() {
+		return nil, fmt.Errorf("color order %q does not match layout %s", caps.Order, caps.Layout)
+	}
+	return &Adapter{dev: dev, caps: caps, order: order, gain: 1}, nil
+}
+
+// Device returns the wrapped ByteDriver.
+func (a *Adapter) Device() ByteDriver { return a.dev }
+
+func (a *Adapter) Caps() render.Caps { return a.caps }
+func (a *Adapter) Open() error       { return a.dev.Open() }
+func (a *Adapter) Close() error      { return a.dev.Close() }
+
+// SetGain sets the output brightness applied at quantization.
+func (a *Adapter) SetGain(g float64) {
+	a.mu.Lock()
+	a.gain = g
+	a.mu.Unlock()
+}
+
+// SetDither sets the temporal quantizer; nil rounds plainly.
+func (a *Adapter) SetDither(d *Dither) {
+	a.mu.Lock()
+	a.dither = d
+	a.mu.Unlock()
+}
+
+// SetWhite sets how RGBW devices split colors between RGB and W; RGB
+// devices ignore it.
+func (a *Adapter) SetWhite(w render.White) {
+	a.mu.Lock()
+	a.white = w
+	a.mu.Unlock()
+}
+
+func (a *Adapter) Write(frame []render.Color) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	n := len(frame)
+	if a.caps.Pixels > 0 && n > a.caps.Pixels {
+		frame, n = frame[:a.caps.Pixels], a.caps.Pixels
+	}
+	a.size(n)
+	if a.caps.Layout == render.LayoutRGBW {
+		a.dither.ToRGBW(a.rgb, frame, a.gain, a.white)
+		a.order.PackRGBW(a.px, a.rgb)
+	} else {
+		a.dither.ToRGB(a.rgb, frame, a.gain)
+		a.order.Pack(a.px, a.rgb)
+	}
+	return a.dev.WriteBytes(a.px)
+}
+
+// Blank sends an all-off frame and drops the dither residue.
+func (a *Adapter) Blank() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.size(0)
+	clear(a.px)
+	if a.dither != nil {
+		a.dither.Reset()
+	}
+	return a.dev.WriteBytes(a.px)
+}
+
+// size fits the scratch buffers to n source pixels. Caller holds a.mu.
+func (a *Adapter) size(n int) {
+	pixels := n
+	if a.caps.Pixels > 0 {
+		pixels = a.caps.Pixels
+	}
+	if w := n * a.caps.Layout.Channels(); len(a.rgb) != w {
+		a.rgb = make([]byte, w)
+	}
+	if w := pixels * len(a.order); len(a.px) != w {
+		a.px = make([]byte, w)
+	}
+}
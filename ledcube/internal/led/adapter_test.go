@@ -0,0 +1,130 @@
+package led
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+// recorder is a ByteDriver that keeps the last frame.
+type recorder struct {
+	caps   render.Caps
+	last   []byte
+	opened bool
+}
+
+func (r *recorder) Caps() render.Caps { return r.caps }
+func (r *recorder) Open() error       { r.opened = true; return nil }
+func (r *recorder) Close() error      { r.opened = false; return nil }
+func (r *recorder) WriteBytes(px []byte) error {
+	r.last = append(r.last[:0], px...)
+	return nil
+}
+
+func TestParseOrder(t *testing.T) {
+	for _, bad := range []string{"", "RG", "RGGB", "RGX", "RGBWW", "RGWW"} {
+		if _, err := ParseOrder(bad); err == nil {
+			t.Errorf("ParseOrder(%q) accepted", bad)
+		}
+	}
+	o, err := ParseOrder("grbw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Layout() != render.LayoutRGBW || len(o) != 4 || o[0] != 1 || o[3] != 3 {
+		t.Fatalf("grbw = %v (%s)", o, o.Layout())
+	}
+}
+
+func TestAdapterPacksInWireOrder(t *testing.T) {
+	dev := &recorder{caps: render.Caps{Pixels: 3, Order: "GRB"}}
+	a, err := NewAdapter(dev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Caps().Layout != render.LayoutRGB {
+		t.Fatalf("layout %q", a.Caps().Layout)
+	}
+	if err := a.Open(); err != nil || !dev.opened {
+		t.Fatalf("open: %v", err)
+	}
+	a.SetGain(0.5)
+	// two pixels for a three-pixel strip: the tail is padded black
+	if err := a.Write([]render.Color{{R: 1, G: 0, B: 0}, {R: 0, G: 1, B: 0.5}}); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0, 128, 0, 128, 0, 64, 0, 0, 0}
+	if !bytes.Equal(dev.last, want) {
+		t.Fatalf("wire = %v, want %v", dev.last, want)
+	}
+	// too many pixels are cut
+	_ = a.Write(make([]render.Color, 5))
+	if len(dev.last) != 9 {
+		t.Fatalf("wire length %d, want 9", len(dev.last))
+	}
+	if err := a.Blank(); err != nil || !bytes.Equal(dev.last, make([]byte, 9)) {
+		t.Fatalf("blank = %v (%v)", dev.last, err)
+	}
+}
+
+func TestAdapterExtractsWhite(t *testing.T) {
+	dev := &recorder{caps: render.Caps{Order: "GRBW"}}
+	a, err := NewAdapter(dev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = a.Write([]render.Color{{R: 1, G: 1, B: 1}, {R: 1, G: 0.6, B: 0.2}})
+	want := []byte{0, 0, 0, 255, 102, 204, 0, 51}
+	if !bytes.Equal(dev.last, want) {
+		t.Fatalf("wire = %v, want %v", dev.last, want)
+	}
+	a.SetWhite(render.White{Mode: render.WhiteOff})
+	_ = a.Write([]render.Color{{R: 1, G: 1, B: 1}})
+	if want := []byte{255, 255, 255, 0}; !bytes.Equal(dev.last[:4], want) {
+		t.Fatalf("wire with W off = %v, want %v", dev.last[:4], want)
+	}
+	// the split happens before quantization: W dithers like R, G and B
+	a.SetWhite(render.White{})
+	a.SetDither(NewDither(0))
+	sum := 0
+	for k := 0; k < 8; k++ {
+		_ = a.Write([]render.Color{{R: 0.502 / 255, G: 0.502 / 255, B: 0.502 / 255}})
+		sum += int(dev.last[3])
+	}
+	if sum != 4 {
+		t.Fatalf("W over 8 frames = %d, want 4", sum)
+	}
+	if _, err := NewAdapter(&recorder{caps: render.Caps{Order: "GRB", Layout: render.LayoutRGBW}}); err == nil {
+		t.Fatal("layout/order mismatch accepted")
+	}
+}
+
+func TestFromRGBRoundTrip(t *testing.T) {
+	rgb := []byte{0, 17, 255, 128, 64, 1}
+	frame := make([]render.Color, 2)
+	FromRGB(frame, rgb)
+	out := make([]byte, 6)
+	ToRGB(out, frame, 1)
+	if !bytes.Equal(out, rgb) {
+		t.Fatalf("round trip %v, want %v", out, rgb)
+	}
+}
+
+func TestUnpackInvertsPack(t *testing.T) {
+	rgb := []byte{10, 200, 30, 255, 255, 255, 0, 5, 9}
+	for _, order := range []string{"GRB", "BGR", "GRBW", "WRGB"} {
+		o, err := ParseOrder(order)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wire := make([]byte, 3*len(o))
+		o.Pack(wire, rgb)
+		out := make([]byte, len(rgb)+3)
+		out[len(rgb)] = 1
+		o.Unpack(out, wire)
+		if !bytes.Equal(out[:len(rgb)], rgb) || out[len(rgb)] != 0 {
+			t.Fatalf("%s: unpacked %v, want %v and a zeroed tail", order, out, rgb)
+		}
+	}
+}
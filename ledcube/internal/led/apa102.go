@@ -0,0 +1,188 @@
+package led
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+// APA102 drives APA102 or SK9822 clocked LEDs over SPI. Each pixel carries
+// a 5-bit global brightness next to its three 8-bit channels; the driver
+// picks the lowest global level that still fits the pixel's brightest
+// channel and spends the full 8 bits on what is left, so dim colors keep
+// about 13 bits of resolution instead of a few 8-bit steps. It takes linear
+// frames directly (no Adapter) and applies the output gain itself.
+//
+// Framing: a 32-bit zero start frame, 0xE0|level followed by the channels
+// per pixel, then an end frame of at least count/2 clock edges: 0xFF bytes
+// for APA102; for SK9822 a 32-bit zero reset frame plus zero bytes (it
+// latches on the extra clocks rather than on the next start frame).
+type APA102 struct {
+	mu      sync.Mutex
+	bus     *spidev
+	count   int
+	order   ChannelOrder
+	name    string // wire order as given
+	sk9822  bool
+	speedHz int
+	gain    float64
+	buf     []byte // start + pixels + end, preallocated
+}
+
+// APA102 variants accepted by NewAPA102.
+const (
+	VariantAPA102 = "apa102"
+	VariantSK9822 = "sk9822"
+)
+
+// NewAPA102 prepares count LEDs on spiDev; Open opens it. colorOrder is the
+// channel order after the brightness byte ("" = "BGR", the usual one);
+// speedHz ≤ 0 means 8 MHz.
+func NewAPA102(spiDev string, count int, colorOrder string, speedHz int, variant string) (*APA102, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("invalid LED count: %d", count)
+	}
+	if colorOrder == "" {
+		colorOrder = "BGR"
+	}
+	o, err := ParseOrder(colorOrder)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) != 3 {
+		return nil, fmt.Errorf("apa102: color order %q: three channels only", colorOrder)
+	}
+	variant = strings.ToLower(variant)
+	switch variant {
+	case "", VariantAPA102, VariantSK9822:
+	default:
+		return nil, fmt.Errorf("unknown clocked LED variant %q (want apa102 or sk9822)", variant)
+	}
+	if speedHz <= 0 {
+		speedHz = 8000000
+	}
+	a := &APA102{
+		bus:     newSpidev(spiDev, speedHz),
+		count:   count,
+		order:   o,
+		name:    strings.ToUpper(colorOrder),
+		sk9822:  variant == VariantSK9822,
+		speedHz: speedHz,
+		gain:    1,
+	}
+	end := (count + 15) / 16 // count/2 bits
+	if a.sk9822 {
+		end += 4
+	} else if end < 4 {
+		end = 4
+	}
+	a.buf = make([]byte, 4+4*count+end)
+	if !a.sk9822 {
+		for i := 4 + 4*count; i < len(a.buf); i++ {
+			a.buf[i] = 0xff
+		}
+	}
+	return a, nil
+}
+
+// Caps reports RGB with 13 bits per channel (5-bit level × 8-bit value).
+func (a *APA102) Caps() render.Caps {
+	return render.Caps{Pixels: a.count, Layout: render.LayoutRGB, BitDepth: 13, MaxFPS: float64(a.speedHz) / float64(len(a.buf)*8), Order: a.name}
+}
+
+func (a *APA102) Open() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.bus.open(4, 0)
+}
+
+func (a *APA102) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.bus.close()
+}
+
+// SetGain sets the output brightness, applied before the level split.
+func (a *APA102) SetGain(g float64) {
+	a.mu.Lock()
+	a.gain = g
+	a.mu.Unlock()
+}
+
+// Write encodes frame (cut or padded with black to the LED count) and sends it.
+func (a *APA102) Write(frame []render.Color) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.encode(frame)
+	return a.send()
+}
+
+// Blank sends every LED at level 0, black.
+func (a *APA102) Blank() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.encode(nil)
+	return a.send()
+}
+
+func (a *APA102) send() error {
+	if !a.bus.isOpen() {
+		return fmt.Errorf("apa102 closed")
+	}
+	if err := a.bus.send(a.buf, 0); err != nil {
+		return fmt.Errorf("apa102 write: %w", err)
+	}
+	return nil
+}
+
+// encode fills the pixel part of buf. Caller holds a.mu.
+func (a *APA102) encode(frame []render.Color) {
+	k := float32(a.gain)
+	for i := 0; i < a.count; i++ {
+		var c render.Color
+		if i < len(frame) {
+			c = render.Color{R: frame[i].R * k, G: frame[i].G * k, B: frame[i].B * k}
+		}
+		level, rgb := splitLevel(c)
+		px := a.buf[4+4*i : 8+4*i]
+		px[0] = 0xe0 | level
+		for s, ch := range a.order {
+			px[1+s] = rgb[ch]
+		}
+	}
+}
+
+// splitLevel picks the lowest 5-bit level that holds c's brightest channel
+// and scales the channels to fill 8 bits at that level.
+func splitLevel(c render.Color) (level byte, rgb [3]byte) {
+	r, g, b := clamp01f(c.R), clamp01f(c.G), clamp01f(c.B)
+	m := r
+	if g > m {
+		m = g
+	}
+	if b > m {
+		m = b
+	}
+	if m <= 0 {
+		return 0, rgb
+	}
+	lv := float32(math.Ceil(float64(m) * 31))
+	if lv < 1 {
+		lv = 1
+	}
+	s := 31 / lv
+	return byte(lv), [3]byte{quantize(r * s), quantize(g * s), quantize(b * s)}
+}
+
+func clamp01f(x float32) float32 {
+	if x <= 0 || x != x {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	return x
+}
@@ -0,0 +1,73 @@
+package led
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+func TestSplitLevelKeepsLowEndPrecision(t *testing.T) {
+	for _, v := range []float32{0.0005, 0.002, 0.01, 0.1, 0.5, 1} {
+		level, rgb := splitLevel(render.Color{R: v, G: v / 2})
+		if level < 1 || level > 31 {
+			t.Fatalf("%v: level %d", v, level)
+		}
+		got := float64(level) / 31 * float64(rgb[0]) / 255
+		// the error is within half a step at the chosen level, far finer
+		// than an 8-bit step (1/255) at the low end
+		if step := float64(level) / 31 / 255; math.Abs(got-float64(v)) > step/2+1e-7 {
+			t.Fatalf("%v: level %d value %d decodes to %v", v, level, rgb[0], got)
+		}
+		if level > 1 && rgb[0] < 128 {
+			t.Fatalf("%v: brightest channel %d does not use the top bit", v, rgb[0])
+		}
+	}
+	if level, rgb := splitLevel(render.Color{}); level != 0 || rgb != [3]byte{} {
+		t.Fatalf("black = %d %v", level, rgb)
+	}
+}
+
+func TestAPA102Framing(t *testing.T) {
+	a, err := NewAPA102("/dev/null", 20, "", 0, VariantAPA102)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.SetGain(0.5)
+	a.encode([]render.Color{{R: 1, G: 0, B: 0}, {R: 0, G: 0, B: 1}})
+	// start frame, two lit pixels in BGR, black padding, ceil(20/16)=2 -> 4 end bytes
+	if !bytes.Equal(a.buf[:4], []byte{0, 0, 0, 0}) {
+		t.Fatalf("start frame % x", a.buf[:4])
+	}
+	if want := []byte{0xe0 | 16, 0, 0, 247}; !bytes.Equal(a.buf[4:8], want) {
+		t.Fatalf("pixel 0 = % x, want % x", a.buf[4:8], want)
+	}
+	if want := []byte{0xe0 | 16, 247, 0, 0}; !bytes.Equal(a.buf[8:12], want) {
+		t.Fatalf("pixel 1 = % x, want % x", a.buf[8:12], want)
+	}
+	if want := []byte{0xe0, 0, 0, 0}; !bytes.Equal(a.buf[12:16], want) {
+		t.Fatalf("padding pixel = % x, want % x", a.buf[12:16], want)
+	}
+	if end := a.buf[4+4*20:]; len(end) != 4 || !bytes.Equal(end, bytes.Repeat([]byte{0xff}, 4)) {
+		t.Fatalf("end frame % x", end)
+	}
+	if c := a.Caps(); c.Order != "BGR" || c.BitDepth != 13 || c.Pixels != 20 {
+		t.Fatalf("caps %+v", c)
+	}
+
+	sk, err := NewAPA102("/dev/null", 100, "rgb", 0, VariantSK9822)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 32-bit reset frame plus ceil(100/16)=7 bytes, all zero
+	if end := sk.buf[4+4*100:]; len(end) != 11 || !bytes.Equal(end, make([]byte, 11)) {
+		t.Fatalf("sk9822 end frame % x", end)
+	}
+	if _, err := NewAPA102("/dev/null", 1, "", 0, "ws2801"); err == nil {
+		t.Fatal("unknown variant accepted")
+	}
+	if err := sk.Write(nil); err == nil {
+		t.Fatal("write before Open accepted")
+	}
+}
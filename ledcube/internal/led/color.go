@@ -0,0 +1,63 @@
+package led
+
+import "github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+
+// ToRGB quantizes a linear [0,1] frame into 8-bit RGB triplets, scaled by
+// brightness. dst must hold at least 3*len(src) bytes; extra bytes are untouched.
+func ToRGB(dst []byte, src []render.Color, brightness float64) {
+	b := float32(brightness)
+	for i := range src {
+		if i*3+2 >= len(dst) {
+			return
+		}
+		dst[i*3+0] = quantize(src[i].R * b)
+		dst[i*3+1] = quantize(src[i].G * b)
+		dst[i*3+2] = quantize(src[i].B * b)
+	}
+}
+
+// ToRGBW quantizes a linear frame for an RGBW strip: each color is scaled
+// by brightness, split by w and written as R, G, B, W. dst must hold at least
+// 4*len(src) bytes; extra bytes are untouched.
+func ToRGBW(dst []byte, src []render.Color, brightness float64, w render.White) {
+	b := float32(brightness)
+	for i := range src {
+		if i*4+3 >= len(dst) {
+			return
+		}
+		rgb, white := w.Split(render.Color{R: src[i].R * b, G: src[i].G * b, B: src[i].B * b})
+		dst[i*4+0] = quantize(rgb.R)
+		dst[i*4+1] = quantize(rgb.G)
+		dst[i*4+2] = quantize(rgb.B)
+		dst[i*4+3] = quantize(white)
+	}
+}
+
+// ShowRGB quantizes what an RGBW strip with split w shows for src, for
+// previews of RGBW outputs; a nil w is plain ToRGB.
+func ShowRGB(dst []byte, src []render.Color, brightness float64, w *render.White) {
+	if w == nil {
+		ToRGB(dst, src, brightness)
+		return
+	}
+	b := float32(brightness)
+	for i := range src {
+		if i*3+2 >= len(dst) {
+			return
+		}
+		c := w.Emit(render.Color{R: src[i].R * b, G: src[i].G * b, B: src[i].B * b})
+		dst[i*3+0] = quantize(c.R)
+		dst[i*3+1] = quantize(c.G)
+		dst[i*3+2] = quantize(c.B)
+	}
+}
+
+func quantize(x float32) byte {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 255
+	}
+	return byte(x*255.0 + 0.5)
+}
@@ -0,0 +1,179 @@
+package led
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+// Segment is one data line of a Composite: the device and the LED index
+// ranges [from, to) chained on it, in wiring order.
+type Segment struct {
+	Name  string
+	Dev   ByteDriver
+	Spans [][2]int
+}
+
+func (s Segment) pixels() int {
+	n := 0
+	for _, sp := range s.Spans {
+		n += sp[1] - sp[0]
+	}
+	return n
+}
+
+// Composite is a ByteDriver that splits a frame across several devices (for
+// example one SPI bus per group of panels) and writes them concurrently.
+// All devices take the same channel order. Shorter lines start late by the
+// difference in transmit time (from their Caps().MaxFPS), so every line
+// latches at about the same moment; LEDs no segment covers are not sent.
+type Composite struct {
+	count int
+	caps  render.Caps
+	segs  []segment
+}
+
+type segment struct {
+	Segment
+	buf   []byte
+	delay time.Duration // start offset, see Composite
+}
+
+// NewComposite checks segs against a count-pixel frame: spans inside the
+// frame, no LED on two lines, each device sized to its spans and one channel
+// order for all.
+func NewComposite(count int, segs []Segment) (*Composite, error) {
+	if len(segs) == 0 {
+		return nil, errors.New("composite: no segments")
+	}
+	c := &Composite{count: count}
+	var all [][2]int
+	var frame []time.Duration
+	for i, s := range segs {
+		name := s.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+		}
+		if s.Dev == nil {
+			return nil, fmt.Errorf("composite: segment %s: no device", name)
+		}
+		dc := s.Dev.Caps()
+		for _, sp := range s.Spans {
+			if sp[0] < 0 || sp[1] > count || sp[0] >= sp[1] {
+				return nil, fmt.Errorf("composite: segment %s: range [%d, %d) outside [0, %d)", name, sp[0], sp[1], count)
+			}
+			all = append(all, sp)
+		}
+		if n := s.pixels(); dc.Pixels > 0 && dc.Pixels != n {
+			return nil, fmt.Errorf("composite: segment %s: device has %d pixels, ranges cover %d", name, dc.Pixels, n)
+		}
+		if i == 0 {
+			c.caps = render.Caps{Pixels: count, Layout: dc.Layout, BitDepth: dc.BitDepth, MaxFPS: dc.MaxFPS, Order: dc.Order}
+		} else if dc.Order != c.caps.Order {
+			return nil, fmt.Errorf("composite: segment %s: color order %s, want %s like the first", name, dc.Order, c.caps.Order)
+		}
+		if dc.MaxFPS > 0 && (c.caps.MaxFPS <= 0 || dc.MaxFPS < c.caps.MaxFPS) {
+			c.caps.MaxFPS = dc.MaxFPS
+		}
+		var d time.Duration
+		if dc.MaxFPS > 0 {
+			d = time.Duration(float64(time.Second) / dc.MaxFPS)
+		}
+		frame = append(frame, d)
+		c.segs = append(c.segs, segment{Segment: Segment{Name: name, Dev: s.Dev, Spans: append([][2]int(nil), s.Spans...)}})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i][0] < all[j][0] })
+	for i := 1; i < len(all); i++ {
+		if all[i][0] < all[i-1][1] {
+			return nil, fmt.Errorf("composite: LEDs %d-%d are on two segments", all[i][0], min(all[i][1], all[i-1][1])-1)
+		}
+	}
+	o, err := ParseOrder(c.caps.Order)
+	if err != nil {
+		return nil, err
+	}
+	longest := time.Duration(0)
+	for _, d := range frame {
+		if d > longest {
+			longest = d
+		}
+	}
+	for i := range c.segs {
+		c.segs[i].buf = make([]byte, c.segs[i].pixels()*len(o))
+		if frame[i] > 0 {
+			c.segs[i].delay = longest - frame[i]
+		}
+	}
+	return c, nil
+}
+
+func (c *Composite) Caps() render.Caps { return c.caps }
+
+// Segments lists the lines in the order given.
+func (c *Composite) Segments() []Segment {
+	out := make([]Segment, len(c.segs))
+	for i, s := range c.segs {
+		out[i] = s.Segment
+	}
+	return out
+}
+
+// Open opens every device; on failure the ones already open are closed.
+func (c *Composite) Open() error {
+	for i, s := range c.segs {
+		if err := s.Dev.Open(); err != nil {
+			for _, o := range c.segs[:i] {
+				_ = o.Dev.Close()
+			}
+			return fmt.Errorf("segment %s: %w", s.Name, err)
+		}
+	}
+	return nil
+}
+
+func (c *Composite) Close() error {
+	var errs []error
+	for _, s := range c.segs {
+		if err := s.Dev.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("segment %s: %w", s.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WriteBytes splits px (the whole frame in wire order) by segment and
+// writes all of them at once, returning when every line is done.
+func (c *Composite) WriteBytes(px []byte) error {
+	n := len(px) / max(1, c.count)
+	if n == 0 || len(px) != c.count*n {
+		return fmt.Errorf("composite: frame length %d does not match count %d", len(px), c.count)
+	}
+	for i := range c.segs {
+		s := &c.segs[i]
+		off := 0
+		for _, sp := range s.Spans {
+			off += copy(s.buf[off:], px[sp[0]*n:sp[1]*n])
+		}
+	}
+	errs := make([]error, len(c.segs))
+	var wg sync.WaitGroup
+	for i := range c.segs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s := &c.segs[i]
+			if s.delay > 0 {
+				time.Sleep(s.delay)
+			}
+			if err := s.Dev.WriteBytes(s.buf); err != nil {
+				errs[i] = fmt.Errorf("segment %s: %w", s.Name, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
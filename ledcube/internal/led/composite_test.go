@@ -0,0 +1,87 @@
+package led
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+// waitRecorder is a recorder whose WriteBytes waits for every line to
+// start, so it only returns if the Composite writes them concurrently.
+type waitRecorder struct {
+	recorder
+	started chan struct{}
+	peers   int
+}
+
+func (r *waitRecorder) WriteBytes(px []byte) error {
+	r.started <- struct{}{}
+	for i := 1; i < r.peers; i++ {
+		select {
+		case <-r.started:
+		case <-time.After(time.Second):
+			return nil // sequential: the peer never started
+		}
+	}
+	return r.recorder.WriteBytes(px)
+}
+
+func TestCompositeSplitsAndWritesConcurrently(t *testing.T) {
+	started := make(chan struct{}, 4)
+	a := &waitRecorder{recorder: recorder{caps: render.Caps{Pixels: 3, Order: "GRB", MaxFPS: 100}}, started: started, peers: 2}
+	b := &waitRecorder{recorder: recorder{caps: render.Caps{Pixels: 2, Order: "GRB", MaxFPS: 200}}, started: started, peers: 2}
+	c, err := NewComposite(6, []Segment{
+		{Name: "a", Dev: a, Spans: [][2]int{{0, 2}, {5, 6}}},
+		{Name: "b", Dev: b, Spans: [][2]int{{2, 4}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps := c.Caps(); caps.Pixels != 6 || caps.MaxFPS != 100 || caps.Order != "GRB" {
+		t.Fatalf("caps %+v", caps)
+	}
+	// the shorter line starts 5 ms late so both latch together
+	if c.segs[0].delay != 0 || c.segs[1].delay != 5*time.Millisecond {
+		t.Fatalf("start offsets %v, %v", c.segs[0].delay, c.segs[1].delay)
+	}
+	c.segs[1].delay = 0 // keep the rendezvous below tight
+
+	px := make([]byte, 18)
+	for i := range px {
+		px[i] = byte(i / 3) // pixel index in every channel
+	}
+	start := time.Now()
+	if err := c.WriteBytes(px); err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) > 500*time.Millisecond || a.last == nil || b.last == nil {
+		t.Fatal("lines were not written concurrently")
+	}
+	if want := []byte{0, 0, 0, 1, 1, 1, 5, 5, 5}; !bytes.Equal(a.last, want) {
+		t.Fatalf("line a got %v, want %v", a.last, want)
+	}
+	if want := []byte{2, 2, 2, 3, 3, 3}; !bytes.Equal(b.last, want) {
+		t.Fatalf("line b got %v, want %v", b.last, want)
+	}
+}
+
+func TestCompositeRejectsBadLayouts(t *testing.T) {
+	dev := func(n int, order string) ByteDriver { return &recorder{caps: render.Caps{Pixels: n, Order: order}} }
+	for _, tc := range []struct {
+		segs []Segment
+		want string
+	}{
+		{[]Segment{{Dev: dev(4, "GRB"), Spans: [][2]int{{0, 4}}}, {Dev: dev(2, "GRB"), Spans: [][2]int{{3, 5}}}}, "two segments"},
+		{[]Segment{{Dev: dev(2, "GRB"), Spans: [][2]int{{5, 7}}}}, "outside"},
+		{[]Segment{{Dev: dev(3, "GRB"), Spans: [][2]int{{0, 2}}}}, "device has 3 pixels"},
+		{[]Segment{{Dev: dev(2, "GRB"), Spans: [][2]int{{0, 2}}}, {Dev: dev(2, "RGB"), Spans: [][2]int{{2, 4}}}}, "color order"},
+		{nil, "no segments"},
+	} {
+		if _, err := NewComposite(6, tc.segs); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("got %v, want error containing %q", err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package led
+
+import "github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+
+// DefaultDitherThreshold is the level (in 8-bit code units) below which a
+// channel is not dithered. The lowest codes are steps of 100% and more, so
+// dithering between them reads as flicker, not as an in-between level;
+// they are rounded steadily instead.
+const DefaultDitherThreshold = 3
+
+// Dither is a temporal error-diffusion quantizer: each channel carries its
+// rounding residue into the next frame, so a value between two 8-bit codes
+// averages out over time instead of stepping. One Dither belongs to one
+// output; it is not safe for concurrent use.
+type Dither struct {
+	// Threshold in code units (0..255 scale). Channels whose target is below
+	// it are rounded plainly and their residue dropped.
+	Threshold float32
+
+	res []float32 // per LED channel, in code units, within ±0.5
+}
+
+// NewDither returns a ditherer with the given near-black threshold.
+func NewDither(threshold float64) *Dither {
+	return &Dither{Threshold: float32(threshold)}
+}
+
+// Reset drops the carried residue (e.g. after a cut to black).
+func (d *Dither) Reset() {
+	for i := range d.res {
+		d.res[i] = 0
+	}
+}
+
+// ToRGB is led.ToRGB with temporal dithering. A nil Dither falls back to
+// plain rounding, so callers can hold an optional *Dither.
+func (d *Dither) ToRGB(dst []byte, src []render.Color, brightness float64) {
+	if d == nil {
+		ToRGB(dst, src, brightness)
+		return
+	}
+	if len(d.res) != len(src)*3 {
+		d.res = make([]float32, len(src)*3)
+	}
+	b := float32(brightness)
+	for i := range src {
+		if i*3+2 >= len(dst) {
+			return
+		}
+		dst[i*3+0] = d.quantize(i*3+0, src[i].R*b)
+		dst[i*3+1] = d.quantize(i*3+1, src[i].G*b)
+		dst[i*3+2] = d.quantize(i*3+2, src[i].B*b)
+	}
+}
+
+// ToRGBW is ToRGBW with temporal dithering of all four channels (the split
+// happens before quantization, so W dithers like the others).
+func (d *Dither) ToRGBW(dst []byte, src []render.Color, brightness float64, w render.White) {
+	if d == nil {
+		ToRGBW(dst, src, brightness, w)
+		return
+	}
+	if len(d.res) != len(src)*4 {
+		d.res = make([]float32, len(src)*4)
+	}
+	b := float32(brightness)
+	for i := range src {
+		if i*4+3 >= len(dst) {
+			return
+		}
+		rgb, white := w.Split(render.Color{R: src[i].R * b, G: src[i].G * b, B: src[i].B * b})
+		dst[i*4+0] = d.quantize(i*4+0, rgb.R)
+		dst[i*4+1] = d.quantize(i*4+1, rgb.G)
+		dst[i*4+2] = d.quantize(i*4+2, rgb.B)
+		dst[i*4+3] = d.quantize(i*4+3, white)
+	}
+}
+
+func (d *Dither) quantize(k int, x float32) byte {
+	v := x * 255
+	if v < d.Threshold {
+		d.res[k] = 0
+		return quantize(x)
+	}
+	v += d.res[k]
+	switch {
+	case v <= 0:
+		d.res[k] = 0
+		return 0
+	case v >= 255:
+		d.res[k] = 0
+		return 255
+	}
+	q := float32(int(v + 0.5))
+	d.res[k] = v - q
+	return byte(q)
+}
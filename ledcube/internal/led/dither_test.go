@@ -0,0 +1,61 @@
+package led
+
+import (
+	"testing"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+func TestDitherAveragesBetweenCodes(t *testing.T) {
+	d := NewDither(DefaultDitherThreshold)
+	src := []render.Color{{R: 10.3 / 255, G: 0.2 / 255, B: 1}}
+	dst := make([]byte, 3)
+	var sumR, sumG int
+	const frames = 100
+	for f := 0; f < frames; f++ {
+		d.ToRGB(dst, src, 1)
+		if dst[0] != 10 && dst[0] != 11 {
+			t.Fatalf("frame %d: R=%d, want 10 or 11", f, dst[0])
+		}
+		if dst[2] != 255 {
+			t.Fatalf("frame %d: B=%d, want 255", f, dst[2])
+		}
+		sumR += int(dst[0])
+		sumG += int(dst[1])
+	}
+	if avg := float64(sumR) / frames; avg < 10.25 || avg > 10.35 {
+		t.Fatalf("R averages %.3f, want ~10.3", avg)
+	}
+	// below the threshold: never lit, no flicker
+	if sumG != 0 {
+		t.Fatalf("near-black G flickered: sum %d", sumG)
+	}
+}
+
+func TestDitherHoldsNearBlackSteady(t *testing.T) {
+	d := NewDither(DefaultDitherThreshold)
+	src := []render.Color{{R: 0.8 / 255, G: 1.4 / 255, B: 2.6 / 255}}
+	dst := make([]byte, 3)
+	d.ToRGB(dst, src, 1)
+	first := string(dst)
+	for f := 1; f < 50; f++ {
+		d.ToRGB(dst, src, 1)
+		if string(dst) != first {
+			t.Fatalf("frame %d: %v, was %v; near-black alternates", f, dst, []byte(first))
+		}
+	}
+	if want := []byte{1, 1, 3}; first != string(want) {
+		t.Fatalf("near-black = %v, want %v", []byte(first), want)
+	}
+}
+
+func TestDitherNilIsPlainRounding(t *testing.T) {
+	var d *Dither
+	src := []render.Color{{R: 0.5, G: 0, B: 1}}
+	a, b := make([]byte, 3), make([]byte, 3)
+	d.ToRGB(a, src, 0.5)
+	ToRGB(b, src, 0.5)
+	if string(a) != string(b) {
+		t.Fatalf("nil dither %v != ToRGB %v", a, b)
+	}
+}
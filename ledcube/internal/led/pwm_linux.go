@@ -1,4 +1,4 @@
-//go:build linux
+//go:build linux && cgo
 
 package led
 
@@ -11,67 +11,89 @@ package led
 import "C"
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"unsafe"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
 )
 
 type PWM struct{
 	gpio int
 	count int
-	colorOrder string
-	brightness float64
+	order string
+	rgbw bool
 
 	mu sync.Mutex
 	dev *C.ws2811_t
 	buf unsafe.Pointer
 }
 
-func NewPWM(gpio int, count int, colorOrder string, brightness float64) (*PWM, error) {
-	p := &PWM{gpio: gpio, count: count, colorOrder: colorOrder, brightness: brightness}
+// NewPWM prepares an rpi_ws281x output on gpio; Open initializes the DMA
+// channel. colorOrder is the wire order the Adapter packs into ("GRB",
+// "GRBW", ...); brightness is applied by the Adapter, not the library.
+func NewPWM(gpio int, count int, colorOrder string) (*PWM, error) {
+	if count <= 0 { return nil, fmt.Errorf("invalid LED count: %d", count) }
+	if colorOrder == "" { colorOrder = "GRB" }
+	o, err := ParseOrder(colorOrder)
+	if err != nil { return nil, err }
+	return &PWM{gpio: gpio, count: count, order: strings.ToUpper(colorOrder), rgbw: len(o) == 4}, nil
+}
+
+func (p *PWM) Caps() render.Caps {
+	layout := render.LayoutRGB
+	if p.rgbw { layout = render.LayoutRGBW }
+	// 30 µs per 24-bit pixel (40 µs for 32-bit) at 800 kHz, plus a 300 µs reset
+	us := 30.0
+	if p.rgbw { us = 40 }
+	return render.Caps{Pixels: p.count, Layout: layout, BitDepth: 8, MaxFPS: 1e6 / (us*float64(p.count) + 300), Order: p.order}
+}
+
+func (p *PWM) Open() error {
+	p.mu.Lock(); defer p.mu.Unlock()
+	if p.dev != nil { return nil }
 
 	// Allocate ws2811_t
-	p.dev = (*C.ws2811_t)(C.calloc(1, C.size_t(unsafe.Sizeof(*p.dev))))
-	if p.dev == nil { return nil, fmt.Errorf("calloc ws2811_t failed") }
+	dev := (*C.ws2811_t)(C.calloc(1, C.size_t(unsafe.Sizeof(*p.dev))))
+	if dev == nil { return fmt.Errorf("calloc ws2811_t failed") }
 
 	// Initialize structure with common defaults
-	p.dev.freq = 800000
-	p.dev.dmanum = 10
+	dev.freq = 800000
+	dev.dmanum = 10
 	// Channel 0
-	ch := &p.dev.channel[0]
-	ch.gpionum = C.int(gpio)
-	ch.count = C.int(count)
+	ch := &dev.channel[0]
+	ch.gpionum = C.int(p.gpio)
+	ch.count = C.int(p.count)
 	ch.invert = 0
-	// Map color order to strip type (basic set)
-	switch colorOrder {
-	case "RGB": ch.strip_type = C.WS2811_STRIP_RGB
-	case "BRG": ch.strip_type = C.WS2811_STRIP_BRG
-	case "GRB": fallthrough
-	default:    ch.strip_type = C.WS2811_STRIP_GRB
-	}
-	ch.brightness = C.uint8_t(int(brightness*255) & 0xFF)
+	// The Adapter already packed the bytes in wire order; the library
+	// sends the packed word MSB-first for these strip types.
+	ch.strip_type = C.WS2811_STRIP_RGB
+	if p.rgbw { ch.strip_type = C.SK6812_STRIP_RGBW }
+	ch.brightness = 255
 
 	// Init device
-	if st := C.ws2811_init(p.dev); st != C.WS2811_SUCCESS {
-		C.free(unsafe.Pointer(p.dev))
-		return nil, fmt.Errorf("ws2811_init failed: %d", int(st))
+	if st := C.ws2811_init(dev); st != C.WS2811_SUCCESS {
+		C.free(unsafe.Pointer(dev))
+		return fmt.Errorf("ws2811_init failed: %d", int(st))
 	}
 
 	// Grab pointer to LED buffer
+	p.dev = dev
 	p.buf = unsafe.Pointer(ch.leds)
-	return p, nil
+	return nil
 }
 
-func (p *PWM) Write(rgb []byte) error {
+func (p *PWM) WriteBytes(px []byte) error {
 	p.mu.Lock(); defer p.mu.Unlock()
 	if p.dev == nil { return fmt.Errorf("pwm not initialized") }
-	// Map RGB to 32-bit GRB packed format expected by ws2811 (0x00RRGGBB order varies by strip_type)
-	// We'll pack as 0x00RRGGBB and rely on strip_type for channel ordering.
+	n := 3
+	if p.rgbw { n = 4 }
+	// Pack as 0xWWRRGGBB with the wire-order bytes in the R, G, B (and W)
+	// slots; strip_type RGB/RGBW keeps that order on the wire.
 	leds := (*[1 << 26]C.ws2811_led_t)(p.buf)[:p.count:p.count]
-	for i := 0; i < p.count && i*3+2 < len(rgb); i++ {
-		r := uint32(rgb[i*3+0])
-		g := uint32(rgb[i*3+1])
-		b := uint32(rgb[i*3+2])
-		val := (r << 16) | (g << 8) | b
+	for i := 0; i < p.count && i*n+n-1 < len(px); i++ {
+		val := uint32(px[i*n+0])<<16 | uint32(px[i*n+1])<<8 | uint32(px[i*n+2])
+		if p.rgbw { val |= uint32(px[i*n+3]) << 24 }
 		leds[i] = C.ws2811_led_t(val)
 	}
 	if st := C.ws2811_render(p.dev); st != C.WS2811_SUCCESS {
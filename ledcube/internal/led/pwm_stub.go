@@ -1,13 +1,19 @@
-//go:build !linux
+//go:build !linux || !cgo
 
 package led
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
 
 type PWM struct{}
 
-func NewPWM(gpio int, count int, colorOrder string, brightness float64) (*PWM, error) {
-	return nil, fmt.Errorf("pwm driver not supported on this platform")
+func NewPWM(gpio int, count int, colorOrder string) (*PWM, error) {
+	return nil, fmt.Errorf("pwm driver not supported in this build (needs linux and cgo)")
 }
-func (p *PWM) Write(rgb []byte) error { return nil }
-func (p *PWM) Close() error { return nil }
+func (p *PWM) Caps() render.Caps          { return render.Caps{} }
+func (p *PWM) Open() error                { return fmt.Errorf("pwm driver not supported in this build") }
+func (p *PWM) WriteBytes(px []byte) error { return nil }
+func (p *PWM) Close() error               { return nil }
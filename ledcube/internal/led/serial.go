@@ -0,0 +1,140 @@
+package led
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+// Serial framings understood by USB-serial LED controllers.
+const (
+	// ProtoAdalight is "Ada", the LED count minus one (big endian), a
+	// checksum (hi ^ lo ^ 0x55) and 3 bytes per LED.
+	ProtoAdalight = "adalight"
+	// ProtoTPM2 is 0xC9 0xDA, the payload size (big endian), the channel
+	// bytes and 0x36.
+	ProtoTPM2 = "tpm2"
+)
+
+// Serial writes frames to a microcontroller on a tty (e.g. /dev/ttyUSB0,
+// /dev/cu.usbserial-*) in one of the serial framings; the controller
+// drives the strip.
+type Serial struct {
+	mu    sync.Mutex
+	path  string
+	baud  int
+	proto string
+	count int
+	order string
+	chans int
+	port  io.WriteCloser
+
+	// buf is the preallocated packet: header, px at hdr, trailer.
+	buf []byte
+	hdr int
+}
+
+// NewSerial prepares a serial output on path at baud (0 = 1_000_000) with
+// proto framing ("" = adalight); Open opens and configures the tty (8N1,
+// raw). colorOrder is the order the controller expects ("" = RGB); TPM2
+// also carries four-channel orders, Adalight is RGB only.
+func NewSerial(path string, count int, colorOrder string, baud int, proto string) (*Serial, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("invalid LED count: %d", count)
+	}
+	if colorOrder == "" {
+		colorOrder = "RGB"
+	}
+	o, err := ParseOrder(colorOrder)
+	if err != nil {
+		return nil, err
+	}
+	if baud <= 0 {
+		baud = 1000000
+	}
+	if proto == "" {
+		proto = ProtoAdalight
+	}
+	s := &Serial{path: path, baud: baud, proto: proto, count: count, order: strings.ToUpper(colorOrder), chans: len(o)}
+	n := count * len(o)
+	switch proto {
+	case ProtoAdalight:
+		if len(o) != 3 {
+			return nil, fmt.Errorf("serial: color order %q: adalight takes three channels", colorOrder)
+		}
+		if count > 1<<16 {
+			return nil, fmt.Errorf("serial: adalight takes at most %d LEDs, got %d", 1<<16, count)
+		}
+		hi, lo := byte((count-1)>>8), byte(count-1)
+		s.hdr = 6
+		s.buf = make([]byte, s.hdr+n)
+		copy(s.buf, []byte{'A', 'd', 'a', hi, lo, hi ^ lo ^ 0x55})
+	case ProtoTPM2:
+		if n > 0xffff {
+			return nil, fmt.Errorf("serial: tpm2 takes at most 65535 channel bytes, got %d", n)
+		}
+		s.hdr = 4
+		s.buf = make([]byte, s.hdr+n+1)
+		copy(s.buf, []byte{0xc9, 0xda, byte(n >> 8), byte(n)})
+		s.buf[len(s.buf)-1] = 0x36
+	default:
+		return nil, fmt.Errorf("serial: unknown protocol %q (adalight or tpm2)", proto)
+	}
+	return s, nil
+}
+
+// Caps reports 8-bit pixels in the configured order; MaxFPS is what the
+// baud rate allows for one packet (10 bit times per byte, 8N1).
+func (s *Serial) Caps() render.Caps {
+	layout := render.LayoutRGB
+	if s.chans == 4 {
+		layout = render.LayoutRGBW
+	}
+	return render.Caps{Pixels: s.count, Layout: layout, BitDepth: 8, MaxFPS: float64(s.baud) / 10 / float64(len(s.buf)), Order: s.order}
+}
+
+// Open opens the tty; it is a no-op while open.
+func (s *Serial) Open() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.port != nil {
+		return nil
+	}
+	p, err := openSerial(s.path, s.baud)
+	if err != nil {
+		return fmt.Errorf("serial %s: %w", s.path, err)
+	}
+	s.port = p
+	return nil
+}
+
+func (s *Serial) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.port == nil {
+		return nil
+	}
+	err := s.port.Close()
+	s.port = nil
+	return err
+}
+
+// WriteBytes sends px (count pixels in wire order) as one packet.
+func (s *Serial) WriteBytes(px []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.port == nil {
+		return fmt.Errorf("serial closed")
+	}
+	if len(px) != s.count*s.chans {
+		return fmt.Errorf("frame length %d does not match count %d", len(px), s.count)
+	}
+	copy(s.buf[s.hdr:], px)
+	if _, err := s.port.Write(s.buf); err != nil {
+		return fmt.Errorf("serial write: %w", err)
+	}
+	return nil
+}
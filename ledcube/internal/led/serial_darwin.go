@@ -0,0 +1,24 @@
+package led
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+const (
+	ioctlGetTermios = syscall.TIOCGETA
+	crtscts         = 0x30000 // CRTSCTS (CCTS_OFLOW | CRTS_IFLOW)
+	// ioSSIOSpeed is IOSSIOSPEED (_IOW('T', 2, speed_t)): any baud rate
+	// the USB-serial chip supports, past the termios table.
+	ioSSIOSpeed = 0x80085402
+)
+
+// setSpeed applies t, then sets baud through IOSSIOSPEED.
+func setSpeed(fd uintptr, t *syscall.Termios, baud int) error {
+	t.Ispeed, t.Ospeed = syscall.B9600, syscall.B9600
+	if err := ioctl(fd, syscall.TIOCSETA, uintptr(unsafe.Pointer(t))); err != nil {
+		return err
+	}
+	speed := uint64(baud)
+	return ioctl(fd, ioSSIOSpeed, uintptr(unsafe.Pointer(&speed)))
+}
@@ -0,0 +1,33 @@
+package led
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	ioctlGetTermios = syscall.TCGETS
+	cbaud           = 0x100f     // CBAUD, the speed bits of c_cflag
+	crtscts         = 0x80000000 // CRTSCTS
+)
+
+// baudFlags are the termios speeds Linux takes without BOTHER.
+var baudFlags = map[int]uint32{
+	9600: syscall.B9600, 19200: syscall.B19200, 38400: syscall.B38400, 57600: syscall.B57600,
+	115200: syscall.B115200, 230400: syscall.B230400, 460800: syscall.B460800, 500000: syscall.B500000,
+	576000: syscall.B576000, 921600: syscall.B921600, 1000000: syscall.B1000000, 1152000: syscall.B1152000,
+	1500000: syscall.B1500000, 2000000: syscall.B2000000, 2500000: syscall.B2500000,
+	3000000: syscall.B3000000, 3500000: syscall.B3500000, 4000000: syscall.B4000000,
+}
+
+// setSpeed applies t at one of the standard baud rates.
+func setSpeed(fd uintptr, t *syscall.Termios, baud int) error {
+	b, ok := baudFlags[baud]
+	if !ok {
+		return fmt.Errorf("unsupported baud rate %d", baud)
+	}
+	t.Cflag = t.Cflag&^cbaud | b
+	t.Ispeed, t.Ospeed = b, b
+	return ioctl(fd, syscall.TCSETS, uintptr(unsafe.Pointer(t)))
+}
@@ -0,0 +1,120 @@
+package led
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+// openPty returns a pty master and the path of its slave, which stands in
+// for the USB-serial tty.
+func openPty(t *testing.T) (*os.File, string) {
+	t.Helper()
+	m, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("no pty: %v", err)
+	}
+	t.Cleanup(func() { _ = m.Close() })
+	var n uint32
+	if err := ioctl(m.Fd(), syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); err != nil {
+		t.Fatal(err)
+	}
+	var unlock int32
+	if err := ioctl(m.Fd(), syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); err != nil {
+		t.Fatal(err)
+	}
+	return m, fmt.Sprintf("/dev/pts/%d", n)
+}
+
+func readPacket(t *testing.T, m *os.File, n int) []byte {
+	t.Helper()
+	_ = m.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(m, buf); err != nil {
+		t.Fatalf("read %d bytes: %v", n, err)
+	}
+	return buf
+}
+
+func TestSerialAdalight(t *testing.T) {
+	m, tty := openPty(t)
+	s, err := NewSerial(tty, 3, "GRB", 115200, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewAdapter(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	// 10/255 is '\n': the tty must be raw, or it goes out as "\r\n"
+	if err := a.Write([]render.Color{{R: 1}, {G: 10.0 / 255}, {B: 0.5}}); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{'A', 'd', 'a', 0, 2, 2 ^ 0x55, 0, 255, 0, 10, 0, 0, 0, 0, 128}
+	if got := readPacket(t, m, len(want)); !bytes.Equal(got, want) {
+		t.Fatalf("packet % x, want % x", got, want)
+	}
+	// 10 bit times per byte (8N1) at 115200 for a 15-byte packet
+	if fps := s.Caps().MaxFPS; fps < 760 || fps > 770 {
+		t.Fatalf("MaxFPS = %v", fps)
+	}
+}
+
+func TestSerialTPM2(t *testing.T) {
+	m, tty := openPty(t)
+	s, err := NewSerial(tty, 2, "GRBW", 1000000, ProtoTPM2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Caps().Layout != render.LayoutRGBW {
+		t.Fatalf("layout %s", s.Caps().Layout)
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	for k := 0; k < 2; k++ {
+		if err := s.WriteBytes([]byte{1, 2, 3, 4, 5, 6, 7, byte(k)}); err != nil {
+			t.Fatal(err)
+		}
+		want := []byte{0xc9, 0xda, 0, 8, 1, 2, 3, 4, 5, 6, 7, byte(k), 0x36}
+		if got := readPacket(t, m, len(want)); !bytes.Equal(got, want) {
+			t.Fatalf("packet %d: % x, want % x", k, got, want)
+		}
+	}
+	if err := s.WriteBytes(make([]byte, 6)); err == nil {
+		t.Fatal("short frame accepted")
+	}
+}
+
+func TestSerialRejects(t *testing.T) {
+	if _, err := NewSerial("/dev/null", 2, "GRBW", 0, ProtoAdalight); err == nil {
+		t.Error("adalight took an RGBW order")
+	}
+	if _, err := NewSerial("/dev/null", 2, "", 0, "dmx"); err == nil {
+		t.Error("unknown protocol accepted")
+	}
+	_, tty := openPty(t)
+	s, err := NewSerial(tty, 2, "", 12345, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Open(); err == nil {
+		s.Close()
+		t.Error("non-standard baud rate accepted")
+	}
+	if err := s.WriteBytes(make([]byte, 6)); err == nil {
+		t.Error("write before Open accepted")
+	}
+}
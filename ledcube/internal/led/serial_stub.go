@@ -0,0 +1,12 @@
+//go:build !linux && !darwin
+
+package led
+
+import (
+	"fmt"
+	"io"
+)
+
+func openSerial(path string, baud int) (io.WriteCloser, error) {
+	return nil, fmt.Errorf("serial driver not supported on this platform")
+}
@@ -1,8 +1,29 @@
-
 package led
 
-type Sim struct{}
+import (
+	"strings"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+// Sim is a ByteDriver that discards frames, for running without hardware.
+type Sim struct {
+	count int
+	order string
+}
+
+// NewSim returns a sink for count pixels in colorOrder ("" = "RGB").
+func NewSim(count int, colorOrder string) *Sim {
+	if colorOrder == "" {
+		colorOrder = "RGB"
+	}
+	return &Sim{count: count, order: strings.ToUpper(colorOrder)}
+}
 
-func NewSim() *Sim { return &Sim{} }
-func (s *Sim) Write(rgb []byte) error { return nil }
-func (s *Sim) Close() error { return nil }
+func (s *Sim) Caps() render.Caps {
+	o, _ := ParseOrder(s.order)
+	return render.Caps{Pixels: s.count, Layout: o.Layout(), BitDepth: 8, Order: s.order}
+}
+func (s *Sim) Open() error                { return nil }
+func (s *Sim) WriteBytes(px []byte) error { return nil }
+func (s *Sim) Close() error               { return nil }
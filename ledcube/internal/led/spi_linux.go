@@ -4,76 +4,67 @@ package led
 
 import (
 	"fmt"
-	"os"
+	"strings"
 	"sync"
-	"syscall"
-	"unsafe"
-)
-
-/*
-Minimal spidev ioctl bindings (no external deps).
-If you prefer, swap this file to use periph.io/x/conn/spi for cleaner setup.
-*/
 
-const (
-	spiIOCWriteMode        = 0x40016b01
-	spiIOCWriteBitsPerWord = 0x40016b03
-	spiIOCWriteMaxSpeedHz  = 0x40046b04
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
 )
 
 type SPI struct {
-	mu       sync.Mutex
-	f        *os.File
-	count    int
-	colorOrd [3]byte
-	resetUs  int
+	mu      sync.Mutex
+	bus     *spidev
+	count   int
+	order   string
+	chans   int // 3, or 4 for RGBW (SK6812) orders
+	speedHz int
+	resetUs int
 	// Precomputed LUT: byte -> 24-bit encoded (3 bytes) using 0b100 (0) / 0b110 (1)
 	lut [256][3]byte
+	// enc is the preallocated wire frame, 3 bytes per channel.
+	enc []byte
 }
 
-// NewSPI opens spidev (e.g. "/dev/spidev0.0") and prepares an encoder for WS2812-over-SPI.
-// speedHz in the 2_400_000–3_200_000 range works well with this 3x expand scheme.
-// colorOrder like "GRB" or "RGB". resetUs is the latch (usually >= 280µs; 300–400 is safe).
+// NewSPI prepares a WS2812-over-SPI encoder for spiDev (e.g. "/dev/spidev0.0");
+// Open opens the device. With this 3x expand scheme speedHz between
+// 2_110_000 and 3_070_000 keeps every pulse inside the WS2812B datasheet
+// tolerances (2_400_000 is the default; many strips also take 3_200_000).
+// colorOrder like "GRB" or "RGB" is the wire order the Adapter packs into;
+// four-channel orders ("GRBW") drive SK6812 RGBW strips, whose shorter
+// pulses (T0H 0.3 µs, T1H 0.6 µs ±150 ns) need 2_670_000 to 4_440_000
+// (3_200_000 is the default for them).
+// resetUs is the latch (usually >= 280µs; 300–400 is safe), sent as a delay
+// after the frame's last transfer.
 func NewSPI(spiDev string, count int, colorOrder string, speedHz int, resetUs int) (*SPI, error) {
 	if count <= 0 {
 		return nil, fmt.Errorf("invalid LED count: %d", count)
 	}
+	if colorOrder == "" {
+		colorOrder = "GRB"
+	}
+	o, err := ParseOrder(colorOrder)
+	if err != nil {
+		return nil, err
+	}
 	if speedHz <= 0 {
 		speedHz = 2400000
+		if len(o) == 4 {
+			speedHz = 3200000
+		}
 	}
 	if resetUs <= 0 {
 		resetUs = 300
 	}
-	f, err := os.OpenFile(spiDev, os.O_RDWR, 0)
-	if err != nil {
-		return nil, fmt.Errorf("open spidev: %w", err)
-	}
-	// mode 0
-	mode := byte(0)
-	if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), spiIOCWriteMode, uintptr(unsafe.Pointer(&mode))); e != 0 {
-		_ = f.Close()
-		return nil, fmt.Errorf("SPI set mode: %v", e)
+	if resetUs > 0xffff {
+		resetUs = 0xffff
 	}
-	// 8 bits per word
-	bpw := byte(8)
-	if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), spiIOCWriteBitsPerWord, uintptr(unsafe.Pointer(&bpw))); e != 0 {
-		_ = f.Close()
-		return nil, fmt.Errorf("SPI set bits-per-word: %v", e)
-	}
-	// max speed
-	if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), spiIOCWriteMaxSpeedHz, uintptr(unsafe.Pointer(&speedHz))); e != 0 {
-		_ = f.Close()
-		return nil, fmt.Errorf("SPI set speed: %v", e)
-	}
-
 	s := &SPI{
-		f:        f,
-		count:    count,
-		resetUs:  resetUs,
-		colorOrd: [3]byte{byte('G'), byte('R'), byte('B')}, // default GRB
-	}
-	if len(colorOrder) == 3 {
-		s.colorOrd = [3]byte{colorOrder[0], colorOrder[1], colorOrder[2]}
+		bus:     newSpidev(spiDev, speedHz),
+		count:   count,
+		order:   strings.ToUpper(colorOrder),
+		chans:   len(o),
+		speedHz: speedHz,
+		resetUs: resetUs,
+		enc:     make([]byte, count*len(o)*3),
 	}
 
 	// Build LUT: for each input byte, expand each bit MSB->LSB to 3 SPI bits:
@@ -101,77 +92,52 @@ func NewSPI(spiDev string, count int, colorOrder string, speedHz int, resetUs in
 	return s, nil
 }
 
-func (s *SPI) Close() error {
+// Caps reports 8-bit RGB or RGBW in the configured order; MaxFPS is what
+// the bus speed allows for the encoded frame plus latch.
+func (s *SPI) Caps() render.Caps {
+	frameS := float64(len(s.enc)*8)/float64(s.speedHz) + float64(s.resetUs)/1e6
+	layout := render.LayoutRGB
+	if s.chans == 4 {
+		layout = render.LayoutRGBW
+	}
+	return render.Caps{Pixels: s.count, Layout: layout, BitDepth: 8, MaxFPS: 1 / frameS, Order: s.order}
+}
+
+// Open opens the device (see spidev) with transfers of whole pixels.
+func (s *SPI) Open() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if s.f != nil {
-		err := s.f.Close()
-		s.f = nil
-		return err
-	}
-	return nil
+	return s.bus.open(s.chans*3, s.resetUs)
 }
 
-func (s *SPI) encodePixel(r, g, b byte, dst []byte) {
-	order := s.colorOrd
-	var v [3]byte
-	for i := 0; i < 3; i++ {
-		switch order[i] {
-		case 'R':
-			v[i] = r
-		case 'G':
-			v[i] = g
-		case 'B':
-			v[i] = b
-		default:
-			v[i] = g // fallback
-		}
-	}
-	off := 0
-	for i := 0; i < 3; i++ {
-		dst[off+0] = s.lut[v[i]][0]
-		dst[off+1] = s.lut[v[i]][1]
-		dst[off+2] = s.lut[v[i]][2]
-		off += 3
-	}
+func (s *SPI) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bus.close()
 }
 
-// Write takes len(rgb)==3*count. We expand to 9 bytes/pixel (3 per color) plus reset tail.
-func (s *SPI) Write(rgb []byte) error {
+// WriteBytes takes 3 (RGBW: 4) bytes per LED in wire order. We expand each
+// to 3 bytes (9 or 12 per pixel) into the preallocated frame and send it in
+// bufsiz-sized transfers; the last one carries the reset delay (the line
+// idles low after the final encoded bit), so nothing is allocated and no
+// zero padding is clocked out.
+func (s *SPI) WriteBytes(px []byte) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.f == nil {
+	if !s.bus.isOpen() {
 		return fmt.Errorf("SPI closed")
 	}
-	if len(rgb) != s.count*3 {
-		return fmt.Errorf("rgb length %d does not match count %d", len(rgb), s.count)
+	if len(px) != s.count*s.chans {
+		return fmt.Errorf("frame length %d does not match count %d", len(px), s.count)
 	}
 
-	// Frame buffer: 9 bytes per pixel (24 encoded bits per byte => 3 bytes per color)
-	enc := make([]byte, s.count*9)
-
-	for i := 0; i < s.count; i++ {
-		r := rgb[i*3+0]
-		g := rgb[i*3+1]
-		b := rgb[i*3+2]
-		s.encodePixel(r, g, b, enc[i*9:i*9+9])
+	// 24 encoded bits per byte => 3 bytes per channel
+	for i, v := range px {
+		copy(s.enc[i*3:i*3+3], s.lut[v][:])
 	}
-
-	// Write encoded stream
-	if _, err := s.f.Write(enc); err != nil {
+	if err := s.bus.send(s.enc, uint16(s.resetUs)); err != nil {
 		return fmt.Errorf("spi write: %w", err)
 	}
-
-	// Reset (latch): drive low for resetUs. With SPI we approximate by sending zeros.
-	// At 2.4MHz, 1 byte ~ 3.33us. For 300us, send ~90 bytes. Round up to be safe.
-	resetBytes := (s.resetUs + 3) / 3 * 1 // rough; we’ll just send 128 zeros.
-	if resetBytes < 128 {
-		resetBytes = 128
-	}
-	zeros := make([]byte, resetBytes)
-	if _, err := s.f.Write(zeros); err != nil {
-		return fmt.Errorf("spi latch: %w", err)
-	}
 	return nil
 }
@@ -0,0 +1,219 @@
+//go:build linux
+
+package led
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/led/ws2812test"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+// fakeSPI opens an SPI on a plain file standing in for /dev/spidevX.Y,
+// with the spidev bufsiz parameter set to bufsiz ("" = missing).
+func fakeSPI(t *testing.T, count int, order string, speedHz int, bufsiz string) (*SPI, string) {
+	t.Helper()
+	dir := t.TempDir()
+	dev := filepath.Join(dir, "spidev0.0")
+	if err := os.WriteFile(dev, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSPI(dev, count, order, speedHz, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.bus.bufsizPath = filepath.Join(dir, "bufsiz")
+	if bufsiz != "" {
+		if err := os.WriteFile(s.bus.bufsizPath, []byte(bufsiz), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s, dev
+}
+
+func TestSPIChunksToBufsiz(t *testing.T) {
+	s, dev := fakeSPI(t, 300, "GRB", 2400000, "1000\n")
+	type xfer struct {
+		n     int
+		delay uint16
+	}
+	var got []xfer
+	write := s.bus.xfer
+	s.bus.xfer = func(tx []byte, delay uint16) error {
+		got = append(got, xfer{len(tx), delay})
+		return write(tx, delay)
+	}
+
+	px := make([]byte, 300*3)
+	for i := range px {
+		if i%2 == 0 {
+			px[i] = 0xff
+		}
+	}
+	if err := s.WriteBytes(px); err != nil {
+		t.Fatal(err)
+	}
+	// 2700 encoded bytes in whole-pixel chunks of 999; the latch is the
+	// delay after the last one, not zero bytes
+	want := []xfer{{999, 0}, {999, 0}, {702, 300}}
+	if len(got) != len(want) {
+		t.Fatalf("transfers %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("transfer %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+	wire, err := os.ReadFile(dev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 300 µs of latch at 2.4 MHz is 90 idle bytes
+	if len(wire) != 2790 || !bytes.Equal(wire[2700:], make([]byte, 90)) {
+		t.Fatalf("wrote %d bytes, want 2700 plus 90 zeros", len(wire))
+	}
+	one, zero := []byte{0xdb, 0x6d, 0xb6}, []byte{0x92, 0x49, 0x24}
+	if !bytes.Equal(wire[:3], one) || !bytes.Equal(wire[3:6], zero) {
+		t.Fatalf("encoding % x, want % x then % x", wire[:6], one, zero)
+	}
+}
+
+func TestSPIDefaultBufsiz(t *testing.T) {
+	s, _ := fakeSPI(t, 650, "GRB", 2400000, "")
+	if s.bus.chunk != 4095 {
+		t.Fatalf("chunk %d without a bufsiz parameter, want 4095", s.bus.chunk)
+	}
+	if err := s.WriteBytes(make([]byte, 10)); err == nil {
+		t.Fatal("short frame accepted")
+	}
+	if fps := s.Caps().MaxFPS; fps < 49 || fps > 51 { // 19.5 ms of bits + 0.3 ms latch
+		t.Fatalf("MaxFPS %.0f for 650 LEDs at 2.4 MHz", fps)
+	}
+	_ = s.Close()
+	if err := s.WriteBytes(make([]byte, 650*3)); err == nil {
+		t.Fatal("write after close accepted")
+	}
+}
+
+// TestSPIDecodesBack runs the SPI driver against a file and decodes the
+// captured line with the WS2812 harness, for every order and in-spec speed.
+func TestSPIDecodesBack(t *testing.T) {
+	frame := make([]render.Color, 40)
+	want := make([][3]byte, len(frame))
+	for i := range frame {
+		want[i] = [3]byte{byte(i * 6), byte(255 - i*5), byte(i * 37)}
+		frame[i] = render.Color{R: float32(want[i][0]) / 255, G: float32(want[i][1]) / 255, B: float32(want[i][2]) / 255}
+	}
+	for _, order := range []string{"RGB", "RBG", "GRB", "GBR", "BRG", "BGR"} {
+		for _, speed := range []int{2200000, 2400000, 2600000, 3000000} {
+			t.Run(fmt.Sprintf("%s@%d", order, speed), func(t *testing.T) {
+				s, dev := fakeSPI(t, len(frame), order, speed, "64") // several transfers per frame
+				a, err := NewAdapter(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for k := 0; k < 2; k++ {
+					if err := a.Write(frame); err != nil {
+						t.Fatal(err)
+					}
+				}
+				wire, _ := os.ReadFile(dev)
+				frames, err := ws2812test.Decode(wire, speed, ws2812test.WS2812B)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(frames) != 2 {
+					t.Fatalf("decoded %d frames, want 2", len(frames))
+				}
+				got, err := frames[1].RGB(order)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for i := range want {
+					if got[i] != want[i] {
+						t.Fatalf("pixel %d = %v, want %v", i, got[i], want[i])
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSPIRGBWDecodesBack(t *testing.T) {
+	frame := make([]render.Color, 24)
+	want := make([][4]byte, len(frame))
+	for i := range frame {
+		// distinct R, G, B above a common floor that moves to W
+		w := byte(i * 5)
+		want[i] = [4]byte{byte(i * 3), 0, byte(255 - w - byte(i*2)), w}
+		frame[i] = render.Color{
+			R: float32(want[i][0]+w) / 255,
+			G: float32(w) / 255,
+			B: float32(want[i][2]+w) / 255,
+		}
+	}
+	for _, order := range []string{"GRBW", "RGBW", "WRGB"} {
+		for _, speed := range []int{2800000, 3200000, 4000000} {
+			t.Run(fmt.Sprintf("%s@%d", order, speed), func(t *testing.T) {
+				s, dev := fakeSPI(t, len(frame), order, speed, "64")
+				if c := s.Caps(); c.Layout != render.LayoutRGBW {
+					t.Fatalf("layout %s", c.Layout)
+				}
+				a, err := NewAdapter(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := a.Write(frame); err != nil {
+					t.Fatal(err)
+				}
+				wire, _ := os.ReadFile(dev)
+				frames, err := ws2812test.Decode(wire, speed, ws2812test.SK6812)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := frames[0].RGBW(order)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for i := range want {
+					if got[i] != want[i] {
+						t.Fatalf("pixel %d = %v, want %v", i, got[i], want[i])
+					}
+				}
+			})
+		}
+	}
+	// WS2812 speeds put T1H out of the SK6812 window
+	s, dev := fakeSPI(t, 2, "GRBW", 2400000, "")
+	_ = s.WriteBytes(bytes.Repeat([]byte{0xff}, 8))
+	wire, _ := os.ReadFile(dev)
+	if _, err := ws2812test.Decode(wire, 2400000, ws2812test.SK6812); err == nil {
+		t.Error("2.4 MHz passed the SK6812 timing check")
+	}
+}
+
+func TestSPIOutOfSpecSpeedsAreCaught(t *testing.T) {
+	for _, speed := range []int{2000000, 3200000} {
+		s, dev := fakeSPI(t, 4, "GRB", speed, "")
+		_ = s.WriteBytes(bytes.Repeat([]byte{0xff}, 12)) // T1H is what falls out first
+		wire, _ := os.ReadFile(dev)
+		if _, err := ws2812test.Decode(wire, speed, ws2812test.WS2812B); err == nil {
+			t.Errorf("%d Hz passed the WS2812B timing check", speed)
+		}
+	}
+	// a frame cut short has no latch
+	s, dev := fakeSPI(t, 4, "GRB", 2400000, "")
+	_ = s.WriteBytes(make([]byte, 12))
+	wire, _ := os.ReadFile(dev)
+	if _, err := ws2812test.Decode(wire[:30], 2400000, ws2812test.WS2812B); err == nil {
+		t.Error("frame without reset decoded")
+	}
+}
@@ -2,7 +2,11 @@
 
 package led
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
 
 type SPI struct{}
 
@@ -10,6 +14,12 @@ func NewSPI(spiDev string, count int, colorOrder string, speedHz int, resetUs in
 	return nil, fmt.Errorf("spi driver not supported on this platform")
 }
 
-func (s *SPI) Write(rgb []byte) error { return fmt.Errorf("spi driver not supported on this platform") }
+func (s *SPI) Caps() render.Caps { return render.Caps{} }
+
+func (s *SPI) Open() error { return fmt.Errorf("spi driver not supported on this platform") }
+
+func (s *SPI) WriteBytes(px []byte) error {
+	return fmt.Errorf("spi driver not supported on this platform")
+}
 
 func (s *SPI) Close() error { return nil }
@@ -0,0 +1,191 @@
+//go:build linux
+
+package led
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+/*
+Minimal spidev ioctl bindings (no external deps).
+If you prefer, swap this file to use periph.io/x/conn/spi for cleaner setup.
+*/
+
+const (
+	spiIOCWriteMode        = 0x40016b01
+	spiIOCWriteBitsPerWord = 0x40016b03
+	spiIOCWriteMaxSpeedHz  = 0x40046b04
+	spiIOCMessage1         = 0x40206b00 // SPI_IOC_MESSAGE(1): _IOW('k', 0, struct spi_ioc_transfer)
+)
+
+// spiIOCTransfer mirrors struct spi_ioc_transfer (linux/spi/spidev.h).
+type spiIOCTransfer struct {
+	txBuf          uint64
+	rxBuf          uint64
+	length         uint32
+	speedHz        uint32
+	delayUsecs     uint16
+	bitsPerWord    uint8
+	csChange       uint8
+	txNbits        uint8
+	rxNbits        uint8
+	wordDelayUsecs uint8
+	pad            uint8
+}
+
+// spidev refuses messages longer than its bufsiz module parameter (4096
+// unless the module was loaded with a bigger one).
+const (
+	spidevBufsizPath    = "/sys/module/spidev/parameters/bufsiz"
+	spidevDefaultBufsiz = 4096
+)
+
+// spidev is the transport shared by the SPI LED drivers: a spidev node in
+// mode 0 at a fixed speed, written in bufsiz-sized transfers. Callers
+// serialize access.
+type spidev struct {
+	path    string
+	speedHz int
+
+	bufsizPath string // read on open; tests point it elsewhere
+	f          *os.File
+	chunk      int    // bytes per transfer
+	idle       []byte // a send delay as zero bytes, for plain files
+	// xfer sends one chunk and then holds the bus idle for delayUs. open
+	// picks SPI_IOC_MESSAGE for a spidev node and plain writes for anything
+	// else (a capture file, tests).
+	xfer func(tx []byte, delayUs uint16) error
+}
+
+func newSpidev(path string, speedHz int) *spidev {
+	return &spidev{path: path, speedHz: speedHz, bufsizPath: spidevBufsizPath}
+}
+
+func (d *spidev) isOpen() bool { return d.f != nil }
+
+// open opens the device with transfers of bufsiz rounded down to a multiple
+// of align. idleUs is the delay send may be asked for, which a plain file
+// records as that much bus time of zero bytes.
+func (d *spidev) open(align, idleUs int) error {
+	if d.f != nil {
+		return nil
+	}
+	f, err := os.OpenFile(d.path, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("open spidev: %w", err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("stat spidev: %w", err)
+	}
+	d.chunk = alignChunk(readBufsiz(d.bufsizPath), align)
+	if st.Mode()&os.ModeCharDevice == 0 {
+		// not a spidev node: write the line as the bus would clock it,
+		// delays included
+		d.f, d.xfer = f, d.writeChunk
+		d.idle = make([]byte, (idleUs*d.speedHz+7_999_999)/8_000_000)
+		return nil
+	}
+	// mode 0
+	mode := byte(0)
+	if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), spiIOCWriteMode, uintptr(unsafe.Pointer(&mode))); e != 0 {
+		_ = f.Close()
+		return fmt.Errorf("SPI set mode: %v", e)
+	}
+	// 8 bits per word
+	bpw := byte(8)
+	if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), spiIOCWriteBitsPerWord, uintptr(unsafe.Pointer(&bpw))); e != 0 {
+		_ = f.Close()
+		return fmt.Errorf("SPI set bits-per-word: %v", e)
+	}
+	// max speed
+	speed := uint32(d.speedHz)
+	if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), spiIOCWriteMaxSpeedHz, uintptr(unsafe.Pointer(&speed))); e != 0 {
+		_ = f.Close()
+		return fmt.Errorf("SPI set speed: %v", e)
+	}
+	d.f, d.xfer = f, d.transfer
+	return nil
+}
+
+func (d *spidev) close() error {
+	if d.f == nil {
+		return nil
+	}
+	err := d.f.Close()
+	d.f, d.xfer = nil, nil
+	return err
+}
+
+// send writes buf in chunk-sized transfers; the last one is followed by
+// delayUs of idle bus.
+func (d *spidev) send(buf []byte, delayUs uint16) error {
+	for off := 0; off < len(buf); off += d.chunk {
+		end := min(off+d.chunk, len(buf))
+		delay := uint16(0)
+		if end == len(buf) {
+			delay = delayUs
+		}
+		if err := d.xfer(buf[off:end], delay); err != nil {
+			return fmt.Errorf("bytes %d-%d of %d: %w", off, end, len(buf), err)
+		}
+	}
+	return nil
+}
+
+// transfer sends tx as one SPI_IOC_MESSAGE(1).
+func (d *spidev) transfer(tx []byte, delayUs uint16) error {
+	tr := spiIOCTransfer{
+		txBuf:       uint64(uintptr(unsafe.Pointer(&tx[0]))),
+		length:      uint32(len(tx)),
+		speedHz:     uint32(d.speedHz),
+		delayUsecs:  delayUs,
+		bitsPerWord: 8,
+	}
+	_, _, e := syscall.Syscall(syscall.SYS_IOCTL, d.f.Fd(), spiIOCMessage1, uintptr(unsafe.Pointer(&tr)))
+	if e != 0 {
+		return e
+	}
+	return nil
+}
+
+// writeChunk is xfer for plain files: a delay becomes the idle zero bytes,
+// so the file is the line sampled at the bus speed.
+func (d *spidev) writeChunk(tx []byte, delayUs uint16) error {
+	if _, err := d.f.Write(tx); err != nil {
+		return err
+	}
+	if delayUs == 0 {
+		return nil
+	}
+	_, err := d.f.Write(d.idle)
+	return err
+}
+
+// readBufsiz reads the spidev bufsiz parameter, 4096 if it is unreadable.
+func readBufsiz(path string) int {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return spidevDefaultBufsiz
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil || n <= 0 {
+		return spidevDefaultBufsiz
+	}
+	return n
+}
+
+// alignChunk rounds bufsiz down to a multiple of align (whole encoded
+// pixels), so a pause between transfers falls between two LEDs.
+func alignChunk(bufsiz, align int) int {
+	if align <= 1 || bufsiz < align {
+		return bufsiz
+	}
+	return bufsiz - bufsiz%align
+}
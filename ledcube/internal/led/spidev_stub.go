@@ -0,0 +1,19 @@
+//go:build !linux
+
+package led
+
+import "fmt"
+
+// spidev is the linux spidev transport; elsewhere it never opens.
+type spidev struct{}
+
+func newSpidev(path string, speedHz int) *spidev { return &spidev{} }
+
+func (d *spidev) isOpen() bool { return false }
+func (d *spidev) open(align, idleUs int) error {
+	return fmt.Errorf("spidev not supported on this platform")
+}
+func (d *spidev) close() error { return nil }
+func (d *spidev) send(buf []byte, delayUs uint16) error {
+	return fmt.Errorf("spidev not supported on this platform")
+}
@@ -0,0 +1,152 @@
+// Package ws2812test decodes a WS2812 data line from the SPI byte stream
+// that drives it, so encoders can be checked without a scope: each stream
+// bit is one line sample at the SPI clock, pulses are timed against the
+// datasheet and the bits are read back as channel bytes.
+package ws2812test
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Spec holds the line timing a decoder enforces, in nanoseconds.
+type Spec struct {
+	T0H, T1H  float64 // high time of a 0 and a 1 bit
+	HighTol   float64 // allowed deviation of either high time
+	PeriodMin float64 // bit period (high + low) bounds
+	PeriodMax float64
+	Reset     float64 // shortest low that latches a frame
+}
+
+// WS2812B is the WS2812B datasheet timing: T0H 0.4 µs, T1H 0.8 µs (±150 ns),
+// bit period 1.25 µs ±600 ns and a reset of at least 280 µs.
+var WS2812B = Spec{T0H: 400, T1H: 800, HighTol: 150, PeriodMin: 650, PeriodMax: 1850, Reset: 280_000}
+
+// SK6812 is the SK6812 (RGB and RGBW) datasheet timing: T0H 0.3 µs, T1H
+// 0.6 µs (±150 ns), bit period 1.25 µs ±600 ns and a reset of at least 80 µs.
+var SK6812 = Spec{T0H: 300, T1H: 600, HighTol: 150, PeriodMin: 650, PeriodMax: 1850, Reset: 80_000}
+
+// Frame is one latched frame: channel bytes in wire order.
+type Frame []byte
+
+// RGB maps the frame's pixels back to R, G, B using the wire order it was
+// sent in ("GRB", "RGB", ...). Orders with W return R, G, B only.
+func (f Frame) RGB(order string) ([][3]byte, error) {
+	order = strings.ToUpper(order)
+	n := len(order)
+	if n != 3 && n != 4 {
+		return nil, fmt.Errorf("order %q: want 3 or 4 channels", order)
+	}
+	if len(f)%n != 0 {
+		return nil, fmt.Errorf("%d bytes is not a whole number of %d-channel pixels", len(f), n)
+	}
+	out := make([][3]byte, len(f)/n)
+	for i := range out {
+		for k := 0; k < n; k++ {
+			if ch := strings.IndexByte("RGB", order[k]); ch >= 0 {
+				out[i][ch] = f[i*n+k]
+			}
+		}
+	}
+	return out, nil
+}
+
+// RGBW is RGB for four-channel orders ("GRBW", ...), with W last.
+func (f Frame) RGBW(order string) ([][4]byte, error) {
+	order = strings.ToUpper(order)
+	n := len(order)
+	if n != 4 {
+		return nil, fmt.Errorf("order %q: want 4 channels", order)
+	}
+	if len(f)%n != 0 {
+		return nil, fmt.Errorf("%d bytes is not a whole number of %d-channel pixels", len(f), n)
+	}
+	out := make([][4]byte, len(f)/n)
+	for i := range out {
+		for k := 0; k < n; k++ {
+			if ch := strings.IndexByte("RGBW", order[k]); ch >= 0 {
+				out[i][ch] = f[i*n+k]
+			}
+		}
+	}
+	return out, nil
+}
+
+// run is a stretch of equal line level.
+type run struct {
+	high bool
+	ns   float64
+}
+
+// Decode rebuilds the line from stream sampled at speedHz and decodes every
+// frame in it. A frame ends at a low of at least spec.Reset; the stream must
+// end with one. Any pulse outside spec is an error naming its position.
+func Decode(stream []byte, speedHz int, spec Spec) ([]Frame, error) {
+	if speedHz <= 0 {
+		return nil, fmt.Errorf("speed %d Hz", speedHz)
+	}
+	sample := 1e9 / float64(speedHz)
+	var runs []run
+	for _, b := range stream {
+		for i := 7; i >= 0; i-- {
+			high := b>>i&1 == 1
+			if k := len(runs) - 1; k >= 0 && runs[k].high == high {
+				runs[k].ns += sample
+			} else {
+				runs = append(runs, run{high: high, ns: sample})
+			}
+		}
+	}
+
+	var (
+		frames []Frame
+		cur    []byte
+		bits   int
+		acc    byte
+		t      float64 // line time at the current run, for errors
+	)
+	i := 0
+	if len(runs) > 0 && !runs[0].high {
+		t += runs[0].ns // idle before the first frame
+		i = 1
+	}
+	for ; i < len(runs); i += 2 {
+		hi := runs[i]
+		var lo run
+		if i+1 < len(runs) {
+			lo = runs[i+1]
+		}
+		var bit byte
+		switch {
+		case near(hi.ns, spec.T0H, spec.HighTol):
+		case near(hi.ns, spec.T1H, spec.HighTol):
+			bit = 1
+		default:
+			return nil, fmt.Errorf("bit %d of frame %d at %.0f ns: high for %.0f ns, want %.0f or %.0f ±%.0f",
+				bits, len(frames), t, hi.ns, spec.T0H, spec.T1H, spec.HighTol)
+		}
+		acc = acc<<1 | bit
+		bits++
+		if bits%8 == 0 {
+			cur = append(cur, acc)
+			acc = 0
+		}
+		if lo.ns >= spec.Reset {
+			if bits%8 != 0 {
+				return nil, fmt.Errorf("frame %d: %d bits is not whole bytes", len(frames), bits)
+			}
+			frames = append(frames, Frame(cur))
+			cur, bits = nil, 0
+		} else if p := hi.ns + lo.ns; lo.ns > 0 && (p < spec.PeriodMin || p > spec.PeriodMax) {
+			return nil, fmt.Errorf("bit %d of frame %d at %.0f ns: period %.0f ns, want %.0f..%.0f",
+				bits-1, len(frames), t, p, spec.PeriodMin, spec.PeriodMax)
+		}
+		t += hi.ns + lo.ns
+	}
+	if bits > 0 {
+		return nil, fmt.Errorf("frame %d: stream ends without a %.0f ns reset", len(frames), spec.Reset)
+	}
+	return frames, nil
+}
+
+func near(v, want, tol float64) bool { return v >= want-tol && v <= want+tol }
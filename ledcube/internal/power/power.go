@@ -0,0 +1,475 @@
+// Package power keeps the LED supply inside its budget: a per-LED white cap,
+// a soft-start ramp at boot and after blackout, per-zone limits for each
+// injection point, a global amp cap and per-panel thermal throttling under
+// sustained load. The engine runs a Manager as its last output stage, so
+// every output path (desktop preview, headless server, hardware drivers) sees
+// the same frame.
+package power
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/config"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+// darkmA is the frame current below which the output counts as blacked out;
+// the next lit frame restarts the soft-start ramp.
+const darkmA = 1.0
+
+// Config is config.PowerCfg with the strip names resolved to current models.
+type Config struct {
+	LimitAmps   float64             // global cap; 0 = no limit
+	WhiteCap    float64             // per-LED cap on (R+G+B)/3, in (0,1); 0 or ≥1 = off
+	SoftStartMs int                 // ramp length; 0 = off
+	Model       render.CurrentModel // LEDs outside zones with their own; zero = linear 20 mA
+	Zones       []Zone
+	Thermal     Thermal
+}
+
+// Zone is one supply/injection point. LEDs outside every zone only count
+// toward the global cap.
+type Zone struct {
+	Name      string
+	Panels    []int               // Z indices
+	Ranges    [][2]int            // LED index ranges [from, to)
+	LimitAmps float64             // 0 = unlimited (still reported)
+	Knee      float64             // fraction of LimitAmps where soft limiting starts; 0 = hard
+	Model     render.CurrentModel // zero = Config.Model
+}
+
+// FromConfig maps the config.yaml power section. Strip names resolve against
+// the section's own strips first, then the built-in models (render.Strips);
+// an empty name means render.DefaultStrip.
+func FromConfig(c config.PowerCfg) (Config, error) {
+	out := Config{LimitAmps: c.LimitAmps, WhiteCap: c.WhiteCap, SoftStartMs: c.SoftStartMs, Thermal: thermalFromConfig(c.Thermal)}
+	m, err := stripModel(c, c.Strip)
+	if err != nil {
+		return out, err
+	}
+	out.Model = m
+	for _, z := range c.Zones {
+		zc := Zone{Name: z.Name, Panels: z.Panels, Ranges: z.Ranges, LimitAmps: z.LimitAmps, Knee: z.Knee}
+		if z.Strip != "" {
+			if zc.Model, err = stripModel(c, z.Strip); err != nil {
+				return out, fmt.Errorf("power zone %q: %w", z.Name, err)
+			}
+		}
+		out.Zones = append(out.Zones, zc)
+	}
+	return out, nil
+}
+
+// WithWhite returns c with every strip model estimating RGBW parts through
+// the driver's white split w (see render.CurrentModel.WhiteMilliamps).
+func (c Config) WithWhite(w render.White) Config {
+	c.Model = c.Model.SetWhite(w)
+	c.Zones = append([]Zone(nil), c.Zones...)
+	for i := range c.Zones {
+		c.Zones[i].Model = c.Zones[i].Model.SetWhite(w)
+	}
+	return c
+}
+
+func stripModel(c config.PowerCfg, name string) (render.CurrentModel, error) {
+	if name == "" {
+		name = render.DefaultStrip
+	}
+	if s, ok := c.Strips[name]; ok {
+		m := render.CurrentModel{Name: name, WhiteMilliamps: s.WhiteMilliamps, IdleMilliamps: s.IdleMilliamps, Exponent: s.Exponent}
+		switch len(s.ChanMilliamps) {
+		case 1:
+			m.ChanMilliamps = [3]float64{s.ChanMilliamps[0], s.ChanMilliamps[0], s.ChanMilliamps[0]}
+		case 3:
+			copy(m.ChanMilliamps[:], s.ChanMilliamps)
+		default:
+			return m, fmt.Errorf("strip %q: chan_ma wants 1 or 3 values, got %d", name, len(s.ChanMilliamps))
+		}
+		return m, m.Validate()
+	}
+	if m, ok := render.StripModel(name); ok {
+		return m, nil
+	}
+	return render.CurrentModel{}, fmt.Errorf("unknown strip %q", name)
+}
+
+// Report describes the last frame. Currents include the LEDs' idle draw.
+type Report struct {
+	Strip         string         `json:"strip"`       // model for LEDs outside zones with their own
+	IdleAmps      float64        `json:"idle_a"`      // quiescent draw of the whole cube
+	EstimatedAmps float64        `json:"estimated_a"` // before white cap, ramp and limits
+	LimitedAmps   float64        `json:"limited_a"`   // what was sent
+	LimitAmps     float64        `json:"limit_a"`
+	Ramp          float64        `json:"ramp"`     // soft-start factor, 0..1
+	Scale         float64        `json:"scale"`    // global cap scale, 0..1
+	Limiting      bool           `json:"limiting"` // global cap engaged
+	Zones         []ZoneReport   `json:"zones,omitempty"`
+	Thermal       []PanelThermal `json:"thermal,omitempty"` // only with a thermal limit
+}
+
+// ZoneReport is one zone's share of the last frame.
+type ZoneReport struct {
+	Name          string  `json:"name"`
+	EstimatedAmps float64 `json:"estimated_a"`
+	LimitedAmps   float64 `json:"limited_a"`
+	LimitAmps     float64 `json:"limit_a"`
+	Scale         float64 `json:"scale"`
+	Limiting      bool    `json:"limiting"`
+}
+
+// Manager implements render.FrameLimiter.
+type Manager struct {
+	mu     sync.Mutex
+	cfg    Config
+	dim    render.Dimensions
+	zoneOf []int16               // LED → zone index, -1 = none
+	models []render.CurrentModel // per zone slot (last = unzoned)
+	idle   []float64             // mA per zone slot
+	gain   float64               // output gain applied after the engine (ws brightness)
+	now    func() time.Time
+	start  time.Time // ramp start; zero = dark, ramp on next lit frame
+	last   Report
+	heat   thermalState
+
+	// per-frame scratch
+	est, cur, scale []float64
+}
+
+// New returns a Manager for a cube of dim; the ramp starts with the first
+// lit frame. Zones that overlap or fall outside dim are rejected.
+func New(cfg Config, dim render.Dimensions) (*Manager, error) {
+	m := &Manager{dim: dim, gain: 1, now: time.Now}
+	m.heat.reset(dim.Z)
+	if err := m.SetConfig(cfg); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SetConfig swaps the settings without restarting the ramp.
+func (m *Manager) SetConfig(cfg Config) error {
+	zoneOf, err := mapZones(cfg.Zones, m.dim)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Model.Validate(); err != nil {
+		return err
+	}
+	for _, z := range cfg.Zones {
+		if err := z.Model.Validate(); err != nil {
+			return fmt.Errorf("power zone %q: %w", z.Name, err)
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cfg = cfg
+	m.zoneOf = zoneOf
+	n := len(cfg.Zones)
+	m.est, m.cur, m.scale = make([]float64, n+1), make([]float64, n+1), make([]float64, n+1)
+	m.models, m.idle = make([]render.CurrentModel, n+1), make([]float64, n+1)
+	for zi := range m.models {
+		m.models[zi] = cfg.Model
+		if zi < n && !cfg.Zones[zi].Model.IsZero() {
+			m.models[zi] = cfg.Zones[zi].Model
+		}
+		if m.models[zi].IsZero() {
+			m.models[zi] = render.LinearModel(0)
+		}
+	}
+	for i := range zoneOf {
+		zi := n
+		if zoneOf[i] >= 0 {
+			zi = int(zoneOf[i])
+		}
+		m.idle[zi] += m.models[zi].IdleMilliamps
+	}
+	return nil
+}
+
+func mapZones(zones []Zone, dim render.Dimensions) ([]int16, error) {
+	n := dim.X * dim.Y * dim.Z
+	perPanel := dim.X * dim.Y
+	zoneOf := make([]int16, n)
+	for i := range zoneOf {
+		zoneOf[i] = -1
+	}
+	claim := func(zi, from, to int) error {
+		if from < 0 || to > n || from >= to {
+			return fmt.Errorf("power zone %q: range [%d,%d) outside [0,%d)", zones[zi].Name, from, to, n)
+		}
+		for i := from; i < to; i++ {
+			if zoneOf[i] >= 0 && int(zoneOf[i]) != zi {
+				return fmt.Errorf("power zone %q: LED %d already in zone %q", zones[zi].Name, i, zones[zoneOf[i]].Name)
+			}
+			zoneOf[i] = int16(zi)
+		}
+		return nil
+	}
+	for zi, z := range zones {
+		if z.Knee < 0 || z.Knee >= 1 {
+			return nil, fmt.Errorf("power zone %q: knee %v outside [0,1)", z.Name, z.Knee)
+		}
+		for _, p := range z.Panels {
+			if p < 0 || p >= dim.Z {
+				return nil, fmt.Errorf("power zone %q: panel %d outside [0,%d)", z.Name, p, dim.Z)
+			}
+			if err := claim(zi, p*perPanel, (p+1)*perPanel); err != nil {
+				return nil, err
+			}
+		}
+		for _, r := range z.Ranges {
+			if err := claim(zi, r[0], r[1]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return zoneOf, nil
+}
+
+// Config returns the current settings.
+func (m *Manager) Config() Config {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cfg
+}
+
+// SetOutputGain tells the manager about scaling applied after the engine
+// (e.g. the headless server's brightness), so estimates and the limit refer
+// to the current actually drawn.
+func (m *Manager) SetOutputGain(g float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if g < 0 {
+		g = 0
+	}
+	m.gain = g
+}
+
+// SetClock replaces the wall clock that drives the soft start and the
+// thermal averages, for offline runs faster than real time.
+func (m *Manager) SetClock(now func() time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = now
+}
+
+// Blackout marks the output dark; the next lit frame ramps up again.
+func (m *Manager) Blackout() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.start = time.Time{}
+}
+
+// Last returns the report for the most recent frame.
+func (m *Manager) Last() Report {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r := m.last
+	r.Zones = append([]ZoneReport(nil), m.last.Zones...)
+	r.Thermal = append([]PanelThermal(nil), m.last.Thermal...)
+	return r
+}
+
+// Limit caps, ramps and limits buf in place.
+func (m *Manager) Limit(buf []render.Color) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cfg := m.cfg
+	nz := len(cfg.Zones) // slot nz collects LEDs outside every zone
+
+	m.drive(buf, m.est)
+	var idle, est float64
+	for zi, v := range m.est {
+		idle += m.idle[zi]
+		est += v
+	}
+	rep := Report{
+		Strip: m.models[nz].Name, IdleAmps: idle / 1000, EstimatedAmps: (idle + est) / 1000,
+		LimitAmps: cfg.LimitAmps, Ramp: 1, Scale: 1, Zones: m.last.Zones[:0],
+	}
+	now := m.now()
+	perPanel := m.dim.X * m.dim.Y
+	defer func() {
+		// panels cool down through dark frames too
+		m.panelAmps(buf, perPanel, m.heat.sent)
+		m.heat.update(cfg.Thermal, now)
+		if cfg.Thermal.PanelLimitAmps > 0 {
+			m.last.Thermal = m.heat.report(m.last.Thermal[:0])
+		} else {
+			m.last.Thermal = m.last.Thermal[:0]
+		}
+	}()
+	if est < darkmA {
+		m.start = time.Time{}
+		for zi, z := range cfg.Zones {
+			a := (m.idle[zi] + m.est[zi]) / 1000
+			rep.Zones = append(rep.Zones, ZoneReport{Name: z.Name, EstimatedAmps: a, LimitedAmps: a, LimitAmps: z.LimitAmps, Scale: 1})
+		}
+		rep.LimitedAmps = rep.EstimatedAmps
+		rep.Thermal = m.last.Thermal
+		m.last = rep
+		return
+	}
+
+	if cfg.WhiteCap > 0 && cfg.WhiteCap < 1 {
+		whiteCap(buf, float32(cfg.WhiteCap*3))
+	}
+	m.heat.apply(buf, perPanel)
+
+	if m.start.IsZero() {
+		m.start = now
+	}
+	if cfg.SoftStartMs > 0 {
+		if r := float64(now.Sub(m.start).Milliseconds()) / float64(cfg.SoftStartMs); r < 1 {
+			rep.Ramp = r
+		}
+	}
+
+	// per-zone limits on the capped, ramped frame; m.cur becomes each
+	// slot's drive current after its zone scale
+	m.drive(buf, m.cur)
+	for zi := range m.cur {
+		md := m.models[zi]
+		m.cur[zi] *= md.Gain(rep.Ramp)
+		m.scale[zi] = 1
+		if zi < nz {
+			z := cfg.Zones[zi]
+			cur := m.idle[zi] + m.cur[zi]
+			allowed := cur * kneeScale(cur, z.LimitAmps*1000, z.Knee)
+			m.scale[zi] = md.ScaleFor(m.cur[zi], allowed-m.idle[zi])
+			m.cur[zi] *= md.Gain(m.scale[zi])
+			rep.Zones = append(rep.Zones, ZoneReport{
+				Name: z.Name, EstimatedAmps: (m.idle[zi] + m.est[zi]) / 1000,
+				LimitAmps: z.LimitAmps, Scale: m.scale[zi], Limiting: m.scale[zi] < 1,
+			})
+		}
+	}
+
+	// global cap over what the zones let through
+	sent := func(g float64) float64 {
+		t := idle
+		for zi, d := range m.cur {
+			t += d * m.models[zi].Gain(g)
+		}
+		return t
+	}
+	if limit := cfg.LimitAmps * 1000; limit > 0 && sent(1) > limit {
+		rep.Scale = solveScale(sent, limit)
+		rep.Limiting = true
+	}
+	for i := range rep.Zones {
+		rep.Zones[i].LimitedAmps = (m.idle[i] + m.cur[i]*m.models[i].Gain(rep.Scale)) / 1000
+	}
+	k := rep.Ramp * rep.Scale
+	for i := range buf {
+		zi := nz
+		if i < len(m.zoneOf) && m.zoneOf[i] >= 0 {
+			zi = int(m.zoneOf[i])
+		}
+		if s := float32(k * m.scale[zi]); s < 1 {
+			buf[i].R *= s
+			buf[i].G *= s
+			buf[i].B *= s
+		}
+	}
+	rep.LimitedAmps = sent(rep.Scale) / 1000
+	rep.Thermal = m.last.Thermal
+	m.last = rep
+}
+
+// drive accumulates the drive current (mA above idle, at the output gain)
+// per zone into out (last slot = unzoned).
+func (m *Manager) drive(buf []render.Color, out []float64) {
+	for i := range out {
+		out[i] = 0
+	}
+	nz := len(out) - 1
+	for i, c := range buf {
+		zi := nz
+		if i < len(m.zoneOf) && m.zoneOf[i] >= 0 {
+			zi = int(m.zoneOf[i])
+		}
+		out[zi] += m.models[zi].DriveMilliamps(c)
+	}
+	for zi := range out {
+		out[zi] *= m.models[zi].Gain(m.gain)
+	}
+}
+
+// panelAmps fills out with each panel's total current for buf as sent.
+func (m *Manager) panelAmps(buf []render.Color, perPanel int, out []float64) {
+	for i := range out {
+		out[i] = 0
+	}
+	if perPanel <= 0 {
+		return
+	}
+	nz := len(m.models) - 1
+	for i, c := range buf {
+		p := i / perPanel
+		if p >= len(out) {
+			break
+		}
+		zi := nz
+		if i < len(m.zoneOf) && m.zoneOf[i] >= 0 {
+			zi = int(m.zoneOf[i])
+		}
+		md := m.models[zi]
+		out[p] += (md.IdleMilliamps + md.DriveMilliamps(c)*md.Gain(m.gain)) / 1000
+	}
+}
+
+// solveScale finds the brightness scale in [0,1] at which f (increasing in
+// the scale) meets target. Zones may use different models, so there is no
+// closed form in general.
+func solveScale(f func(float64) float64, target float64) float64 {
+	lo, hi := 0.0, 1.0
+	if f(0) >= target {
+		return 0
+	}
+	for i := 0; i < 40; i++ {
+		mid := (lo + hi) / 2
+		if f(mid) > target {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return lo
+}
+
+// kneeScale returns the factor that keeps cur under limit. Up to
+// knee*limit it is 1; above, the excess x over the knee is compressed to
+// x*w/(x+w), w being the room left to the limit, so the current bends
+// smoothly toward the limit without passing it. knee 0 is a hard limit.
+func kneeScale(cur, limit, knee float64) float64 {
+	if limit <= 0 || cur <= 0 {
+		return 1
+	}
+	if knee <= 0 {
+		if cur > limit {
+			return limit / cur
+		}
+		return 1
+	}
+	k := knee * limit
+	if cur <= k {
+		return 1
+	}
+	w, x := limit-k, cur-k
+	return (k + x*w/(x+w)) / cur
+}
+
+func whiteCap(buf []render.Color, wc float32) {
+	for i := range buf {
+		s := buf[i].R + buf[i].G + buf[i].B
+		if s > wc {
+			k := wc / s
+			buf[i].R *= k
+			buf[i].G *= k
+			buf[i].B *= k
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package power
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/config"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+func white(n int) []render.Color {
+	buf := make([]render.Color, n)
+	for i := range buf {
+		buf[i] = render.Color{R: 1, G: 1, B: 1}
+	}
+	return buf
+}
+
+func TestAmpLimit(t *testing.T) {
+	m, _ := New(Config{LimitAmps: 0.3}, render.Dimensions{X: 10, Y: 1, Z: 1}) // 10 white LEDs draw 0.6 A
+	buf := white(10)
+	m.Limit(buf)
+	r := m.Last()
+	if math.Abs(r.EstimatedAmps-0.6) > 1e-9 || math.Abs(r.LimitedAmps-0.3) > 1e-6 || !r.Limiting {
+		t.Fatalf("report = %+v", r)
+	}
+	if math.Abs(float64(buf[0].R)-0.5) > 1e-6 {
+		t.Fatalf("expected frame halved, got %+v", buf[0])
+	}
+
+	// output gain counts toward the budget
+	m.SetOutputGain(0.5)
+	buf = white(10)
+	m.Limit(buf)
+	if r := m.Last(); r.Limiting || buf[0].R != 1 || math.Abs(r.LimitedAmps-0.3) > 1e-9 {
+		t.Fatalf("at half gain the frame fits: %+v %+v", r, buf[0])
+	}
+}
+
+func TestWhiteCap(t *testing.T) {
+	m, _ := New(Config{WhiteCap: 0.5}, render.Dimensions{X: 2, Y: 1, Z: 1})
+	buf := []render.Color{{R: 1, G: 1, B: 1}, {R: 1}}
+	m.Limit(buf)
+	if s := buf[0].R + buf[0].G + buf[0].B; math.Abs(float64(s)-1.5) > 1e-6 {
+		t.Fatalf("white not capped: %+v", buf[0])
+	}
+	if buf[1].R != 1 {
+		t.Fatalf("single channel under the cap changed: %+v", buf[1])
+	}
+}
+
+func TestSoftStartAtBootAndAfterBlackout(t *testing.T) {
+	clock := time.Unix(0, 0)
+	m, _ := New(Config{SoftStartMs: 1000}, render.Dimensions{X: 1, Y: 1, Z: 1})
+	m.now = func() time.Time { return clock }
+
+	step := func(lit bool) (float32, Report) {
+		buf := white(1)
+		if !lit {
+			buf[0] = render.Color{}
+		}
+		m.Limit(buf)
+		return buf[0].R, m.Last()
+	}
+	if v, _ := step(true); v != 0 {
+		t.Fatalf("boot frame should start dark, got %v", v)
+	}
+	clock = clock.Add(500 * time.Millisecond)
+	if v, r := step(true); math.Abs(float64(v)-0.5) > 1e-6 || r.Ramp != 0.5 {
+		t.Fatalf("mid-ramp = %v (%+v)", v, r)
+	}
+	clock = clock.Add(time.Second)
+	if v, _ := step(true); v != 1 {
+		t.Fatalf("after ramp = %v", v)
+	}
+
+	step(false) // blackout
+	clock = clock.Add(250 * time.Millisecond)
+	if v, _ := step(true); v != 0 {
+		t.Fatalf("first frame after blackout should ramp from 0, got %v", v)
+	}
+	clock = clock.Add(250 * time.Millisecond)
+	if v, _ := step(true); math.Abs(float64(v)-0.25) > 1e-6 {
+		t.Fatalf("ramp after blackout = %v", v)
+	}
+}
+
+func TestZonesLimitIndependently(t *testing.T) {
+	dim := render.Dimensions{X: 2, Y: 1, Z: 3} // panels of 2 LEDs
+	m, err := New(Config{
+		LimitAmps: 1,
+		Zones: []Zone{
+			{Name: "front", Panels: []int{0}, LimitAmps: 0.06},                // half of one white panel
+			{Name: "back", Ranges: [][2]int{{2, 6}}, LimitAmps: 1, Knee: 0.9}, // plenty
+		},
+	}, dim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := white(6)
+	m.Limit(buf)
+	if math.Abs(float64(buf[0].R)-0.5) > 1e-6 || buf[2].R != 1 || buf[5].R != 1 {
+		t.Fatalf("only the hot zone should dim: %+v", buf)
+	}
+	r := m.Last()
+	if len(r.Zones) != 2 || !r.Zones[0].Limiting || r.Zones[1].Limiting {
+		t.Fatalf("zones = %+v", r.Zones)
+	}
+	if math.Abs(r.Zones[0].EstimatedAmps-0.12) > 1e-9 || math.Abs(r.Zones[0].LimitedAmps-0.06) > 1e-9 {
+		t.Fatalf("front zone = %+v", r.Zones[0])
+	}
+
+	// global cap still applies on top of the zones
+	_ = m.SetConfig(Config{LimitAmps: 0.15, Zones: m.Config().Zones})
+	buf = white(6)
+	m.Limit(buf)
+	if r := m.Last(); !r.Limiting || math.Abs(r.LimitedAmps-0.15) > 1e-9 {
+		t.Fatalf("global cap = %+v", r)
+	}
+}
+
+func TestZoneKneeDimsBelowTheLimit(t *testing.T) {
+	// 10 white LEDs draw 0.6 A: 92% of the zone, above its 50% knee
+	dim := render.Dimensions{X: 10, Y: 1, Z: 2}
+	m, err := New(Config{Zones: []Zone{
+		{Name: "soft", Panels: []int{0}, LimitAmps: 0.65, Knee: 0.5},
+		{Name: "calm", Panels: []int{1}, LimitAmps: 2, Knee: 0.5},
+	}}, dim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := white(20)
+	m.Limit(buf)
+	z := m.Last().Zones
+	if !z[0].Limiting || z[0].LimitedAmps >= 0.6 || z[0].LimitedAmps <= 0.325 || buf[0].R >= 0.95 {
+		t.Fatalf("zone above its knee not dimmed: %+v, LED %+v", z[0], buf[0])
+	}
+	if z[1].Limiting || buf[10].R != 1 {
+		t.Fatalf("zone under its knee dimmed: %+v, LED %+v", z[1], buf[10])
+	}
+
+	// the compressed current rises with the load but never passes the limit
+	prev := 0.0
+	for _, cur := range []float64{0.4, 0.6, 0.65, 1, 10} {
+		got := cur * kneeScale(cur, 0.65, 0.5)
+		if got <= prev || got > 0.65 {
+			t.Fatalf("kneeScale at %v A lets %v A through (previous %v)", cur, got, prev)
+		}
+		prev = got
+	}
+}
+
+func TestZoneValidation(t *testing.T) {
+	dim := render.Dimensions{X: 2, Y: 1, Z: 2}
+	for name, z := range map[string][]Zone{
+		"overlap": {{Name: "a", Panels: []int{0}}, {Name: "b", Ranges: [][2]int{{1, 3}}}},
+		"panel":   {{Name: "a", Panels: []int{2}}},
+		"range":   {{Name: "a", Ranges: [][2]int{{3, 5}}}},
+		"knee":    {{Name: "a", Panels: []int{0}, Knee: 1}},
+	} {
+		if _, err := New(Config{Zones: z}, dim); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestThermalThrottleAndRecovery(t *testing.T) {
+	clock := time.Unix(0, 0)
+	dim := render.Dimensions{X: 10, Y: 1, Z: 2} // white panel = 0.6 A
+	m, _ := New(Config{Thermal: Thermal{PanelLimitAmps: 0.25, WindowS: 1, Hysteresis: 0.2, MinScale: 0.5, RatePerS: 0.1}}, dim)
+	m.now = func() time.Time { return clock }
+
+	frame := func(level float32) []render.Color {
+		buf := make([]render.Color, 20)
+		for i := 0; i < 10; i++ { // panel 0 only
+			buf[i] = render.Color{R: level, G: level, B: level}
+		}
+		m.Limit(buf)
+		clock = clock.Add(100 * time.Millisecond)
+		return buf
+	}
+
+	buf := frame(1)
+	if buf[0].R != 1 {
+		t.Fatalf("a short burst must not throttle: %+v", buf[0])
+	}
+	for i := 0; i < 30; i++ { // 3 s sustained
+		buf = frame(1)
+	}
+	r := m.Last()
+	if len(r.Thermal) != 2 || !r.Thermal[0].Throttling || r.Thermal[1].Throttling {
+		t.Fatalf("thermal = %+v", r.Thermal)
+	}
+	if s := r.Thermal[0].Scale; s >= 1 || s <= 0.5 {
+		t.Fatalf("throttling should be gradual, scale = %v", s)
+	}
+	if math.Abs(float64(buf[0].R)-r.Thermal[0].Scale) > 0.011 { // the report is one step ahead
+		t.Fatalf("panel not dimmed by its scale: %v vs %v", buf[0].R, r.Thermal[0].Scale)
+	}
+
+	for i := 0; i < 100; i++ { // 0.3 A at the floor is still over the limit
+		buf = frame(1)
+	}
+	if r := m.Last(); math.Abs(r.Thermal[0].Scale-0.5) > 1e-9 || math.Abs(float64(buf[0].R)-0.5) > 1e-6 {
+		t.Fatalf("floor = %+v, led %v", r.Thermal[0], buf[0].R)
+	}
+
+	// 0.225 A is under the limit but inside the hysteresis band (0.2 A), so
+	// the panel holds
+	for i := 0; i < 100; i++ {
+		frame(0.75)
+	}
+	if r := m.Last(); !r.Thermal[0].Throttling || math.Abs(r.Thermal[0].Scale-0.5) > 1e-9 {
+		t.Fatalf("released inside the band: %+v", r.Thermal[0])
+	}
+
+	for i := 0; i < 100; i++ { // dark frames cool the panel
+		frame(0)
+	}
+	if r := m.Last(); r.Thermal[0].Throttling || r.Thermal[0].Scale != 1 {
+		t.Fatalf("no recovery: %+v", r.Thermal[0])
+	}
+}
+
+func TestStripModelInLimits(t *testing.T) {
+	model := render.CurrentModel{Name: "t", ChanMilliamps: [3]float64{10, 10, 10}, IdleMilliamps: 2, Exponent: 2}
+	m, err := New(Config{LimitAmps: 0.05, Model: model}, render.Dimensions{X: 2, Y: 1, Z: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := white(2)
+	m.Limit(buf)
+	r := m.Last()
+	if r.Strip != "t" || math.Abs(r.IdleAmps-0.004) > 1e-9 || math.Abs(r.EstimatedAmps-0.064) > 1e-9 {
+		t.Fatalf("report = %+v", r)
+	}
+	// 46 mA of drive may remain out of 60: brightness √(46/60) on a square law
+	if math.Abs(r.LimitedAmps-0.05) > 1e-6 || math.Abs(float64(buf[0].R)-math.Sqrt(46.0/60)) > 1e-4 {
+		t.Fatalf("limited = %+v, led %v", r, buf[0].R)
+	}
+
+	// idle draw alone does not count as lit
+	buf = make([]render.Color, 2)
+	m.Limit(buf)
+	if r := m.Last(); r.Ramp != 1 || math.Abs(r.LimitedAmps-0.004) > 1e-9 {
+		t.Fatalf("dark frame = %+v", r)
+	}
+}
+
+func TestFromConfigStrips(t *testing.T) {
+	c := config.PowerCfg{
+		Strip:  "mine",
+		Strips: map[string]config.StripModel{"mine": {ChanMilliamps: []float64{15}, IdleMilliamps: 0.5}},
+		Zones:  []config.PowerZone{{Name: "old", Panels: []int{0}, Strip: "linear"}},
+	}
+	pc, err := FromConfig(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pc.Model.ChanMilliamps != [3]float64{15, 15, 15} || pc.Model.IdleMilliamps != 0.5 || pc.Zones[0].Model.Name != "linear" {
+		t.Fatalf("config = %+v", pc)
+	}
+	if pc, _ := FromConfig(config.PowerCfg{}); pc.Model.Name != render.DefaultStrip {
+		t.Fatalf("default strip = %q", pc.Model.Name)
+	}
+	c.Zones[0].Strip = "nope"
+	if _, err := FromConfig(c); err == nil {
+		t.Fatal("unknown strip accepted")
+	}
+}
+
+func TestSummary(t *testing.T) {
+	var s Summary
+	s.Add(Report{EstimatedAmps: 2, LimitedAmps: 1, Limiting: true, Zones: []ZoneReport{{Name: "a", LimitedAmps: 1}}}, 1)
+	s.Add(Report{EstimatedAmps: 4, LimitedAmps: 3, Zones: []ZoneReport{{Name: "a", LimitedAmps: 3}}}, 3)
+	if s.Frames != 2 || s.PeakLimitedAmps != 3 || s.MeanLimitedAmps != 2.5 || s.MeanEstimatedAmps != 3.5 || s.LimitingS != 1 {
+		t.Fatalf("summary = %+v", s)
+	}
+	if z := s.Zones[0]; z.MeanLimitedAmps != 2.5 || z.PeakLimitedAmps != 3 {
+		t.Fatalf("zone = %+v", z)
+	}
+}
@@ -0,0 +1,80 @@
+package power
+
+// Summary accumulates frame Reports over a run, e.g. an offline pass over a
+// whole program. Means are time-weighted.
+type Summary struct {
+	Frames            int            `json:"frames"`
+	Seconds           float64        `json:"seconds"`
+	Strip             string         `json:"strip"`
+	IdleAmps          float64        `json:"idle_a"`
+	PeakEstimatedAmps float64        `json:"peak_estimated_a"`
+	PeakLimitedAmps   float64        `json:"peak_limited_a"`
+	MeanEstimatedAmps float64        `json:"mean_estimated_a"`
+	MeanLimitedAmps   float64        `json:"mean_limited_a"`
+	LimitingS         float64        `json:"limiting_s"` // time with the global cap engaged
+	Ah                float64        `json:"ah"`         // charge drawn after limiting
+	Zones             []ZoneSummary  `json:"zones,omitempty"`
+	Panels            []PanelSummary `json:"panels,omitempty"` // only with a thermal limit
+
+	sumEst float64
+}
+
+// ZoneSummary is one zone's share of a Summary.
+type ZoneSummary struct {
+	Name            string  `json:"name"`
+	LimitAmps       float64 `json:"limit_a"`
+	PeakLimitedAmps float64 `json:"peak_limited_a"`
+	MeanLimitedAmps float64 `json:"mean_limited_a"`
+	LimitingS       float64 `json:"limiting_s"`
+}
+
+// PanelSummary is one panel's thermal history in a Summary.
+type PanelSummary struct {
+	Panel       int     `json:"panel"`
+	PeakAvgAmps float64 `json:"peak_avg_a"`
+	MinScale    float64 `json:"min_scale"`
+	ThrottlingS float64 `json:"throttling_s"`
+}
+
+// Add folds in one frame that was on the output for dt seconds.
+func (s *Summary) Add(r Report, dt float64) {
+	s.Frames++
+	s.Seconds += dt
+	s.Strip, s.IdleAmps = r.Strip, r.IdleAmps
+	s.PeakEstimatedAmps = max(s.PeakEstimatedAmps, r.EstimatedAmps)
+	s.PeakLimitedAmps = max(s.PeakLimitedAmps, r.LimitedAmps)
+	s.sumEst += r.EstimatedAmps * dt
+	s.Ah += r.LimitedAmps * dt / 3600
+	if r.Limiting {
+		s.LimitingS += dt
+	}
+	if s.Seconds > 0 {
+		s.MeanEstimatedAmps = s.sumEst / s.Seconds
+		s.MeanLimitedAmps = s.Ah * 3600 / s.Seconds
+	}
+
+	for i, z := range r.Zones {
+		if i == len(s.Zones) {
+			s.Zones = append(s.Zones, ZoneSummary{Name: z.Name, LimitAmps: z.LimitAmps})
+		}
+		zs := &s.Zones[i]
+		zs.PeakLimitedAmps = max(zs.PeakLimitedAmps, z.LimitedAmps)
+		if s.Seconds > 0 {
+			zs.MeanLimitedAmps += (z.LimitedAmps - zs.MeanLimitedAmps) * dt / s.Seconds
+		}
+		if z.Limiting {
+			zs.LimitingS += dt
+		}
+	}
+	for i, p := range r.Thermal {
+		if i == len(s.Panels) {
+			s.Panels = append(s.Panels, PanelSummary{Panel: p.Panel, MinScale: 1})
+		}
+		ps := &s.Panels[i]
+		ps.PeakAvgAmps = max(ps.PeakAvgAmps, p.AvgAmps)
+		ps.MinScale = min(ps.MinScale, p.Scale)
+		if p.Throttling {
+			ps.ThrottlingS += dt
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package power
+
+import (
+	"math"
+	"time"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/config"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+// Thermal limits sustained load per panel. The amp limits act on a single
+// frame; enclosed panels also heat up under a load that fits the supply but
+// lasts for minutes. Each panel's sent current is averaged over WindowS;
+// while the average is above PanelLimitAmps the panel's brightness is
+// lowered by RatePerS each second (down to MinScale), and it only recovers
+// once the average falls below PanelLimitAmps*(1-Hysteresis).
+type Thermal struct {
+	PanelLimitAmps float64 // sustained amps per panel; 0 = off
+	WindowS        float64 // averaging time constant; 0 = 60 s
+	Hysteresis     float64 // release band below the limit; 0 = 0.1
+	MinScale       float64 // lowest allowed brightness; 0 = 0.25
+	RatePerS       float64 // throttle/recover speed, fraction per second; 0 = 0.02
+}
+
+func thermalFromConfig(c config.ThermalCfg) Thermal {
+	return Thermal{PanelLimitAmps: c.PanelLimitAmps, WindowS: c.WindowS, Hysteresis: c.Hysteresis, MinScale: c.MinScale, RatePerS: c.RatePerS}
+}
+
+func (t Thermal) withDefaults() Thermal {
+	if t.WindowS <= 0 {
+		t.WindowS = 60
+	}
+	if t.Hysteresis <= 0 || t.Hysteresis >= 1 {
+		t.Hysteresis = 0.1
+	}
+	if t.MinScale <= 0 || t.MinScale > 1 {
+		t.MinScale = 0.25
+	}
+	if t.RatePerS <= 0 {
+		t.RatePerS = 0.02
+	}
+	return t
+}
+
+// PanelThermal is one panel's thermal state after the last frame.
+type PanelThermal struct {
+	Panel      int     `json:"panel"`
+	AvgAmps    float64 `json:"avg_a"`
+	Scale      float64 `json:"scale"`
+	Throttling bool    `json:"throttling"`
+}
+
+// thermalState carries the rolling averages between frames.
+type thermalState struct {
+	avg, scale []float64
+	throttling []bool
+	sent       []float64 // per-frame scratch, amps per panel
+	last       time.Time
+}
+
+func (s *thermalState) reset(panels int) {
+	s.avg = make([]float64, panels)
+	s.scale = make([]float64, panels)
+	s.throttling = make([]bool, panels)
+	s.sent = make([]float64, panels)
+	for i := range s.scale {
+		s.scale[i] = 1
+	}
+	s.last = time.Time{}
+}
+
+// apply dims each panel by its current thermal scale.
+func (s *thermalState) apply(buf []render.Color, perPanel int) {
+	if perPanel <= 0 {
+		return
+	}
+	for i := range buf {
+		p := i / perPanel
+		if p >= len(s.scale) {
+			return
+		}
+		if k := float32(s.scale[p]); k < 1 {
+			buf[i].R *= k
+			buf[i].G *= k
+			buf[i].B *= k
+		}
+	}
+}
+
+// update folds the amps each panel drew this frame (sent, filled by the
+// Manager) into the averages and steps each panel's scale.
+func (s *thermalState) update(cfg Thermal, now time.Time) {
+	dt := 0.0
+	if !s.last.IsZero() {
+		dt = math.Min(1, now.Sub(s.last).Seconds())
+	}
+	s.last = now
+	if dt <= 0 {
+		return
+	}
+	cfg = cfg.withDefaults()
+	alpha := 1 - math.Exp(-dt/cfg.WindowS)
+	release := cfg.PanelLimitAmps * (1 - cfg.Hysteresis)
+	for p := range s.avg {
+		s.avg[p] += (s.sent[p] - s.avg[p]) * alpha
+		switch {
+		case cfg.PanelLimitAmps <= 0:
+			s.scale[p], s.throttling[p] = 1, false
+		case s.avg[p] > cfg.PanelLimitAmps:
+			s.throttling[p] = true
+			s.scale[p] = math.Max(cfg.MinScale, s.scale[p]-cfg.RatePerS*dt)
+		case s.avg[p] < release && s.throttling[p]:
+			s.scale[p] = math.Min(1, s.scale[p]+cfg.RatePerS*dt)
+			if s.scale[p] >= 1 {
+				s.throttling[p] = false
+			}
+		}
+	}
+}
+
+func (s *thermalState) report(out []PanelThermal) []PanelThermal {
+	for p := range s.avg {
+		out = append(out, PanelThermal{Panel: p, AvgAmps: s.avg[p], Scale: s.scale[p], Throttling: s.throttling[p]})
+	}
+	return out
+}
@@ -0,0 +1,238 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WritePolicy decides what AsyncDriver.Write does while the previous frame
+// is still queued behind the one being transmitted.
+type WritePolicy string
+
+const (
+	// WriteDrop replaces the queued frame with the new one, so the device
+	// always gets the freshest frame and rendering never waits.
+	WriteDrop WritePolicy = "drop"
+	// WriteBlock waits until the queued frame is taken, so every frame is
+	// shown and rendering is paced by the device.
+	WriteBlock WritePolicy = "block"
+	// WriteSync writes inline from the render loop (see WithWritePolicy).
+	WriteSync WritePolicy = "sync"
+)
+
+// ParseWritePolicy parses "drop", "block" or "sync" ("" = drop).
+func ParseWritePolicy(s string) (WritePolicy, error) {
+	switch p := WritePolicy(s); p {
+	case "":
+		return WriteDrop, nil
+	case WriteDrop, WriteBlock, WriteSync:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown write policy %q (want drop, block or sync)", s)
+}
+
+// WriterStats are an AsyncDriver's counters and latencies.
+type WriterStats struct {
+	Policy  WritePolicy `json:"policy"`
+	Frames  uint64      `json:"frames"`  // written to the device
+	Dropped uint64      `json:"dropped"` // replaced while queued (drop policy)
+	Blocked uint64      `json:"blocked"` // Write calls that had to wait (block policy)
+	Errors  uint64      `json:"errors"`
+	LastMS  float64     `json:"lastMs"`  // device write time of the last frame
+	AvgMS   float64     `json:"avgMs"`   // moving average of the above
+	MaxMS   float64     `json:"maxMs"`   // worst since start
+	QueueMS float64     `json:"queueMs"` // last frame's wait between Write and the writer
+	Err     string      `json:"error,omitempty"`
+}
+
+// AsyncDriver writes frames to a Driver from its own goroutine, so the
+// next frame renders while the previous one is on the wire. It keeps two
+// preallocated buffers: one in flight and one queued. Write copies the frame
+// into the free one and returns; a device error surfaces on the next Write
+// and in Stats.
+type AsyncDriver struct {
+	drv    Driver
+	policy WritePolicy
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	bufs     [2][]Color
+	queued   int // index of the queued buffer, -1 = none
+	inflight int // index of the buffer being written, -1 = none
+	at       time.Time
+	running  bool
+	closing  bool
+	done     chan struct{}
+	err      error
+	stats    WriterStats
+}
+
+// WithWritePolicy returns drv itself for WriteSync, otherwise drv behind an
+// AsyncDriver with the policy.
+func WithWritePolicy(drv Driver, policy WritePolicy) Driver {
+	if policy == WriteSync {
+		return drv
+	}
+	return NewAsyncDriver(drv, policy)
+}
+
+// NewAsyncDriver wraps drv and starts its writer ("" and WriteSync mean
+// WriteDrop). Open and Close go to drv; Close stops the writer after the
+// queued frame, Open restarts it.
+func NewAsyncDriver(drv Driver, policy WritePolicy) *AsyncDriver {
+	if policy == "" || policy == WriteSync {
+		policy = WriteDrop
+	}
+	a := &AsyncDriver{drv: drv, policy: policy, queued: -1, inflight: -1}
+	a.cond = sync.NewCond(&a.mu)
+	a.stats.Policy = policy
+	a.mu.Lock()
+	a.startLocked()
+	a.mu.Unlock()
+	return a
+}
+
+// Unwrap returns the wrapped driver.
+func (a *AsyncDriver) Unwrap() Driver { return a.drv }
+
+func (a *AsyncDriver) Caps() Caps { return a.drv.Caps() }
+
+func (a *AsyncDriver) Open() error {
+	a.mu.Lock()
+	a.startLocked()
+	a.mu.Unlock()
+	return a.drv.Open()
+}
+
+func (a *AsyncDriver) startLocked() {
+	if a.running {
+		return
+	}
+	a.running, a.closing = true, false
+	a.done = make(chan struct{})
+	go a.run(a.done)
+}
+
+// Write queues a copy of frame for the writer.
+func (a *AsyncDriver) Write(frame []Color) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.running {
+		return errors.New("async driver closed")
+	}
+	if a.policy == WriteBlock && a.queued >= 0 {
+		a.stats.Blocked++
+		for a.queued >= 0 && a.running {
+			a.cond.Wait()
+		}
+		if !a.running {
+			return errors.New("async driver closed")
+		}
+	}
+	i := a.queued
+	if i >= 0 {
+		a.stats.Dropped++
+	} else {
+		i = 0
+		if a.inflight == 0 {
+			i = 1
+		}
+	}
+	if cap(a.bufs[i]) < len(frame) {
+		a.bufs[i] = make([]Color, len(frame))
+	}
+	a.bufs[i] = a.bufs[i][:len(frame)]
+	copy(a.bufs[i], frame)
+	a.queued, a.at = i, time.Now()
+	a.cond.Broadcast()
+	err := a.err
+	a.err = nil
+	return err
+}
+
+func (a *AsyncDriver) run(done chan struct{}) {
+	defer close(done)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for {
+		for a.queued < 0 && !a.closing {
+			a.cond.Wait()
+		}
+		if a.queued < 0 {
+			a.running = false
+			a.cond.Broadcast()
+			return
+		}
+		i := a.queued
+		a.queued, a.inflight = -1, i
+		a.stats.QueueMS = ms(time.Since(a.at))
+		a.cond.Broadcast()
+		a.mu.Unlock()
+
+		t0 := time.Now()
+		err := a.drv.Write(a.bufs[i])
+		d := ms(time.Since(t0))
+
+		a.mu.Lock()
+		a.inflight = -1
+		a.stats.Frames++
+		a.stats.LastMS = d
+		if a.stats.Frames == 1 {
+			a.stats.AvgMS = d
+		} else {
+			a.stats.AvgMS += (d - a.stats.AvgMS) * 0.1
+		}
+		if d > a.stats.MaxMS {
+			a.stats.MaxMS = d
+		}
+		if err != nil {
+			a.stats.Errors++
+			a.stats.Err = err.Error()
+			a.err = err
+		} else {
+			a.stats.Err = ""
+		}
+		a.cond.Broadcast()
+	}
+}
+
+// Flush waits until every queued frame has been written.
+func (a *AsyncDriver) Flush() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for (a.queued >= 0 || a.inflight >= 0) && a.running {
+		a.cond.Wait()
+	}
+}
+
+// Blank flushes the queue and blanks the device.
+func (a *AsyncDriver) Blank() error {
+	a.Flush()
+	return a.drv.Blank()
+}
+
+// Close writes the queued frame, stops the writer and closes the device.
+func (a *AsyncDriver) Close() error {
+	a.mu.Lock()
+	done := a.done
+	if a.running {
+		a.closing = true
+		a.cond.Broadcast()
+	}
+	a.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+	return a.drv.Close()
+}
+
+// Stats returns the writer's counters.
+func (a *AsyncDriver) Stats() WriterStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.stats
+}
+
+func ms(d time.Duration) float64 { return float64(d.Microseconds()) / 1000.0 }
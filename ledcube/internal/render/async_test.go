@@ -0,0 +1,121 @@
+package render
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gateDriver holds each Write until the test releases it.
+type gateDriver struct {
+	fakeDriver
+	mu      sync.Mutex
+	gate    chan struct{}
+	entered chan struct{}
+	got     []float32 // R of every frame written
+	fail    error
+}
+
+func newGateDriver() *gateDriver {
+	return &gateDriver{gate: make(chan struct{}), entered: make(chan struct{}, 8)}
+}
+
+func (d *gateDriver) Write(buf []Color) error {
+	d.entered <- struct{}{}
+	<-d.gate
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.got = append(d.got, buf[0].R)
+	return d.fail
+}
+
+func (d *gateDriver) frames() []float32 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]float32(nil), d.got...)
+}
+
+func frameOf(r float32) []Color { return []Color{{R: r}} }
+
+func TestAsyncDriverDropKeepsNewest(t *testing.T) {
+	dev := newGateDriver()
+	a := NewAsyncDriver(dev, WriteDrop)
+	_ = a.Write(frameOf(1))
+	<-dev.entered // frame 1 is on the wire
+	for _, r := range []float32{2, 3, 4} {
+		start := time.Now()
+		if err := a.Write(frameOf(r)); err != nil {
+			t.Fatal(err)
+		}
+		if time.Since(start) > 100*time.Millisecond {
+			t.Fatal("drop policy blocked the caller")
+		}
+	}
+	close(dev.gate)
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := dev.frames(); len(got) != 2 || got[0] != 1 || got[1] != 4 {
+		t.Fatalf("written %v, want [1 4]", got)
+	}
+	st := a.Stats()
+	if st.Frames != 2 || st.Dropped != 2 || st.Policy != WriteDrop {
+		t.Fatalf("stats %+v", st)
+	}
+	if err := a.Write(frameOf(5)); err == nil {
+		t.Fatal("write after close accepted")
+	}
+}
+
+func TestAsyncDriverBlockWritesEveryFrame(t *testing.T) {
+	dev := newGateDriver()
+	a := NewAsyncDriver(dev, WriteBlock)
+	_ = a.Write(frameOf(1))
+	<-dev.entered
+	_ = a.Write(frameOf(2)) // queued behind 1
+	third := make(chan struct{})
+	go func() {
+		_ = a.Write(frameOf(3)) // waits for 2 to be taken
+		close(third)
+	}()
+	select {
+	case <-third:
+		t.Fatal("block policy did not wait")
+	case <-time.After(20 * time.Millisecond):
+	}
+	dev.gate <- struct{}{} // finish 1; the writer takes 2
+	<-third
+	close(dev.gate)
+	a.Flush()
+	if got := dev.frames(); len(got) != 3 || got[2] != 3 {
+		t.Fatalf("written %v, want [1 2 3]", got)
+	}
+	if st := a.Stats(); st.Blocked != 1 || st.Dropped != 0 {
+		t.Fatalf("stats %+v", st)
+	}
+	_ = a.Close()
+}
+
+func TestAsyncDriverReportsErrors(t *testing.T) {
+	dev := newGateDriver()
+	dev.fail = errors.New("unplugged")
+	close(dev.gate)
+	a := NewAsyncDriver(dev, WriteDrop)
+	defer a.Close()
+	_ = a.Write(frameOf(1))
+	a.Flush()
+	if err := a.Write(frameOf(2)); err == nil || err.Error() != "unplugged" {
+		t.Fatalf("next write returned %v", err)
+	}
+	a.Flush()
+	if st := a.Stats(); st.Errors != 2 || st.Err != "unplugged" {
+		t.Fatalf("stats %+v", st)
+	}
+	if _, ok := WithWritePolicy(dev, WriteSync).(*gateDriver); !ok {
+		t.Fatal("sync policy wrapped the driver")
+	}
+	if _, err := ParseWritePolicy("later"); err == nil {
+		t.Fatal("bad policy accepted")
+	}
+}
@@ -0,0 +1,132 @@
+package render
+
+// BlendMode selects how a layer composites onto the layers beneath it.
+type BlendMode string
+
+const (
+	BlendAlpha    BlendMode = "alpha"    // alpha-over: lerp(dst, src, opacity)
+	BlendAdd      BlendMode = "add"      // dst + src*opacity
+	BlendScreen   BlendMode = "screen"   // 1-(1-dst)(1-src), mixed by opacity
+	BlendMultiply BlendMode = "multiply" // dst*src, mixed by opacity
+	BlendMax      BlendMode = "max"      // per-channel max, mixed by opacity
+)
+
+// BlendModes lists the supported modes in UI order.
+func BlendModes() []BlendMode {
+	return []BlendMode{BlendAlpha, BlendAdd, BlendScreen, BlendMultiply, BlendMax}
+}
+
+// ParseBlendMode maps a name to a BlendMode; "" and unknown names fall back to alpha-over.
+func ParseBlendMode(s string) (BlendMode, bool) {
+	for _, m := range BlendModes() {
+		if string(m) == s {
+			return m, true
+		}
+	}
+	return BlendAlpha, s == ""
+}
+
+// Blend composites src onto dst in place. Channels are linear; no clamping is
+// done here so the post pipeline sees the true summed energy.
+func Blend(dst, src []Color, mode BlendMode, opacity float64) {
+	if opacity <= 0 {
+		return
+	}
+	if opacity > 1 {
+		opacity = 1
+	}
+	o := float32(opacity)
+	n := len(dst)
+	if len(src) < n {
+		n = len(src)
+	}
+	switch mode {
+	case BlendAdd:
+		for i := 0; i < n; i++ {
+			dst[i].R += src[i].R * o
+			dst[i].G += src[i].G * o
+			dst[i].B += src[i].B * o
+		}
+	case BlendScreen:
+		for i := 0; i < n; i++ {
+			dst[i] = lerpColor(dst[i], Color{
+				R: dst[i].R + src[i].R - dst[i].R*src[i].R,
+				G: dst[i].G + src[i].G - dst[i].G*src[i].G,
+				B: dst[i].B + src[i].B - dst[i].B*src[i].B,
+			}, o)
+		}
+	case BlendMultiply:
+		for i := 0; i < n; i++ {
+			dst[i] = lerpColor(dst[i], Color{
+				R: dst[i].R * src[i].R,
+				G: dst[i].G * src[i].G,
+				B: dst[i].B * src[i].B,
+			}, o)
+		}
+	case BlendMax:
+		for i := 0; i < n; i++ {
+			dst[i] = lerpColor(dst[i], Color{
+				R: maxf(dst[i].R, src[i].R),
+				G: maxf(dst[i].G, src[i].G),
+				B: maxf(dst[i].B, src[i].B),
+			}, o)
+		}
+	default: // BlendAlpha
+		if o >= 1 {
+			copy(dst[:n], src[:n])
+			return
+		}
+		for i := 0; i < n; i++ {
+			dst[i] = lerpColor(dst[i], src[i], o)
+		}
+	}
+}
+
+// BlendMask composites src onto dst with a per-voxel opacity mask (0..1),
+// as produced by Transition.Mask.
+func BlendMask(dst, src []Color, mode BlendMode, mask []float32) {
+	n := len(dst)
+	if len(src) < n {
+		n = len(src)
+	}
+	if len(mask) < n {
+		n = len(mask)
+	}
+	for i := 0; i < n; i++ {
+		o := mask[i]
+		if o <= 0 {
+			continue
+		}
+		if o > 1 {
+			o = 1
+		}
+		d, s := dst[i], src[i]
+		switch mode {
+		case BlendAdd:
+			dst[i] = Color{R: d.R + s.R*o, G: d.G + s.G*o, B: d.B + s.B*o}
+		case BlendScreen:
+			dst[i] = lerpColor(d, Color{R: d.R + s.R - d.R*s.R, G: d.G + s.G - d.G*s.G, B: d.B + s.B - d.B*s.B}, o)
+		case BlendMultiply:
+			dst[i] = lerpColor(d, Color{R: d.R * s.R, G: d.G * s.G, B: d.B * s.B}, o)
+		case BlendMax:
+			dst[i] = lerpColor(d, Color{R: maxf(d.R, s.R), G: maxf(d.G, s.G), B: maxf(d.B, s.B)}, o)
+		default:
+			dst[i] = lerpColor(d, s, o)
+		}
+	}
+}
+
+func lerpColor(a, b Color, t float32) Color {
+	return Color{
+		R: a.R + (b.R-a.R)*t,
+		G: a.G + (b.G-a.G)*t,
+		B: a.B + (b.B-a.B)*t,
+	}
+}
+
+func maxf(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,205 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// CalibEntry corrects one LED's drive values: out = (Matrix · rgb) ^ Gamma,
+// per channel. A zero Matrix means identity and a zero Gamma means 1, so a
+// partially filled entry only changes what it sets.
+type CalibEntry struct {
+	Matrix [9]float64 `json:"matrix"` // row-major, rows are output R, G, B
+	Gamma  [3]float64 `json:"gamma"`
+}
+
+// CalibProfile is a calibration at three levels of detail; the most specific
+// one wins for each LED: LEDs (by output index), then Panels (by Z index),
+// then Global.
+type CalibProfile struct {
+	Global *CalibEntry        `json:"global,omitempty"`
+	Panels map[int]CalibEntry `json:"panels,omitempty"`
+	LEDs   map[int]CalibEntry `json:"leds,omitempty"`
+}
+
+// Calibration is a CalibProfile compiled for one set of dimensions.
+type Calibration struct {
+	profile CalibProfile
+	ops     []calibOp
+	per     []int32 // LED → ops index, -1 = untouched
+}
+
+type calibOp struct {
+	m        mat3
+	identity bool
+	g        [3]float64
+	linear   bool // all gammas 1
+}
+
+// NewCalibration validates p against dim and compiles the per-LED lookup.
+// LEDs are indexed in output order (x fastest, then y, then z).
+func NewCalibration(p CalibProfile, dim Dimensions) (*Calibration, error) {
+	n := dim.X * dim.Y * dim.Z
+	c := &Calibration{profile: copyProfile(p), per: make([]int32, n)}
+	add := func(where string, e CalibEntry) (int32, error) {
+		op, err := compileCalib(e)
+		if err != nil {
+			return 0, fmt.Errorf("calibration %s: %w", where, err)
+		}
+		c.ops = append(c.ops, op)
+		return int32(len(c.ops) - 1), nil
+	}
+	global := int32(-1)
+	if p.Global != nil {
+		i, err := add("global", *p.Global)
+		if err != nil {
+			return nil, err
+		}
+		global = i
+	}
+	for i := range c.per {
+		c.per[i] = global
+	}
+	perPanel := dim.X * dim.Y
+	for z, e := range p.Panels {
+		if z < 0 || z >= dim.Z {
+			return nil, fmt.Errorf("calibration panel %d: out of range [0,%d)", z, dim.Z)
+		}
+		i, err := add(fmt.Sprintf("panel %d", z), e)
+		if err != nil {
+			return nil, err
+		}
+		for k := z * perPanel; k < (z+1)*perPanel; k++ {
+			c.per[k] = i
+		}
+	}
+	for led, e := range p.LEDs {
+		if led < 0 || led >= n {
+			return nil, fmt.Errorf("calibration led %d: out of range [0,%d)", led, n)
+		}
+		i, err := add(fmt.Sprintf("led %d", led), e)
+		if err != nil {
+			return nil, err
+		}
+		c.per[led] = i
+	}
+	return c, nil
+}
+
+func compileCalib(e CalibEntry) (calibOp, error) {
+	op := calibOp{identity: e.Matrix == [9]float64{}, linear: true}
+	if op.identity {
+		op.m = mat3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	} else {
+		for i, v := range e.Matrix {
+			if math.IsNaN(v) || math.IsInf(v, 0) || v < -4 || v > 4 {
+				return op, fmt.Errorf("matrix[%d] = %v, want within [-4, 4]", i, v)
+			}
+			op.m[i/3][i%3] = float32(v)
+		}
+	}
+	for ch, g := range e.Gamma {
+		if g == 0 {
+			g = 1
+		}
+		if math.IsNaN(g) || g < 0.2 || g > 5 {
+			return op, fmt.Errorf("gamma[%d] = %v, want within [0.2, 5]", ch, g)
+		}
+		op.g[ch] = g
+		if g != 1 {
+			op.linear = false
+		}
+	}
+	return op, nil
+}
+
+// Profile returns a copy of the profile c was built from.
+func (c *Calibration) Profile() CalibProfile {
+	return copyProfile(c.profile)
+}
+
+// Apply corrects buf in place and clamps it to [0,1]. LEDs beyond the
+// compiled size are left alone.
+func (c *Calibration) Apply(buf []Color) {
+	for i := range buf {
+		if i >= len(c.per) {
+			return
+		}
+		k := c.per[i]
+		if k < 0 {
+			continue
+		}
+		op := &c.ops[k]
+		v := buf[i]
+		if !op.identity {
+			v = op.m.apply(v)
+		}
+		v = Color{R: clamp01(v.R), G: clamp01(v.G), B: clamp01(v.B)}
+		if !op.linear {
+			v = Color{R: powf(v.R, op.g[0]), G: powf(v.G, op.g[1]), B: powf(v.B, op.g[2])}
+		}
+		buf[i] = v
+	}
+}
+
+// SetCalibration installs a calibration profile as the final output stage,
+// after the post chain (nil removes it). Its time is reported in Last.Post
+// as "calibration".
+func (e *Engine) SetCalibration(p *CalibProfile) error {
+	if p == nil {
+		e.mu.Lock()
+		e.calib = nil
+		e.mu.Unlock()
+		return nil
+	}
+	c, err := NewCalibration(*p, e.Dim)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	e.calib = c
+	e.mu.Unlock()
+	return nil
+}
+
+// Calibration returns the active profile, or nil.
+func (e *Engine) Calibration() *CalibProfile {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.calib == nil {
+		return nil
+	}
+	p := e.calib.Profile()
+	return &p
+}
+
+func (c *Calibration) run(buf []Color, timing []PostTiming) []PostTiming {
+	if c == nil {
+		return timing
+	}
+	t0 := time.Now()
+	c.Apply(buf)
+	return append(timing, PostTiming{Stage: "calibration", MS: float64(time.Since(t0).Microseconds()) / 1000.0})
+}
+
+func copyProfile(p CalibProfile) CalibProfile {
+	out := CalibProfile{}
+	if p.Global != nil {
+		g := *p.Global
+		out.Global = &g
+	}
+	if p.Panels != nil {
+		out.Panels = make(map[int]CalibEntry, len(p.Panels))
+		for k, v := range p.Panels {
+			out.Panels[k] = v
+		}
+	}
+	if p.LEDs != nil {
+		out.LEDs = make(map[int]CalibEntry, len(p.LEDs))
+		for k, v := range p.LEDs {
+			out.LEDs[k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+package render
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalibrationPrecedence(t *testing.T) {
+	dim := Dimensions{X: 2, Y: 1, Z: 2} // LEDs 0,1 on panel 0; 2,3 on panel 1
+	half := CalibEntry{Matrix: [9]float64{0.5, 0, 0, 0, 0.5, 0, 0, 0, 0.5}}
+	swap := CalibEntry{Matrix: [9]float64{0, 1, 0, 1, 0, 0, 0, 0, 1}}
+	sq := CalibEntry{Gamma: [3]float64{2, 0, 0}}
+	c, err := NewCalibration(CalibProfile{
+		Global: &half,
+		Panels: map[int]CalibEntry{1: swap},
+		LEDs:   map[int]CalibEntry{3: sq},
+	}, dim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := []Color{{0.8, 0.4, 1}, {0.8, 0.4, 1}, {0.8, 0.4, 1}, {0.5, 0.4, 1}}
+	c.Apply(buf)
+	want := []Color{{0.4, 0.2, 0.5}, {0.4, 0.2, 0.5}, {0.4, 0.8, 1}, {0.25, 0.4, 1}}
+	for i := range want {
+		if math.Abs(float64(buf[i].R-want[i].R))+math.Abs(float64(buf[i].G-want[i].G))+math.Abs(float64(buf[i].B-want[i].B)) > 1e-5 {
+			t.Fatalf("led %d = %+v, want %+v", i, buf[i], want[i])
+		}
+	}
+}
+
+func TestCalibrationValidation(t *testing.T) {
+	dim := Dimensions{X: 2, Y: 2, Z: 2}
+	for name, p := range map[string]CalibProfile{
+		"panel range": {Panels: map[int]CalibEntry{2: {}}},
+		"led range":   {LEDs: map[int]CalibEntry{8: {}}},
+		"gamma":       {Global: &CalibEntry{Gamma: [3]float64{1, 9, 1}}},
+		"matrix":      {Global: &CalibEntry{Matrix: [9]float64{math.NaN()}}},
+	} {
+		if _, err := NewCalibration(p, dim); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestEngineCalibrationIsFinalStage(t *testing.T) {
+	drv := &fakeDriver{}
+	e, err := NewEngine(Dimensions{X: 1, Y: 1, Z: 1}, []Vec3{{}}, drv, &fakeRenderer{name: "A", r: 1, g: 1, b: 1}, nil, &Resources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = e.SetPostChain([]PostStageConfig{{Stage: "clamp"}})
+	if err := e.SetCalibration(&CalibProfile{Global: &CalibEntry{Matrix: [9]float64{0.9, 0, 0, 0, 0.8, 0, 0, 0, 1}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.RenderOnce(0); err != nil {
+		t.Fatal(err)
+	}
+	if c := drv.last[0]; math.Abs(float64(c.R-0.9)) > 1e-6 || math.Abs(float64(c.G-0.8)) > 1e-6 || c.B != 1 {
+		t.Fatalf("calibrated white = %+v", c)
+	}
+	if n := len(e.Last.Post); n != 2 || e.Last.Post[n-1].Stage != "calibration" {
+		t.Fatalf("Last.Post = %+v", e.Last.Post)
+	}
+	_ = e.SetCalibration(nil)
+	if e.Calibration() != nil {
+		t.Fatal("calibration should be cleared")
+	}
+}
@@ -0,0 +1,192 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// CurrentModel is the electrical model of one LED type. A lit channel draws
+// ChanMilliamps[ch] * value^Exponent on top of the IdleMilliamps every LED
+// draws, lit or not. Values are clamped to [0,1] first; an Exponent of 0
+// means 1 (linear). RGBW parts set WhiteMilliamps: colors are then split by
+// White, as the driver does, and the white die draws
+// WhiteMilliamps * w^Exponent.
+type CurrentModel struct {
+	Name           string     `json:"name"`
+	ChanMilliamps  [3]float64 `json:"chan_ma"`        // full-scale current of R, G, B
+	WhiteMilliamps float64    `json:"w_ma,omitempty"` // full-scale current of W; 0 = RGB part
+	IdleMilliamps  float64    `json:"idle_ma"`        // quiescent draw per LED
+	Exponent       float64    `json:"exponent"`
+	White          White      `json:"white"` // RGBW split (see SetWhite)
+}
+
+// LinearModel is the legacy estimate: chanmA per channel at full scale, no
+// idle draw (≤0 = 20 mA).
+func LinearModel(chanmA float64) CurrentModel {
+	if chanmA <= 0 {
+		chanmA = 20
+	}
+	return CurrentModel{Name: "linear", ChanMilliamps: [3]float64{chanmA, chanmA, chanmA}, Exponent: 1}
+}
+
+// IsZero reports whether m is unset.
+func (m CurrentModel) IsZero() bool {
+	return m.ChanMilliamps == [3]float64{} && m.WhiteMilliamps == 0 && m.IdleMilliamps == 0
+}
+
+// Validate checks the model's numbers are usable.
+func (m CurrentModel) Validate() error {
+	for ch, v := range m.ChanMilliamps {
+		if math.IsNaN(v) || v < 0 || v > 200 {
+			return fmt.Errorf("strip %q: chan_ma[%d] = %v, want within [0, 200]", m.Name, ch, v)
+		}
+	}
+	if math.IsNaN(m.WhiteMilliamps) || m.WhiteMilliamps < 0 || m.WhiteMilliamps > 200 {
+		return fmt.Errorf("strip %q: w_ma = %v, want within [0, 200]", m.Name, m.WhiteMilliamps)
+	}
+	if math.IsNaN(m.IdleMilliamps) || m.IdleMilliamps < 0 || m.IdleMilliamps > 20 {
+		return fmt.Errorf("strip %q: idle_ma = %v, want within [0, 20]", m.Name, m.IdleMilliamps)
+	}
+	if math.IsNaN(m.Exponent) || m.Exponent < 0 || (m.Exponent > 0 && (m.Exponent < 0.25 || m.Exponent > 4)) {
+		return fmt.Errorf("strip %q: exponent = %v, want within [0.25, 4]", m.Name, m.Exponent)
+	}
+	return m.White.Validate()
+}
+
+// SetWhite returns m estimating with the RGBW split w; RGB parts are
+// unaffected.
+func (m CurrentModel) SetWhite(w White) CurrentModel {
+	m.White = w
+	return m
+}
+
+func (m CurrentModel) exp() float64 {
+	if m.Exponent <= 0 {
+		return 1
+	}
+	return m.Exponent
+}
+
+// DriveMilliamps is c's current above idle.
+func (m CurrentModel) DriveMilliamps(c Color) float64 {
+	var w float64
+	if m.WhiteMilliamps > 0 {
+		var wf float32
+		c, wf = m.White.Split(c)
+		w = float64(wf)
+	}
+	r, g, b := float64(clamp01(c.R)), float64(clamp01(c.G)), float64(clamp01(c.B))
+	if e := m.exp(); e != 1 {
+		r, g, b, w = math.Pow(r, e), math.Pow(g, e), math.Pow(b, e), math.Pow(w, e)
+	}
+	return m.ChanMilliamps[0]*r + m.ChanMilliamps[1]*g + m.ChanMilliamps[2]*b + m.WhiteMilliamps*w
+}
+
+// LEDMilliamps is c's total current, idle included.
+func (m CurrentModel) LEDMilliamps(c Color) float64 { return m.IdleMilliamps + m.DriveMilliamps(c) }
+
+// FrameMilliamps returns buf's idle and drive current.
+func (m CurrentModel) FrameMilliamps(buf []Color) (idle, drive float64) {
+	for _, c := range buf {
+		drive += m.DriveMilliamps(c)
+	}
+	return m.IdleMilliamps * float64(len(buf)), drive
+}
+
+// Gain returns how drive current scales when every value is multiplied by k.
+func (m CurrentModel) Gain(k float64) float64 {
+	if k <= 0 {
+		return 0
+	}
+	if e := m.exp(); e != 1 {
+		return math.Pow(k, e)
+	}
+	return k
+}
+
+// ScaleFor returns the brightness factor in [0,1] that brings drive current
+// down to target (the inverse of Gain).
+func (m CurrentModel) ScaleFor(drive, target float64) float64 {
+	switch {
+	case drive <= 0 || target >= drive:
+		return 1
+	case target <= 0:
+		return 0
+	}
+	r := target / drive
+	if e := m.exp(); e != 1 {
+		return math.Pow(r, 1/e)
+	}
+	return r
+}
+
+var (
+	stripMu sync.RWMutex
+	strips  = map[string]CurrentModel{}
+)
+
+// DefaultStrip is the model used when the config names none.
+const DefaultStrip = "ws2812b"
+
+func init() {
+	for _, m := range []CurrentModel{
+		LinearModel(20),
+		{Name: "ws2812b", ChanMilliamps: [3]float64{13, 12, 12}, IdleMilliamps: 1, Exponent: 1},
+		{Name: "ws2812", ChanMilliamps: [3]float64{17, 17, 17}, IdleMilliamps: 1, Exponent: 1},
+		{Name: "sk6812", ChanMilliamps: [3]float64{12, 12, 12}, IdleMilliamps: 0.8, Exponent: 1},
+		{Name: "sk6812rgbw", ChanMilliamps: [3]float64{12, 12, 12}, WhiteMilliamps: 16, IdleMilliamps: 1, Exponent: 1},
+	} {
+		if err := RegisterStrip(m); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterStrip adds (or replaces) a named LED model.
+func RegisterStrip(m CurrentModel) error {
+	if m.Name == "" {
+		return fmt.Errorf("strip model needs a name")
+	}
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	stripMu.Lock()
+	strips[m.Name] = m
+	stripMu.Unlock()
+	return nil
+}
+
+// StripModel looks up a registered model by name.
+func StripModel(name string) (CurrentModel, bool) {
+	stripMu.RLock()
+	defer stripMu.RUnlock()
+	m, ok := strips[name]
+	return m, ok
+}
+
+// Strips lists the registered models by name.
+func Strips() []CurrentModel {
+	stripMu.RLock()
+	defer stripMu.RUnlock()
+	out := make([]CurrentModel, 0, len(strips))
+	for _, m := range strips {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// SetCurrentModel sets the LED model the "limiter" post stage estimates
+// with; nil falls back to a linear model at the LEDChan_mA param.
+func (e *Engine) SetCurrentModel(m *CurrentModel) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if m == nil {
+		e.model = nil
+		return
+	}
+	cp := *m
+	e.model = &cp
+}
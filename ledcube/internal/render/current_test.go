@@ -0,0 +1,36 @@
+package render
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCurrentModel(t *testing.T) {
+	m := CurrentModel{Name: "t", ChanMilliamps: [3]float64{10, 20, 30}, IdleMilliamps: 1, Exponent: 2}
+	if got := m.LEDMilliamps(Color{R: 1, G: 0.5, B: 0}); math.Abs(got-16) > 1e-9 { // 1 + 10 + 20·0.25
+		t.Fatalf("LEDMilliamps = %v", got)
+	}
+	if got := m.LEDMilliamps(Color{R: 2, G: -1}); got != 11 {
+		t.Fatalf("values should clamp: %v", got)
+	}
+	// halving the drive current takes 1/√2 brightness on a square law
+	if k := m.ScaleFor(100, 50); math.Abs(k-math.Sqrt(0.5)) > 1e-9 || math.Abs(m.Gain(k)-0.5) > 1e-9 {
+		t.Fatalf("ScaleFor = %v", k)
+	}
+	if _, ok := StripModel(DefaultStrip); !ok {
+		t.Fatalf("default strip %q not registered", DefaultStrip)
+	}
+	if err := RegisterStrip(CurrentModel{Name: "bad", IdleMilliamps: -1}); err == nil {
+		t.Fatal("negative idle accepted")
+	}
+}
+
+func TestLimiterBudgetIncludesIdle(t *testing.T) {
+	buf := []Color{{R: 1, G: 1, B: 1}, {}}
+	m := CurrentModel{Name: "t", ChanMilliamps: [3]float64{20, 20, 20}, IdleMilliamps: 5, Exponent: 1}
+	limit(buf, 3, m, 40, 0.9) // 70 mA estimated; only the 60 mA drive can be scaled
+	idle, drive := m.FrameMilliamps(buf)
+	if math.Abs(idle+drive-40) > 1e-4 {
+		t.Fatalf("after limit = %v mA, want 40", idle+drive)
+	}
+}
@@ -0,0 +1,42 @@
+package render
+
+// ChannelLayout is the set of color channels a pixel carries on the wire.
+type ChannelLayout string
+
+const (
+	LayoutRGB  ChannelLayout = "rgb"
+	LayoutRGBW ChannelLayout = "rgbw"
+)
+
+// Channels returns the number of channels per pixel.
+func (l ChannelLayout) Channels() int {
+	if l == LayoutRGBW {
+		return 4
+	}
+	return 3
+}
+
+// Caps describes what a driver can show. Zero fields mean "no constraint"
+// (Pixels 0 = any frame size, MaxFPS 0 = unbounded).
+type Caps struct {
+	Pixels   int           `json:"pixels"`
+	Layout   ChannelLayout `json:"layout"`
+	BitDepth int           `json:"bitDepth"` // per channel
+	MaxFPS   float64       `json:"maxFps"`   // what the transport can sustain
+	Order    string        `json:"order"`    // wire channel order, e.g. "GRB", "GRBW"
+}
+
+// Driver is an LED output: SPI, PWM, the UI preview, a recorder or a network
+// sender. Frames are linear [0,1] colors in output (LUT) order; byte-oriented
+// drivers quantize them with the shared led adapters.
+//
+// Lifecycle: Open acquires the device, Write sends one frame, Blank sends an
+// all-off frame (e.g. before Close or on blackout) and Close releases the
+// device. Open and Close are idempotent.
+type Driver interface {
+	Caps() Caps
+	Open() error
+	Write(frame []Color) error
+	Blank() error
+	Close() error
+}
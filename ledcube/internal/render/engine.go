@@ -6,13 +6,47 @@ import (
 	"time"
 )
 
-// Driver abstracts the LED transport (SPI, etc.).
-type Driver interface {
-	Write([]Color) error
+// Names of the built-in scene layers.
+const (
+	SceneLayer = "scene"      // bottom layer: the active renderer
+	NextLayer  = "scene.next" // incoming renderer while a crossfade is armed
+)
+
+// Layer is one renderer in the compositor stack with its own uniforms,
+// opacity (0..1) and blend mode.
+type Layer struct {
+	Name    string
+	R       Renderer
+	Preset  string
+	U       *Uniforms
+	Opacity float64
+	Blend   BlendMode
+	// Trans shapes how Opacity is applied across the volume; nil is a uniform fade.
+	Trans *Transition
+
+	buf  []Color
+	mask []float32
+	view *Uniforms // U over global as of the last snapshot; refilled in place
+}
+
+// LayerInfo is a read-only view of a layer for UIs and health endpoints.
+type LayerInfo struct {
+	Name     string    `json:"name"`
+	Renderer string    `json:"renderer"`
+	Preset   string    `json:"preset,omitempty"`
+	Opacity  float64   `json:"opacity"`
+	Blend    BlendMode `json:"blend"`
 }
 
-// Engine renders frames using an active Renderer, optional next Renderer for crossfades,
-// applies post-processing, then writes to the driver.
+// Engine renders a stack of layers bottom→top onto black, applies
+// post-processing once to the composited frame, then writes to the driver.
+// Extra outputs (AddOutput) each post-process their own copy of the
+// composite.
+//
+// Layer 0 is the scene. A crossfade is a special case of layer opacity: ArmNext
+// inserts the incoming renderer directly above the scene (alpha-over, opacity 0),
+// SetCrossfade drives its opacity, and at 1.0 it is promoted to be the scene.
+// Overlays added with AddLayer stay on top throughout.
 type Engine struct {
 	Dim  Dimensions
 	LUT  []Vec3
@@ -20,42 +54,81 @@ type Engine struct {
 	Rsrc *Resources
 	mu   sync.RWMutex
 
-	// active + next renderer and uniforms
-	RActive Renderer
-	RNext   Renderer
-	UActive *Uniforms
-	UNext   *Uniforms
+	layers []*Layer
+	global *Uniforms   // ScopeGlobal: inherited by every layer and by post
+	postU  *Uniforms   // ScopePost: tone map / limiter knobs
+	postV  *Uniforms   // postU over global as of the last snapshot
+	next   *Layer      // armed incoming scene, also present in layers
+	trans  *Transition // applied to the next armed scene (nil = fade)
+
+	// instances taken off the stack; released after the next frame renders,
+	// once the render goroutine can no longer be using them
+	retired []Renderer
 
-	// framebuffers
-	BufA []Color // active
-	BufB []Color // next (during crossfade)
-	Out  []Color // mixed + post
+	Out []Color // composited + post
 
-	// crossfade
-	alpha  float64 // 0..1
-	fading bool
+	// per-frame copy of the stack so rendering runs without holding mu
+	frame []layerFrame
 
 	// timing
 	t0 time.Time
 
-	// post
-	post PostPipeline
+	// post: swapped whole under mu, so a frame always runs one consistent chain
+	post  *PostChain
+	calib *Calibration  // output stage after post (nil = none)
+	power FrameLimiter  // last output stage, after calibration (nil = none)
+	model *CurrentModel // LED model for the limiter stage (nil = linear LEDChan_mA)
+
+	// extra outputs, each with its own post chain (see AddOutput)
+	outputs  []*output
+	outFrame []outputFrame // due this frame
 
 	// metrics (last durations in ms)
 	Last struct {
 		RenderMS float64
 		PostMS   float64
+		WriteMS  float64 // Drv.Write; only the hand-off for an AsyncDriver
 		TotalMS  float64
+		Post     []PostTiming // per stage, in chain order
 	}
 }
 
-// PostPipeline groups post stages; all are optional.
-type PostPipeline struct {
-	ToneMap func([]Color)
-	Limiter func([]Color, *Uniforms)
+// layerFrame is one layer as of the snapshot. The renderer is captured
+// with it: SetRenderer and promotion replace layers rather than edit them,
+// but a frame must never pick up an instance swapped in after its snapshot.
+type layerFrame struct {
+	l       *Layer
+	r       Renderer
+	u       *Uniforms
+	opacity float64
+	blend   BlendMode
+	trans   *Transition
 }
 
-func (e *Engine) SnapshotUniforms() *Uniforms { return e.snapshotActive() }
+// SnapshotUniforms returns a copy of what the scene renderer sees
+// (global overlaid by the scene's own uniforms).
+func (e *Engine) SnapshotUniforms() *Uniforms {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return inherit(e.global, e.layers[0].U)
+}
+
+// SnapshotScope returns a copy of one scope: ScopeGlobal as stored, ScopePost
+// and layers as their consumers see them (inheriting global).
+func (e *Engine) SnapshotScope(scope string) (*Uniforms, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	switch scope {
+	case ScopeGlobal:
+		return copyUniforms(e.global), nil
+	case ScopePost:
+		return inherit(e.global, e.postU), nil
+	}
+	if l := e.findLocked(scope); l != nil {
+		return inherit(e.global, l.U), nil
+	}
+	return nil, errors.New("unknown scope: " + scope)
+}
 
 // NewEngine allocates buffers and returns an Engine with defaults wired.
 func NewEngine(dim Dimensions, lut []Vec3, drv Driver, r Renderer, u *Uniforms, rsrc *Resources) (*Engine, error) {
@@ -63,33 +136,56 @@ func NewEngine(dim Dimensions, lut []Vec3, drv Driver, r Renderer, u *Uniforms,
 		return nil, errors.New("invalid dimensions")
 	}
 	n := dim.X * dim.Y * dim.Z
-	e := &Engine{
-		Dim:     dim,
-		LUT:     lut,
-		Drv:     drv,
-		Rsrc:    rsrc,
-		RActive: r,
-		UActive: u,
-		BufA:    make([]Color, n),
-		BufB:    make([]Color, n),
-		Out:     make([]Color, n),
-		alpha:   0,
-		fading:  false,
-		post: PostPipeline{
-			ToneMap: DefaultToneMap,
-			Limiter: DefaultLimiter,
-		},
-		t0: time.Now(),
+	global, post, scene := splitUniforms(u)
+	chain, err := NewPostChain(DefaultPostChain())
+	if err != nil {
+		return nil, err
 	}
+	e := &Engine{
+		Dim:    dim,
+		LUT:    lut,
+		Drv:    drv,
+		Rsrc:   rsrc,
+		Out:    make([]Color, n),
+		post:   chain,
+		global: global,
+		postU:  post,
+		postV:  newUniforms(),
+		t0:     time.Now(),
+	}
+	e.layers = []*Layer{e.newLayer(SceneLayer, r, scene, BlendAlpha, 1)}
 	return e, nil
 }
 
+func (e *Engine) newLayer(name string, r Renderer, u *Uniforms, blend BlendMode, opacity float64) *Layer {
+	return &Layer{Name: name, R: r, U: u, Opacity: opacity, Blend: blend, buf: make([]Color, len(e.Out)), view: newUniforms()}
+}
+
+// Active returns the scene renderer.
+func (e *Engine) Active() Renderer {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.layers[0].R
+}
+
+// Next returns the armed crossfade renderer, or nil.
+func (e *Engine) Next() Renderer {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.next == nil {
+		return nil
+	}
+	return e.next.R
+}
+
 // Now returns seconds since engine start, scaled by TimeScale.
 func (e *Engine) Now() float64 {
 	scale := 1.0
-	if e.UActive != nil && e.UActive.TimeScale != 0 {
-		scale = e.UActive.TimeScale
+	e.mu.RLock()
+	if e.global.TimeScale != 0 {
+		scale = e.global.TimeScale
 	}
+	e.mu.RUnlock()
 	return time.Since(e.t0).Seconds() * scale
 }
 
@@ -101,69 +197,65 @@ func (e *Engine) RenderOnce(t float64) error {
 	}
 	start := time.Now()
 
-	// Read-only uniforms for this frame
-	uA := e.snapshotActive()
-	uN := e.snapshotNext()
-
-	// Render active
-	// --- Render & mix ---
-	if e.RActive != nil {
-		e.RActive.Render(e.BufA, e.LUT, e.Dim, t, uA, e.Rsrc)
-	}
-
-	if e.RNext != nil {
-		// Only render B if we actually need it (alpha in (0,1))
-		if e.alpha > 0 && e.alpha < 1 {
-			e.RNext.Render(e.BufB, e.LUT, e.Dim, t, uN, e.Rsrc)
-			Mix(e.Out, e.BufA, e.BufB, e.alpha)
-		} else if e.alpha >= 1 {
-			// promote B -> A immediately at frame boundary
-			e.RActive = e.RNext
-			e.RNext = nil
-			e.fading = false
-			e.alpha = 0
-			if uN != nil {
-				e.mu.Lock()
-				e.UActive = uN
-				e.UNext = nil
-				e.mu.Unlock()
-			}
-			if e.RActive != nil {
-				e.RActive.Render(e.BufA, e.LUT, e.Dim, t, uN, e.Rsrc)
-			}
-			copy(e.Out, e.BufA)
-			// clear BufB to avoid ghosts
-			for i := range e.BufB {
-				e.BufB[i] = Color{}
-			}
-		} else {
-			// alpha == 0: ignore B
-			copy(e.Out, e.BufA)
+	// Snapshot the stack (promoting a finished crossfade at the frame boundary)
+	e.mu.Lock()
+	if e.next != nil && e.next.Opacity >= 1 {
+		e.promoteLocked()
+	}
+	e.frame = e.frame[:0]
+	for _, l := range e.layers {
+		inheritInto(l.view, e.global, l.U)
+		e.frame = append(e.frame, layerFrame{l: l, r: l.R, u: l.view, opacity: l.Opacity, blend: l.Blend, trans: l.Trans})
+	}
+	inheritInto(e.postV, e.global, e.postU)
+	uPost := e.postV
+	chain, calib, power, model := e.post, e.calib, e.power, e.model
+	e.snapshotOutputsLocked(start)
+	e.mu.Unlock()
+
+	// --- Render & composite ---
+	for i := range e.Out {
+		e.Out[i] = Color{}
+	}
+	for _, f := range e.frame {
+		if f.r == nil || f.opacity <= 0 {
+			continue
+		}
+		f.r.Render(f.l.buf, e.LUT, e.Dim, t, f.u, e.Rsrc)
+		if f.trans == nil || f.trans.Kind == TransFade || f.opacity >= 1 {
+			Blend(e.Out, f.l.buf, f.blend, f.opacity)
+			continue
+		}
+		if len(f.l.mask) != len(f.l.buf) {
+			f.l.mask = make([]float32, len(f.l.buf))
 		}
-	} else {
-		// no B armed, render A only
-		copy(e.Out, e.BufA)
+		f.trans.Mask(f.l.mask, e.LUT, e.Dim, f.opacity)
+		BlendMask(e.Out, f.l.buf, f.blend, f.l.mask)
 	}
+	e.mu.Lock()
+	e.releaseRetiredLocked()
+	e.mu.Unlock()
+
+	// --- Extra outputs, each from its own copy of the composite ---
+	e.writeOutputs(e.Out, uPost, model, calib)
 
 	// --- Post ---
 	postStart := time.Now()
-	if e.post.ToneMap != nil {
-		e.post.ToneMap(e.Out) // exposure+tonemap+gamma for preview path
-	}
-	preview := false
-	if uA != nil && uA.Params != nil {
-		if uA.Params["PreviewMode"] > 0.5 || uA.Params["PreviewBypass"] > 0.5 {
-			preview = true
-		}
-	}
-	if !preview && e.post.Limiter != nil {
-		e.post.Limiter(e.Out, uA)
+	e.Last.Post = chain.run(e.Out, uPost, model, e.Last.Post)
+	e.Last.Post = calib.run(e.Out, e.Last.Post)
+	if power != nil {
+		t0 := time.Now()
+		power.Limit(e.Out)
+		e.Last.Post = append(e.Last.Post, PostTiming{Stage: "power", MS: float64(time.Since(t0).Microseconds()) / 1000.0})
 	}
 	e.Last.PostMS = float64(time.Since(postStart).Microseconds()) / 1000.0
 
 	// Write
 	if e.Drv != nil {
-		if err := e.Drv.Write(e.Out); err != nil {
+		t0 := time.Now()
+		err := e.Drv.Write(e.Out)
+		e.Last.WriteMS = float64(time.Since(t0).Microseconds()) / 1000.0
+		if err != nil {
 			return err
 		}
 	}
@@ -174,57 +266,73 @@ func (e *Engine) RenderOnce(t float64) error {
 	return nil
 }
 
-func (e *Engine) UseFilmicPost() {
-	e.SetPost(PostPipeline{
-		ToneMap: func(buf []Color) { FilmicToneMap(buf, e.UActive) },
-		Limiter: DefaultLimiter,
-	})
+// FrameLimiter is the engine's last output stage: it sees the final frame
+// bound for every output and may only scale it down (see package power).
+type FrameLimiter interface {
+	Limit(buf []Color)
 }
 
-func (e *Engine) SetPost(p PostPipeline) { e.post = p }
+// SetFrameLimiter installs the output power stage (nil removes it).
+func (e *Engine) SetFrameLimiter(l FrameLimiter) {
+	e.mu.Lock()
+	e.power = l
+	e.mu.Unlock()
+}
 
-// snapshotActive copies UActive (maps included) so renderers read a stable view
-func (e *Engine) snapshotActive() *Uniforms {
-	e.mu.RLock()
-	defer e.mu.RUnlock()
-	if e.UActive == nil {
-		return &Uniforms{Params: map[string]float64{}, Bools: map[string]bool{}}
+// SetPostChain replaces the output post chain (nil or empty = no post).
+// Stage instances are rebuilt, so stateful stages start fresh.
+func (e *Engine) SetPostChain(cfg []PostStageConfig) error {
+	c, err := NewPostChain(cfg)
+	if err != nil {
+		return err
 	}
-	out := &Uniforms{
-		GlobalBrightness: e.UActive.GlobalBrightness,
-		TimeScale:        e.UActive.TimeScale,
-		SunDir:           e.UActive.SunDir,
-		MoonDir:          e.UActive.MoonDir,
-		Params:           map[string]float64{},
-		Bools:            map[string]bool{},
-	}
-	for k, v := range e.UActive.Params {
-		out.Params[k] = v
-	}
-	for k, v := range e.UActive.Bools {
-		out.Bools[k] = v
-	}
-	return out
+	e.mu.Lock()
+	e.post = c
+	e.mu.Unlock()
+	return nil
 }
 
-func (e *Engine) snapshotNext() *Uniforms {
+// PostChain returns the current chain configuration.
+func (e *Engine) PostChain() []PostStageConfig {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
-	if e.UNext == nil {
-		return &Uniforms{Params: map[string]float64{}, Bools: map[string]bool{}}
+	return e.post.Config()
+}
+
+// SetPostStageParam sets one explicit param on stage i of the chain. The
+// stage keeps its instance (and any state) across the edit.
+func (e *Engine) SetPostStageParam(i int, key string, v float64) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c, err := e.post.withParam(i, key, v)
+	if err != nil {
+		return err
+	}
+	e.post = c
+	return nil
+}
+
+func newUniforms() *Uniforms {
+	return &Uniforms{Params: map[string]float64{}, Bools: map[string]bool{}}
+}
+
+// copyUniforms copies u (maps included) so renderers read a stable view.
+func copyUniforms(u *Uniforms) *Uniforms {
+	if u == nil {
+		return newUniforms()
 	}
 	out := &Uniforms{
-		GlobalBrightness: e.UNext.GlobalBrightness,
-		TimeScale:        e.UNext.TimeScale,
-		SunDir:           e.UNext.SunDir,
-		MoonDir:          e.UNext.MoonDir,
-		Params:           map[string]float64{},
-		Bools:            map[string]bool{},
-	}
-	for k, v := range e.UNext.Params {
+		GlobalBrightness: u.GlobalBrightness,
+		TimeScale:        u.TimeScale,
+		SunDir:           u.SunDir,
+		MoonDir:          u.MoonDir,
+		Params:           make(map[string]float64, len(u.Params)),
+		Bools:            make(map[string]bool, len(u.Bools)),
+	}
+	for k, v := range u.Params {
 		out.Params[k] = v
 	}
-	for k, v := range e.UNext.Bools {
+	for k, v := range u.Bools {
 		out.Bools[k] = v
 	}
 	return out
@@ -232,109 +340,328 @@ func (e *Engine) snapshotNext() *Uniforms {
 
 // ---- Hooks that match Sequencer expectations ----
 
-// SetRenderer becomes the active renderer immediately.
-// If preset != "", ApplyPreset is called on the renderer with UActive.
+// SetRenderer makes a fresh instance of the named renderer the scene, with
+// fresh scene uniforms, and drops any armed crossfade. If preset != "",
+// ApplyPreset is called on it with those uniforms. When the armed renderer
+// is already this name/preset (the sequencer snapping to the clip it just
+// faded into), it is promoted instead, so its simulation state carries on
+// without a pop.
 func (e *Engine) SetRenderer(name string, preset string, reg *Registry) error {
 	if reg == nil {
 		return errors.New("registry is nil")
 	}
-	rr, ok := reg.Get(name)
+	e.mu.Lock()
+	if n := e.next; n != nil && n.R != nil && n.R.Name() == name && n.Preset == preset {
+		e.promoteLocked()
+		e.mu.Unlock()
+		return nil
+	}
+	e.mu.Unlock()
+
+	// build and preset the instance before it is published
+	rr, ok := reg.New(name)
 	if !ok {
 		return errors.New("renderer not found: " + name)
 	}
-	e.RActive = rr
+	u := newUniforms()
 	if preset != "" {
-		rr.ApplyPreset(preset, e.UActive)
+		rr.ApplyPreset(preset, u)
 	}
-	// reset fade
-	e.fading = false
-	e.alpha = 0
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	// a fresh layer: the render goroutine may still be using the old one
+	scene := e.newLayer(SceneLayer, rr, u, BlendAlpha, 1)
+	scene.Preset = preset
+	e.retireLocked(e.layers[0].R)
+	e.layers[0] = scene
+	e.dropNextLocked()
 	return nil
 }
 
-// ArmNext prepares the next renderer for crossfade.
+// ArmNext prepares the next renderer for crossfade: it is inserted directly
+// above the scene at opacity 0 with its own fresh uniforms.
 func (e *Engine) ArmNext(name string, preset string, reg *Registry) error {
 	if reg == nil {
 		return errors.New("registry is nil")
 	}
-	rr, ok := reg.Get(name)
+	rr, ok := reg.New(name)
 	if !ok {
 		return errors.New("renderer not found: " + name)
 	}
-	e.RNext = rr
-
-	e.mu.RLock()
-	ua := e.UActive
-	e.mu.RUnlock()
-
-	if e.UNext == nil {
-		e.UNext = &Uniforms{
-			GlobalBrightness: ua.GlobalBrightness,
-			TimeScale:        ua.TimeScale,
-			SunDir:           ua.SunDir,
-			MoonDir:          ua.MoonDir,
-			Params:           map[string]float64{},
-			Bools:            map[string]bool{},
-		}
-		if ua.Params != nil {
-			for k, v := range ua.Params {
-				e.UNext.Params[k] = v
-			}
-		}
-		if ua.Bools != nil {
-			for k, v := range ua.Bools {
-				e.UNext.Bools[k] = v
-			}
-		}
-	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.dropNextLocked()
+	l := e.newLayer(NextLayer, rr, newUniforms(), BlendAlpha, 0)
+	l.Preset, l.Trans = preset, e.trans
 	if preset != "" {
-		rr.ApplyPreset(preset, e.UNext)
+		rr.ApplyPreset(preset, l.U)
 	}
-	e.fading = true
+	e.layers = append(e.layers[:1], append([]*Layer{l}, e.layers[1:]...)...)
+	e.next = l
 	return nil
 }
 
-// SetCrossfade sets mix alpha 0..1 and enables/disables fading.
-// SetCrossfade clamps alpha and toggles fading appropriately.
-// If there is no RNext armed, alpha changes are ignored.
+// SetTransition picks the spatial transition for crossfades; the crossfade
+// alpha becomes its progress. It applies to the armed layer (if any) and to
+// every later ArmNext. nil restores the uniform fade.
+func (e *Engine) SetTransition(tr *Transition) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.trans = tr
+	if e.next != nil {
+		e.next.Trans = tr
+	}
+}
+
+// SetCrossfade sets the armed layer's opacity, clamped to 0..1.
+// If there is no next renderer armed, alpha changes are ignored.
 func (e *Engine) SetCrossfade(a float64) {
 	if a < 0 {
 		a = 0
 	} else if a > 1 {
 		a = 1
 	}
-	// no next renderer => nothing to fade to; ignore
-	if e.RNext == nil {
-		e.fading = false
-		e.alpha = 0
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.next == nil {
+		return
+	}
+	e.next.Opacity = a
+}
+
+// promoteLocked makes the armed layer the scene, retiring the old scene
+// instance. Caller holds mu.
+func (e *Engine) promoteLocked() {
+	l := e.next
+	e.unlinkLocked(l)
+	e.next = nil
+	e.retireLocked(e.layers[0].R)
+	// the armed layer may be mid-render: the scene takes its instance,
+	// uniforms and buffer on a new layer instead of relabelling it
+	e.layers[0] = &Layer{Name: SceneLayer, R: l.R, Preset: l.Preset, U: l.U, Opacity: 1, Blend: BlendAlpha, buf: l.buf, view: l.view}
+}
+
+// dropNextLocked removes and retires the armed layer. Caller holds mu.
+func (e *Engine) dropNextLocked() {
+	if e.next == nil {
 		return
 	}
-	e.alpha = a
-	// only mix when we truly are in-between
-	e.fading = (a > 0 && a < 1)
+	e.unlinkLocked(e.next)
+	e.retireLocked(e.next.R)
+	e.next = nil
+}
+
+func (e *Engine) unlinkLocked(target *Layer) {
+	for i, l := range e.layers {
+		if l == target {
+			e.layers = append(e.layers[:i], e.layers[i+1:]...)
+			return
+		}
+	}
+}
+
+// retireLocked queues an instance that left the stack for release.
+func (e *Engine) retireLocked(r Renderer) {
+	if r != nil {
+		e.retired = append(e.retired, r)
+	}
+}
+
+// releaseRetiredLocked frees retired instances. Called once the frame's
+// layers have rendered: retired instances are off the stack, so no later
+// snapshot holds them, and the only one that could is finished.
+func (e *Engine) releaseRetiredLocked() {
+	for i, r := range e.retired {
+		if rel, ok := r.(Releaser); ok {
+			rel.Release()
+		}
+		e.retired[i] = nil
+	}
+	e.retired = e.retired[:0]
 }
 
-// SetParam updates active uniforms.
-func (e *Engine) SetParam(name string, v float64) {
+// SetParam sets an unscoped key, routed to the scene renderer if it declares
+// it, else to post or global if they do, else to the scene. Values are
+// clamped to the declared range; unknown keys (for renderers with a schema)
+// and non-finite values are rejected. Use SetScopedParam to pick the scope.
+func (e *Engine) SetParam(name string, v float64) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	if e.UActive == nil {
-		e.UActive = &Uniforms{}
+	return e.setScopedParamLocked(e.routeLocked(name), name, v)
+}
+
+// SetBool is SetParam for boolean uniforms.
+func (e *Engine) SetBool(name string, b bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.setScopedBoolLocked(e.routeLocked(name), name, b)
+}
+
+// DescribeParams lists the scene renderer's parameters, then PostParams and
+// GlobalParams, each tagged with its scope and current value.
+func (e *Engine) DescribeParams() []ParamInfo {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	scene := e.layers[0]
+	specs, _ := SchemaOf(scene.R)
+	out := describe(nil, SceneLayer, specs, scene.U)
+	out = describe(out, ScopePost, PostParams(), e.postU)
+	global := copyUniforms(e.global)
+	global.Params["TimeScale"], global.Params["GlobalBrightness"] = e.global.TimeScale, e.global.GlobalBrightness
+	return describe(out, ScopeGlobal, GlobalParams(), global)
+}
+
+// DescribeLayerParams describes one layer's renderer parameters.
+func (e *Engine) DescribeLayerParams(name string) ([]ParamInfo, error) {
+	var out []ParamInfo
+	err := e.withLayer(name, func(l *Layer) error {
+		specs, _ := SchemaOf(l.R)
+		out = describe(nil, l.Name, specs, l.U)
+		return nil
+	})
+	return out, err
+}
+
+func setParam(l *Layer, name string, v float64) {
+	if l.U == nil {
+		l.U = &Uniforms{}
+	}
+	if l.U.Params == nil {
+		l.U.Params = map[string]float64{}
+	}
+	l.U.Params[name] = v
+}
+
+func setBool(l *Layer, name string, b bool) {
+	if l.U == nil {
+		l.U = &Uniforms{}
 	}
-	if e.UActive.Params == nil {
-		e.UActive.Params = map[string]float64{}
+	if l.U.Bools == nil {
+		l.U.Bools = map[string]bool{}
 	}
-	e.UActive.Params[name] = v
+	l.U.Bools[name] = b
 }
 
-func (e *Engine) SetBool(name string, b bool) {
+// ---- Overlay layers ----
+
+// AddLayer pushes a renderer on top of the stack with fresh uniforms (it
+// inherits global like every layer) and the given preset.
+func (e *Engine) AddLayer(name, renderer, preset string, reg *Registry, blend BlendMode, opacity float64) error {
+	if reg == nil {
+		return errors.New("registry is nil")
+	}
+	if name == "" || name == SceneLayer || name == NextLayer {
+		return errors.New("invalid layer name: " + name)
+	}
+	rr, ok := reg.New(renderer)
+	if !ok {
+		return errors.New("renderer not found: " + renderer)
+	}
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	if e.UActive == nil {
-		e.UActive = &Uniforms{}
+	if e.findLocked(name) != nil {
+		return errors.New("layer exists: " + name)
+	}
+	u := newUniforms()
+	if preset != "" {
+		rr.ApplyPreset(preset, u)
+	}
+	l := e.newLayer(name, rr, u, blend, clampUnit(opacity))
+	l.Preset = preset
+	e.layers = append(e.layers, l)
+	return nil
+}
+
+// RemoveLayer drops an overlay layer. The scene layers cannot be removed.
+func (e *Engine) RemoveLayer(name string) error {
+	if name == SceneLayer || name == NextLayer {
+		return errors.New("cannot remove " + name)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	l := e.findLocked(name)
+	if l == nil {
+		return errors.New("layer not found: " + name)
+	}
+	e.unlinkLocked(l)
+	e.retireLocked(l.R)
+	return nil
+}
+
+// SetLayerOpacity sets a layer's opacity (clamped 0..1).
+func (e *Engine) SetLayerOpacity(name string, a float64) error {
+	return e.withLayer(name, func(l *Layer) error { l.Opacity = clampUnit(a); return nil })
+}
+
+// SetLayerBlend sets a layer's blend mode.
+func (e *Engine) SetLayerBlend(name string, m BlendMode) error {
+	return e.withLayer(name, func(l *Layer) error { l.Blend = m; return nil })
+}
+
+// SetLayerParam updates one layer's uniforms.
+func (e *Engine) SetLayerParam(name, key string, v float64) error {
+	return e.withLayer(name, func(l *Layer) error {
+		v, err := CheckParam(l.R, nil, key, v)
+		if err != nil {
+			return err
+		}
+		setParam(l, key, v)
+		return nil
+	})
+}
+
+// SetLayerBool updates one layer's boolean uniforms.
+func (e *Engine) SetLayerBool(name, key string, b bool) error {
+	return e.withLayer(name, func(l *Layer) error {
+		if err := CheckBool(l.R, nil, key); err != nil {
+			return err
+		}
+		setBool(l, key, b)
+		return nil
+	})
+}
+
+// Layers lists the stack bottom→top.
+func (e *Engine) Layers() []LayerInfo {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	out := make([]LayerInfo, 0, len(e.layers))
+	for _, l := range e.layers {
+		li := LayerInfo{Name: l.Name, Preset: l.Preset, Opacity: l.Opacity, Blend: l.Blend}
+		if l.R != nil {
+			li.Renderer = l.R.Name()
+		}
+		out = append(out, li)
+	}
+	return out
+}
+
+func (e *Engine) withLayer(name string, f func(*Layer) error) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	l := e.findLocked(name)
+	if l == nil {
+		return errors.New("layer not found: " + name)
+	}
+	return f(l)
+}
+
+func (e *Engine) findLocked(name string) *Layer {
+	for _, l := range e.layers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+func clampUnit(a float64) float64 {
+	if a < 0 {
+		return 0
 	}
-	if e.UActive.Bools == nil {
-		e.UActive.Bools = map[string]bool{}
+	if a > 1 {
+		return 1
 	}
-	e.UActive.Bools[name] = b
+	return a
 }
@@ -1,6 +1,11 @@
 package render
 
-import "testing"
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+)
 
 // fakeRenderer writes a constant color for testing.
 type fakeRenderer struct {
@@ -22,6 +27,11 @@ type fakeDriver struct {
 	last []Color
 }
 
+func (d *fakeDriver) Caps() Caps   { return Caps{} }
+func (d *fakeDriver) Open() error  { return nil }
+func (d *fakeDriver) Blank() error { return nil }
+func (d *fakeDriver) Close() error { return nil }
+
 func (d *fakeDriver) Write(buf []Color) error {
 	d.last = make([]Color, len(buf))
 	copy(d.last, buf)
@@ -50,8 +60,8 @@ func TestEngineRenderOnceAndCrossfade(t *testing.T) {
 	reg := NewRegistry()
 	ra := &fakeRenderer{name: "A", r: 1, g: 0, b: 0}
 	rb := &fakeRenderer{name: "B", r: 0, g: 0, b: 1}
-	reg.Register(ra)
-	reg.Register(rb)
+	reg.Register("A", func() Renderer { return ra })
+	reg.Register("B", func() Renderer { return rb })
 
 	u := &Uniforms{GlobalBrightness: 1.0, TimeScale: 1.0, Params: map[string]float64{}, Bools: map[string]bool{}}
 	e, err := NewEngine(dim, lut, drv, ra, u, &Resources{})
@@ -59,7 +69,7 @@ func TestEngineRenderOnceAndCrossfade(t *testing.T) {
 		t.Fatalf("engine: %v", err)
 	}
 	// Disable tone mapping for deterministic tests.
-	e.SetPost(PostPipeline{})
+	_ = e.SetPostChain(nil)
 
 	// Active A, render once
 	if err := e.RenderOnce(-1); err != nil {
@@ -90,3 +100,378 @@ func TestEngineRenderOnceAndCrossfade(t *testing.T) {
 		t.Fatalf("expected blue frame after complete fade, got %#v", drv.last[0])
 	}
 }
+
+func TestBlendModes(t *testing.T) {
+	base := Color{0.5, 0.5, 0.5}
+	src := Color{0.5, 1, 0}
+	cases := []struct {
+		mode BlendMode
+		want Color
+	}{
+		{BlendAlpha, Color{0.5, 1, 0}},
+		{BlendAdd, Color{1, 1.5, 0.5}},
+		{BlendScreen, Color{0.75, 1, 0.5}},
+		{BlendMultiply, Color{0.25, 0.5, 0}},
+		{BlendMax, Color{0.5, 1, 0.5}},
+	}
+	for _, c := range cases {
+		dst := []Color{base}
+		Blend(dst, []Color{src}, c.mode, 1)
+		if dst[0] != c.want {
+			t.Errorf("%s: got %#v, want %#v", c.mode, dst[0], c.want)
+		}
+	}
+	// half opacity lands halfway between base and the full blend
+	dst := []Color{base}
+	Blend(dst, []Color{src}, BlendMultiply, 0.5)
+	if dst[0].R < 0.374 || dst[0].R > 0.376 {
+		t.Fatalf("multiply @0.5: got %#v", dst[0])
+	}
+}
+
+func TestEngineOverlayStaysOnTopThroughCrossfade(t *testing.T) {
+	dim := Dimensions{X: 1, Y: 1, Z: 1}
+	drv := &fakeDriver{}
+	reg := NewRegistry()
+	ra := &fakeRenderer{name: "A", r: 1}
+	rb := &fakeRenderer{name: "B", b: 1}
+	fx := &fakeRenderer{name: "fx", g: 1}
+	reg.Register("A", func() Renderer { return ra })
+	reg.Register("B", func() Renderer { return rb })
+	reg.Register("fx", func() Renderer { return fx })
+
+	e, err := NewEngine(dim, []Vec3{{}}, drv, ra, &Uniforms{}, &Resources{})
+	if err != nil {
+		t.Fatalf("engine: %v", err)
+	}
+	_ = e.SetPostChain(nil)
+	if err := e.AddLayer("fx", "fx", "", reg, BlendAdd, 0.5); err != nil {
+		t.Fatalf("add layer: %v", err)
+	}
+	if err := e.ArmNext("B", "", reg); err != nil {
+		t.Fatalf("arm: %v", err)
+	}
+	if got := e.Layers(); len(got) != 3 || got[1].Name != NextLayer || got[2].Name != "fx" {
+		t.Fatalf("unexpected stack: %+v", got)
+	}
+	e.SetCrossfade(0.5)
+	_ = e.RenderOnce(0)
+	if c := drv.last[0]; c.R < 0.49 || c.R > 0.51 || c.B < 0.49 || c.B > 0.51 || c.G < 0.49 || c.G > 0.51 {
+		t.Fatalf("expected purple + half green overlay, got %#v", c)
+	}
+
+	e.SetCrossfade(1)
+	_ = e.RenderOnce(0)
+	if got := e.Layers(); len(got) != 2 || got[0].Renderer != "B" || got[1].Name != "fx" {
+		t.Fatalf("expected B promoted under fx, got %+v", got)
+	}
+	if c := drv.last[0]; c.R > 0.01 || c.B < 0.99 || c.G < 0.49 {
+		t.Fatalf("expected blue + overlay after promotion, got %#v", c)
+	}
+}
+
+// releasingRenderer counts Release calls.
+type releasingRenderer struct {
+	fakeRenderer
+	released int
+}
+
+func (r *releasingRenderer) Release() { r.released++ }
+
+func TestRegistryFreshInstancesReleasedOnPromoteAndDrop(t *testing.T) {
+	reg := NewRegistry()
+	var made []*releasingRenderer
+	reg.Register("A", func() Renderer {
+		r := &releasingRenderer{fakeRenderer: fakeRenderer{name: "A", r: 1}}
+		made = append(made, r)
+		return r
+	})
+	r1, _ := reg.New("A")
+	r2, _ := reg.New("A")
+	if r1 == r2 {
+		t.Fatalf("expected distinct instances per New")
+	}
+	if _, ok := reg.New("nope"); ok {
+		t.Fatalf("expected unknown name to fail")
+	}
+
+	drv := &fakeDriver{}
+	e, err := NewEngine(Dimensions{X: 1, Y: 1, Z: 1}, []Vec3{{}}, drv, made[0], &Uniforms{}, &Resources{})
+	if err != nil {
+		t.Fatalf("engine: %v", err)
+	}
+	_ = e.SetPostChain(nil)
+
+	// A → A crossfade uses a third instance; the scene instance is untouched.
+	if err := e.ArmNext("A", "x", reg); err != nil {
+		t.Fatalf("arm: %v", err)
+	}
+	armed := made[2]
+	e.SetCrossfade(1)
+	_ = e.RenderOnce(0) // promotes; old scene retired, released once rendered
+	if made[0].released != 1 || armed.released != 0 {
+		t.Fatalf("expected old scene released once, armed kept: %d %d", made[0].released, armed.released)
+	}
+
+	// SetRenderer for the name/preset just faded in keeps that instance.
+	if err := e.ArmNext("A", "y", reg); err != nil {
+		t.Fatalf("arm: %v", err)
+	}
+	incoming := made[3]
+	if err := e.SetRenderer("A", "y", reg); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if got := e.Active(); got != Renderer(incoming) {
+		t.Fatalf("expected armed instance promoted by SetRenderer")
+	}
+
+	// Dropping an armed layer releases it.
+	_ = e.ArmNext("A", "z", reg)
+	dropped := made[len(made)-1]
+	_ = e.SetRenderer("A", "w", reg)
+	_ = e.RenderOnce(0)
+	if dropped.released != 1 || armed.released != 1 {
+		t.Fatalf("expected dropped and replaced instances released: %d %d", dropped.released, armed.released)
+	}
+}
+
+// schemaRenderer declares one float and one bool param.
+type schemaRenderer struct{ fakeRenderer }
+
+func (r *schemaRenderer) ParamSchema() []ParamSpec {
+	return []ParamSpec{
+		{Name: "Level", Type: ParamFloat, Min: 0, Max: 2, Default: 1},
+		{Name: "Steps", Type: ParamInt, Min: 1, Max: 8, Default: 4},
+		{Name: "Flip", Type: ParamBool, Min: 0, Max: 1},
+	}
+}
+
+func TestEngineSetParamUsesSchema(t *testing.T) {
+	e, err := NewEngine(Dimensions{X: 1, Y: 1, Z: 1}, []Vec3{{}}, &fakeDriver{}, &schemaRenderer{}, &Uniforms{}, &Resources{})
+	if err != nil {
+		t.Fatalf("engine: %v", err)
+	}
+	if err := e.SetParam("Level", 5); err != nil {
+		t.Fatalf("in-schema param: %v", err)
+	}
+	if err := e.SetParam("Steps", 2.6); err != nil {
+		t.Fatalf("int param: %v", err)
+	}
+	if err := e.SetParam("ExposureEV", 1); err != nil {
+		t.Fatalf("post params are always accepted on the scene: %v", err)
+	}
+	if err := e.SetParam("Bogus", 1); !errors.Is(err, ErrUnknownParam) {
+		t.Fatalf("expected unknown param error, got %v", err)
+	}
+	if err := e.SetParam("Level", math.NaN()); err == nil {
+		t.Fatalf("expected NaN to be rejected")
+	}
+	if err := e.SetBool("Flip", true); err != nil {
+		t.Fatalf("bool param: %v", err)
+	}
+	if err := e.SetBool("Level", true); err == nil {
+		t.Fatalf("expected bool on a float param to be rejected")
+	}
+
+	got := map[string]float64{}
+	for _, p := range e.DescribeParams() {
+		got[p.Name] = p.Value
+	}
+	if got["Level"] != 2 || got["Steps"] != 3 || got["Flip"] != 1 || got["ExposureEV"] != 1 {
+		t.Fatalf("unexpected described values: %v", got)
+	}
+	if _, ok := got["Bogus"]; ok {
+		t.Fatalf("rejected key must not be stored")
+	}
+}
+
+func TestEngineSetParamWithoutSchemaAcceptsAnything(t *testing.T) {
+	e, _ := NewEngine(Dimensions{X: 1, Y: 1, Z: 1}, []Vec3{{}}, &fakeDriver{}, &fakeRenderer{}, &Uniforms{}, &Resources{})
+	if err := e.SetParam("Anything", 42); err != nil {
+		t.Fatalf("legacy renderer should accept any key: %v", err)
+	}
+	err := e.SetParam("OutputGamma", 9)
+	post, _ := e.SnapshotScope(ScopePost)
+	if err != nil || post.Params["OutputGamma"] != 3 {
+		t.Fatalf("post params clamp even without a renderer schema: %v %v", err, post.Params)
+	}
+}
+
+// presetRenderer writes one knob from ApplyPreset and records what it rendered with.
+type presetRenderer struct {
+	fakeRenderer
+	seen *Uniforms
+}
+
+func (r *presetRenderer) ApplyPreset(name string, u *Uniforms) { u.Params["Gamma"] = 1.7 }
+func (r *presetRenderer) Render(dst []Color, pLUT []Vec3, dim Dimensions, t float64, u *Uniforms, rs *Resources) {
+	r.seen = u
+}
+
+func TestEngineUniformScopes(t *testing.T) {
+	reg := NewRegistry()
+	var last *presetRenderer
+	reg.Register("calib", func() Renderer { last = &presetRenderer{fakeRenderer: fakeRenderer{name: "calib"}}; return last })
+	reg.Register("plain", func() Renderer { return &fakeRenderer{name: "plain"} })
+
+	u := &Uniforms{TimeScale: 1, Params: map[string]float64{"ExposureEV": 1, "Shared": 5, "Local": 2}}
+	e, _ := NewEngine(Dimensions{X: 1, Y: 1, Z: 1}, []Vec3{{}}, &fakeDriver{}, &fakeRenderer{name: "plain"}, u, &Resources{})
+	_ = e.SetPostChain(nil)
+	_ = e.SetScopedParam(ScopeGlobal, "Shared", 5)
+	_ = e.SetScopedBool(ScopeGlobal, "PreviewMode", true)
+
+	if err := e.SetRenderer("calib", "p", reg); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	scene := e.SnapshotUniforms()
+	if scene.Params["Gamma"] != 1.7 || scene.Params["Shared"] != 5 || !scene.Bools["PreviewMode"] {
+		t.Fatalf("scene should see its preset plus global: %+v", scene)
+	}
+	if _, leaked := scene.Params["ExposureEV"]; leaked {
+		t.Fatalf("post knobs must not reach renderers: %+v", scene.Params)
+	}
+	if _, kept := scene.Params["Local"]; kept {
+		t.Fatalf("a new renderer starts from fresh scene uniforms: %+v", scene.Params)
+	}
+	if post, _ := e.SnapshotScope(ScopePost); post.Params["ExposureEV"] != 1 || post.Params["Gamma"] != 0 {
+		t.Fatalf("preset must not touch post: %+v", post.Params)
+	}
+
+	// the incoming renderer does not inherit the outgoing scene's knobs
+	_ = e.ArmNext("plain", "", reg)
+	if err := e.SetScopedParam(NextLayer, "Speed", 3); err != nil {
+		t.Fatalf("scoped layer set: %v", err)
+	}
+	next, _ := e.SnapshotScope(NextLayer)
+	if _, leaked := next.Params["Gamma"]; leaked || next.Params["Speed"] != 3 || next.Params["Shared"] != 5 {
+		t.Fatalf("armed layer should see only global + its own: %+v", next.Params)
+	}
+
+	if err := e.SetScopedParam(ScopePost, "Nope", 1); !errors.Is(err, ErrUnknownParam) {
+		t.Fatalf("post is strict, got %v", err)
+	}
+	if err := e.SetScopedParam("nowhere", "X", 1); err == nil {
+		t.Fatalf("expected unknown scope error")
+	}
+	if err := e.SetParam("TimeScale", 2); err != nil || e.SnapshotUniforms().TimeScale != 2 {
+		t.Fatalf("TimeScale routes to the global field: %v", err)
+	}
+	_ = e.RenderOnce(0)
+	if last.seen == nil || last.seen.Params["Shared"] != 5 {
+		t.Fatalf("render should receive the inherited view")
+	}
+}
+
+// levelRenderer keeps its preset in the instance, so presetting one that
+// is already rendering is a data race.
+type levelRenderer struct {
+	fakeRenderer
+	level float32
+}
+
+func (p *levelRenderer) ApplyPreset(name string, u *Uniforms) { p.level = float32(len(name)) / 10 }
+func (p *levelRenderer) Render(dst []Color, _ []Vec3, _ Dimensions, _ float64, _ *Uniforms, _ *Resources) {
+	for i := range dst {
+		dst[i] = Color{R: p.level}
+	}
+}
+
+// Run with -race: scene swaps from a control goroutine while frames render.
+func TestEngineSceneSwapsDuringRender(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register("P", func() Renderer { return &levelRenderer{} })
+	e, err := NewEngine(Dimensions{X: 4, Y: 1, Z: 1}, make([]Vec3, 4), &fakeDriver{}, &levelRenderer{}, &Uniforms{}, &Resources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			_ = e.SetRenderer("P", "abc", reg)
+			_ = e.ArmNext("P", "abcdef", reg)
+			e.SetCrossfade(float64(i%3) / 2)
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+			if err := e.RenderOnce(0); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+// guardedRenderer fails a test that renders it after Release.
+type guardedRenderer struct {
+	fakeRenderer
+	mu       sync.Mutex
+	released bool
+	t        *testing.T
+}
+
+func (g *guardedRenderer) Release() {
+	g.mu.Lock()
+	g.released = true
+	g.mu.Unlock()
+}
+
+func (g *guardedRenderer) Render(dst []Color, pLUT []Vec3, dim Dimensions, t float64, u *Uniforms, r *Resources) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.released {
+		g.t.Error("rendered after Release")
+	}
+}
+
+func TestEngineReleasesOnlyAfterRender(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register("G", func() Renderer { return &guardedRenderer{t: t} })
+	e, err := NewEngine(Dimensions{X: 4, Y: 1, Z: 1}, make([]Vec3, 4), &fakeDriver{}, &guardedRenderer{t: t}, &Uniforms{}, &Resources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			_ = e.SetRenderer("G", "", reg)
+			_ = e.ArmNext("G", "", reg)
+			e.SetCrossfade(1)
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+			_ = e.RenderOnce(0)
+		}
+	}
+}
+
+// nopDriver drops every frame without allocating.
+type nopDriver struct{ fakeDriver }
+
+func (*nopDriver) Write([]Color) error { return nil }
+
+func TestEngineSnapshotsDoNotAllocate(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register("A", func() Renderer { return &fakeRenderer{name: "A", r: 1} })
+	u := &Uniforms{GlobalBrightness: 1, TimeScale: 1, Params: map[string]float64{"Shared": 2, "ExposureEV": 1}, Bools: map[string]bool{"Flag": true}}
+	e, err := NewEngine(Dimensions{X: 2, Y: 2, Z: 2}, make([]Vec3, 8), &nopDriver{}, &fakeRenderer{name: "A"}, u, &Resources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.AddLayer("top", "A", "", reg, BlendAdd, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	_ = e.SetLayerParam("top", "Own", 3)
+	_ = e.RenderOnce(0)
+	if n := testing.AllocsPerRun(20, func() { _ = e.RenderOnce(0) }); n != 0 {
+		t.Fatalf("RenderOnce allocates %v times per frame", n)
+	}
+}
@@ -0,0 +1,227 @@
+package render
+
+import (
+	"errors"
+	"time"
+)
+
+// MainOutput names the engine's primary output: Drv, fed by the engine post
+// chain, calibration and frame limiter (see SetPostChain).
+const MainOutput = "main"
+
+// OutputConfig describes one extra engine output. Each output starts from
+// the composited frame (before any post) and runs its own chain, so the UI
+// preview can show the tone-mapped look while the LEDs get the limiter.
+type OutputConfig struct {
+	Name    string            `json:"name"`
+	Post    []PostStageConfig `json:"post"`   // empty = raw composite
+	MaxFPS  float64           `json:"maxFps"` // 0 = every frame; capped at the driver's Caps().MaxFPS
+	Enabled bool              `json:"enabled"`
+	Calib   bool              `json:"calibrate"` // apply the engine calibration after post
+}
+
+// The frame limiter (SetFrameLimiter) is stateful (soft start, thermal
+// averages) and belongs to the main output alone; an extra output that
+// needs limiting puts the stateless "limiter" stage in its Post.
+
+// OutputInfo is an output's configuration plus its counters.
+type OutputInfo struct {
+	OutputConfig
+	Frames  uint64       `json:"frames"`  // written
+	Skipped uint64       `json:"skipped"` // held back by MaxFPS
+	LastMS  float64      `json:"lastMs"`  // post + write time of the last frame
+	Timing  []PostTiming `json:"timing,omitempty"`
+	Err     string       `json:"error,omitempty"` // last write error, cleared on success
+}
+
+type output struct {
+	cfg   OutputConfig
+	drv   Driver
+	chain *PostChain
+	next  time.Time // earliest time the next frame is due
+
+	// owned by the render goroutine
+	buf    []Color
+	timing []PostTiming
+
+	// published under Engine.mu after each frame
+	frames, skipped uint64
+	lastMS          float64
+	post            []PostTiming
+	err             error
+}
+
+// due reports whether o takes the frame at now and advances its schedule.
+func (o *output) due(now time.Time) bool {
+	if !o.cfg.Enabled {
+		return false
+	}
+	if o.cfg.MaxFPS <= 0 {
+		return true
+	}
+	if now.Before(o.next) {
+		o.skipped++
+		return false
+	}
+	iv := time.Duration(float64(time.Second) / o.cfg.MaxFPS)
+	o.next = o.next.Add(iv)
+	if o.next.Before(now) {
+		o.next = now.Add(iv)
+	}
+	return true
+}
+
+type outputFrame struct {
+	o     *output
+	drv   Driver
+	chain *PostChain
+	calib bool
+}
+
+// AddOutput adds an output writing to drv.
+func (e *Engine) AddOutput(cfg OutputConfig, drv Driver) error {
+	if cfg.Name == "" || cfg.Name == MainOutput {
+		return errors.New("invalid output name: " + cfg.Name)
+	}
+	if drv == nil {
+		return errors.New("output " + cfg.Name + ": nil driver")
+	}
+	chain, err := NewPostChain(cfg.Post)
+	if err != nil {
+		return err
+	}
+	cfg.Post = chain.Config()
+	cfg.MaxFPS = capFPS(cfg.MaxFPS, drv.Caps().MaxFPS)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.findOutputLocked(cfg.Name) != nil {
+		return errors.New("output exists: " + cfg.Name)
+	}
+	e.outputs = append(e.outputs, &output{cfg: cfg, drv: drv, chain: chain, buf: make([]Color, len(e.Out))})
+	return nil
+}
+
+// RemoveOutput drops an output; its driver is not closed.
+func (e *Engine) RemoveOutput(name string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i, o := range e.outputs {
+		if o.cfg.Name == name {
+			e.outputs = append(e.outputs[:i], e.outputs[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("output not found: " + name)
+}
+
+// SetOutput replaces an output's post chain, throttle and flags (the name
+// selects it; the driver stays). A new chain starts with fresh stage state.
+func (e *Engine) SetOutput(cfg OutputConfig) error {
+	chain, err := NewPostChain(cfg.Post)
+	if err != nil {
+		return err
+	}
+	cfg.Post = chain.Config()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o := e.findOutputLocked(cfg.Name)
+	if o == nil {
+		return errors.New("output not found: " + cfg.Name)
+	}
+	cfg.MaxFPS = capFPS(cfg.MaxFPS, o.drv.Caps().MaxFPS)
+	o.cfg, o.chain, o.next = cfg, chain, time.Time{}
+	return nil
+}
+
+// SetOutputEnabled switches one output on or off.
+func (e *Engine) SetOutputEnabled(name string, on bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o := e.findOutputLocked(name)
+	if o == nil {
+		return errors.New("output not found: " + name)
+	}
+	o.cfg.Enabled = on
+	return nil
+}
+
+// Outputs lists the extra outputs in the order they are written.
+func (e *Engine) Outputs() []OutputInfo {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	out := make([]OutputInfo, 0, len(e.outputs))
+	for _, o := range e.outputs {
+		oi := OutputInfo{OutputConfig: o.cfg, Frames: o.frames, Skipped: o.skipped, LastMS: o.lastMS, Timing: append([]PostTiming(nil), o.post...)}
+		oi.OutputConfig.Post = copyChain(o.cfg.Post)
+		if o.err != nil {
+			oi.Err = o.err.Error()
+		}
+		out = append(out, oi)
+	}
+	return out
+}
+
+func (e *Engine) findOutputLocked(name string) *output {
+	for _, o := range e.outputs {
+		if o.cfg.Name == name {
+			return o
+		}
+	}
+	return nil
+}
+
+// snapshotOutputsLocked picks the outputs due at now. Caller holds mu.
+func (e *Engine) snapshotOutputsLocked(now time.Time) {
+	e.outFrame = e.outFrame[:0]
+	for _, o := range e.outputs {
+		if o.due(now) {
+			e.outFrame = append(e.outFrame, outputFrame{o: o, drv: o.drv, chain: o.chain, calib: o.cfg.Calib})
+		}
+	}
+}
+
+// writeOutputs post-processes the composite src for each due output and
+// writes it. A failing output is recorded on it and does not stop the
+// others or the main output.
+func (e *Engine) writeOutputs(src []Color, u *Uniforms, model *CurrentModel, calib *Calibration) {
+	for _, f := range e.outFrame {
+		t0 := time.Now()
+		o := f.o
+		if len(o.buf) != len(src) {
+			o.buf = make([]Color, len(src))
+		}
+		copy(o.buf, src)
+		o.timing = f.chain.run(o.buf, u, model, o.timing)
+		if f.calib {
+			o.timing = calib.run(o.buf, o.timing)
+		}
+		err := f.drv.Write(o.buf)
+		ms := float64(time.Since(t0).Microseconds()) / 1000.0
+
+		e.mu.Lock()
+		o.frames++
+		o.lastMS, o.err = ms, err
+		o.post = append(o.post[:0], o.timing...)
+		e.mu.Unlock()
+	}
+}
+
+// capFPS limits a requested rate (0 = unthrottled) to what the driver can
+// take (0 = unbounded).
+func capFPS(want, limit float64) float64 {
+	if limit > 0 && (want <= 0 || want > limit) {
+		return limit
+	}
+	return want
+}
+
+func copyChain(cfg []PostStageConfig) []PostStageConfig {
+	if cfg == nil {
+		return nil
+	}
+	out := make([]PostStageConfig, len(cfg))
+	for i, st := range cfg {
+		out[i] = PostStageConfig{Stage: st.Stage, Params: copyParams(st.Params)}
+	}
+	return out
+}
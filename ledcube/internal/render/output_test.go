@@ -0,0 +1,63 @@
+package render
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+type failDriver struct{}
+
+func (failDriver) Caps() Caps          { return Caps{} }
+func (failDriver) Open() error         { return nil }
+func (failDriver) Write([]Color) error { return errors.New("unplugged") }
+func (failDriver) Blank() error        { return nil }
+func (failDriver) Close() error        { return nil }
+
+func TestOutputsRunTheirOwnChains(t *testing.T) {
+	dim := Dimensions{X: 2, Y: 1, Z: 1}
+	main, preview, slow := &fakeDriver{}, &fakeDriver{}, &fakeDriver{}
+	u := &Uniforms{GlobalBrightness: 1, TimeScale: 1, Params: map[string]float64{}, Bools: map[string]bool{}}
+	e, err := NewEngine(dim, make([]Vec3, 2), main, &fakeRenderer{name: "A", r: 0.5, g: 0.5, b: 0.5}, u, &Resources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = e.SetPostChain([]PostStageConfig{{Stage: "exposure", Params: map[string]float64{"ExposureEV": 1}}})
+	if err := e.AddOutput(OutputConfig{Name: "preview", Enabled: true, Post: []PostStageConfig{{Stage: "gamma", Params: map[string]float64{"OutputGamma": 2}}}}, preview); err != nil {
+		t.Fatal(err)
+	}
+	_ = e.AddOutput(OutputConfig{Name: "slow", Enabled: true, MaxFPS: 0.001}, slow)
+	_ = e.AddOutput(OutputConfig{Name: "broken", Enabled: true}, failDriver{})
+	if err := e.AddOutput(OutputConfig{Name: "preview"}, preview); err == nil {
+		t.Fatal("duplicate output accepted")
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := e.RenderOnce(0); err != nil {
+			t.Fatalf("a failing extra output must not fail the frame: %v", err)
+		}
+	}
+	if main.last[0].R != 1 {
+		t.Fatalf("main = %+v", main.last[0])
+	}
+	if math.Abs(float64(preview.last[0].R)-math.Sqrt(0.5)) > 1e-6 {
+		t.Fatalf("preview should post the raw composite: %+v", preview.last[0])
+	}
+	if slow.last[0].R != 0.5 {
+		t.Fatalf("slow = %+v", slow.last[0])
+	}
+	outs := e.Outputs()
+	if outs[0].Frames != 3 || outs[1].Frames != 1 || outs[1].Skipped != 2 || outs[2].Err != "unplugged" {
+		t.Fatalf("outputs = %+v", outs)
+	}
+
+	_ = e.SetOutputEnabled("preview", false)
+	preview.last = nil
+	_ = e.RenderOnce(0)
+	if preview.last != nil {
+		t.Fatal("disabled output written")
+	}
+	if err := e.RemoveOutput("broken"); err != nil || len(e.Outputs()) != 2 {
+		t.Fatalf("remove: %v", err)
+	}
+}
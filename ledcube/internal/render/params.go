@@ -0,0 +1,143 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ParamType is the value kind of a declared parameter.
+type ParamType string
+
+const (
+	ParamFloat ParamType = "float"
+	ParamInt   ParamType = "int"  // stored as float64, rounded on set
+	ParamBool  ParamType = "bool" // lives in Uniforms.Bools; SetParam accepts 0/1
+)
+
+// ParamSpec declares one tunable uniform so hosts can validate values and UIs
+// can build controls without knowing the renderer.
+type ParamSpec struct {
+	Name    string    `json:"name"`
+	Type    ParamType `json:"type"`
+	Min     float64   `json:"min"`
+	Max     float64   `json:"max"`
+	Default float64   `json:"default"`
+	Step    float64   `json:"step,omitempty"`
+	Units   string    `json:"units,omitempty"`
+	Desc    string    `json:"desc,omitempty"`
+	Group   string    `json:"group,omitempty"`
+}
+
+// ParamDescriber is optionally implemented by renderers that declare their
+// parameters. Once a renderer declares a schema, undeclared keys are rejected;
+// renderers without one accept anything (legacy behaviour).
+type ParamDescriber interface {
+	ParamSchema() []ParamSpec
+}
+
+// ParamInfo is a spec plus the scope it lives in and its current value.
+type ParamInfo struct {
+	ParamSpec
+	Scope string  `json:"scope"`
+	Value float64 `json:"value"`
+}
+
+// Clamp limits v to the spec's range, rounding ints and bools.
+func (p ParamSpec) Clamp(v float64) float64 {
+	if p.Type == ParamBool {
+		if v > 0.5 {
+			return 1
+		}
+		return 0
+	}
+	if p.Min < p.Max {
+		v = math.Max(p.Min, math.Min(p.Max, v))
+	}
+	if p.Type == ParamInt {
+		v = math.Round(v)
+	}
+	return v
+}
+
+// LookupParam finds name in specs.
+func LookupParam(specs []ParamSpec, name string) (ParamSpec, bool) {
+	for _, s := range specs {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return ParamSpec{}, false
+}
+
+// SchemaOf returns r's declared parameters, or nil (and false) if it declares none.
+func SchemaOf(r Renderer) ([]ParamSpec, bool) {
+	d, ok := r.(ParamDescriber)
+	if !ok {
+		return nil, false
+	}
+	return d.ParamSchema(), true
+}
+
+// ErrUnknownParam is wrapped by CheckParam/CheckBool for undeclared keys.
+var ErrUnknownParam = errors.New("unknown param")
+
+// CheckParam validates a numeric value for r (plus any extra specs) and
+// returns the value to store: clamped into range for declared keys, unchanged
+// for renderers without a schema. Non-finite values and keys r does not
+// declare are rejected.
+func CheckParam(r Renderer, extra []ParamSpec, name string, v float64) (float64, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("param %s: value %v is not finite", name, v)
+	}
+	specs, declared := SchemaOf(r)
+	if s, ok := LookupParam(specs, name); ok {
+		return s.Clamp(v), nil
+	}
+	if s, ok := LookupParam(extra, name); ok {
+		return s.Clamp(v), nil
+	}
+	if declared {
+		return 0, fmt.Errorf("%w %q for renderer %s", ErrUnknownParam, name, r.Name())
+	}
+	return v, nil
+}
+
+// CheckBool validates a boolean key for r the same way CheckParam does.
+func CheckBool(r Renderer, extra []ParamSpec, name string) error {
+	specs, declared := SchemaOf(r)
+	s, ok := LookupParam(specs, name)
+	if !ok {
+		s, ok = LookupParam(extra, name)
+	}
+	switch {
+	case ok && s.Type != ParamBool:
+		return fmt.Errorf("param %s is %s, not bool", name, s.Type)
+	case !ok && declared:
+		return fmt.Errorf("%w %q for renderer %s", ErrUnknownParam, name, r.Name())
+	}
+	return nil
+}
+
+// describe pairs specs with the current uniform values (Default when unset).
+func describe(out []ParamInfo, scope string, specs []ParamSpec, u *Uniforms) []ParamInfo {
+	for _, s := range specs {
+		pi := ParamInfo{ParamSpec: s, Scope: scope, Value: s.Default}
+		if u != nil {
+			if s.Type == ParamBool {
+				if b, ok := u.Bools[s.Name]; ok {
+					pi.Value = 0
+					if b {
+						pi.Value = 1
+					}
+				} else if v, ok := u.Params[s.Name]; ok {
+					pi.Value = s.Clamp(v)
+				}
+			} else if v, ok := u.Params[s.Name]; ok {
+				pi.Value = v
+			}
+		}
+		out = append(out, pi)
+	}
+	return out
+}
@@ -42,11 +42,6 @@ func FilmicToneMap(buf []Color, u *Uniforms) {
 	}
 }
 
-// DefaultToneMap now points to FilmicToneMap for convenience.
-func DefaultToneMap(buf []Color) {
-	FilmicToneMap(buf, &Uniforms{Params: map[string]float64{"ExposureEV": 0, "OutputGamma": 2.2}})
-}
-
 // DefaultLimiter applies a two-stage limiter:
 // 1) Per-LED "white cap": scales (R,G,B) so R+G+B <= WhiteCap (default 3.0 = no cap)
 // 2) Global current budget: estimates current and scales the whole frame to stay under Budget_mA
@@ -56,33 +51,23 @@ func DefaultToneMap(buf []Color) {
 //   - "LEDChan_mA" (mA per color channel at full scale; WS2812 ≈ 20, default 20)
 //   - "Budget_mA" (global budget in mA; if 0 or missing, limiter returns immediately)
 //   - "LimiterKnee" (fraction of budget where soft limiting begins; default 0.9)
+//
+// The "limiter" post stage runs the same code with chain-resolved params.
 func DefaultLimiter(buf []Color, u *Uniforms) {
 	if u == nil {
-        return
-    }
-    // Preview disables limiter (new) + honor legacy flag
-    if u.Params["PreviewMode"] > 0.5 || u.Params["PreviewBypass"] > 0.5 {
-        return
-    }
+		return
+	}
+	limit(buf, u.Params["WhiteCap"], LinearModel(u.Params["LEDChan_mA"]), u.Params["Budget_mA"], u.Params["LimiterKnee"])
+}
 
-	// Params
-	whiteCap := 3.0
-	chanmA := 20.0
-	budget := 0.0
-	knee := 0.9
-	if u.Params != nil {
-		if v, ok := u.Params["WhiteCap"]; ok && v > 0 {
-			whiteCap = v
-		}
-		if v, ok := u.Params["LEDChan_mA"]; ok && v > 0 {
-			chanmA = v
-		}
-		if v, ok := u.Params["Budget_mA"]; ok && v > 0 {
-			budget = v
-		}
-		if v, ok := u.Params["LimiterKnee"]; ok && v > 0 && v < 1 {
-			knee = v
-		}
+// limit is the limiter body; out-of-range params fall back to the defaults.
+// Idle current counts toward the budget but cannot be scaled away.
+func limit(buf []Color, whiteCap float64, model CurrentModel, budget, knee float64) {
+	if whiteCap <= 0 {
+		whiteCap = 3.0
+	}
+	if knee <= 0 || knee >= 1 {
+		knee = 0.9
 	}
 
 	// 1) Per-LED white cap
@@ -102,32 +87,25 @@ func DefaultLimiter(buf []Color, u *Uniforms) {
 		return
 	}
 	// Estimate total current
-	var total float64
-	cm := float32(chanmA)
-	for i := range buf {
-		total += float64((buf[i].R + buf[i].G + buf[i].B) * cm)
-	}
-
-	if total <= 0 {
+	idle, drive := model.FrameMilliamps(buf)
+	total := idle + drive
+	if drive <= 0 {
 		return
 	}
 	// Soft knee: start scaling gently after knee*budget, fully meet budget above budget
 	ratio := total / budget
+	target := budget
 	if ratio <= 1.0 {
 		if ratio <= knee {
 			return // under knee, do nothing
 		}
 		// between knee and 1.0 -> apply gentle scale
-		// map ratio in [knee,1] to scale s in [1, budget/total]
-		minS := budget / total
+		// map ratio in [knee,1] to a current in [total, budget]
 		t := (ratio - knee) / (1.0 - knee) // 0..1
-		s := float32(1.0 - t*(1.0-minS))
-		applyGlobalScale(buf, s)
-		return
+		target = total - t*(total-budget)
 	}
 	// Above budget: hard scale to meet budget
-	s := float32(budget / total)
-	applyGlobalScale(buf, s)
+	applyGlobalScale(buf, float32(model.ScaleFor(drive, target-idle)))
 }
 
 func applyGlobalScale(buf []Color, s float32) {
@@ -0,0 +1,26 @@
+package post
+
+import (
+	"testing"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+)
+
+func TestPresetsBuildAndRun(t *testing.T) {
+	u := &render.Uniforms{GlobalBrightness: 1, Params: map[string]float64{}}
+	for name, build := range map[string]func() (*render.PostChain, error){"preview": NewPreview, "led": NewLED} {
+		c, err := build()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		buf := []render.Color{{R: 4, G: 0.5, B: 0}}
+		for f := 0; f < 2; f++ {
+			if timing := c.Run(buf, u, nil); len(timing) != 3 {
+				t.Fatalf("%s: ran %d stages, want 3", name, len(timing))
+			}
+		}
+		if buf[0].R > 1 || buf[0].R < 0 {
+			t.Fatalf("%s: R = %v, want within 0..1", name, buf[0].R)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package render
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PostFunc processes a composited frame in place.
+type PostFunc func(buf []Color, a PostArgs)
+
+// PostStageDef registers a named post stage. Params doubles as the stage's
+// post-scope uniforms: a stage param not set in the chain falls back to the
+// post uniform of the same name, then to its Default.
+type PostStageDef struct {
+	Name   string      `json:"name"`
+	Desc   string      `json:"desc,omitempty"`
+	Params []ParamSpec `json:"params,omitempty"`
+	// New returns a fresh stage instance; stages with per-frame state
+	// (e.g. dithering residue) keep it in the closure.
+	New func() PostFunc `json:"-"`
+}
+
+var (
+	postMu     sync.RWMutex
+	postStages []*PostStageDef
+)
+
+// RegisterPostStage adds (or replaces) a stage kind in the global registry.
+func RegisterPostStage(d PostStageDef) {
+	if d.Name == "" || d.New == nil {
+		return
+	}
+	postMu.Lock()
+	defer postMu.Unlock()
+	for i, s := range postStages {
+		if s.Name == d.Name {
+			postStages[i] = &d
+			return
+		}
+	}
+	postStages = append(postStages, &d)
+}
+
+// PostStages lists registered stages in registration order.
+func PostStages() []PostStageDef {
+	postMu.RLock()
+	defer postMu.RUnlock()
+	out := make([]PostStageDef, len(postStages))
+	for i, s := range postStages {
+		out[i] = *s
+	}
+	return out
+}
+
+func lookupPostStage(name string) (*PostStageDef, bool) {
+	postMu.RLock()
+	defer postMu.RUnlock()
+	for _, s := range postStages {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// PostParams declares the post scope: every registered stage's params,
+// first declaration wins. PreviewMode lives in the global scope: it is a
+// renderer knob, not a post one.
+func PostParams() []ParamSpec {
+	var out []ParamSpec
+	for _, s := range PostStages() {
+		for _, p := range s.Params {
+			if !hasParam(out, p.Name) {
+				out = append(out, p)
+			}
+		}
+	}
+	return out
+}
+
+// PostStageConfig is one entry of a chain: a registered stage name plus any
+// explicit parameter settings.
+type PostStageConfig struct {
+	Stage  string             `json:"stage"`
+	Params map[string]float64 `json:"params,omitempty"`
+}
+
+// PostTiming is one stage's duration in the last frame.
+type PostTiming struct {
+	Stage string  `json:"stage"`
+	MS    float64 `json:"ms"`
+}
+
+// PostChain is an ordered list of stage instances. Chains are immutable once
+// built; edits build a new chain that shares the stage instances.
+type PostChain struct {
+	cfg  []PostStageConfig
+	defs []*PostStageDef
+	ops  []PostFunc
+}
+
+// NewPostChain instantiates cfg. Unknown stages and params are errors;
+// values are clamped to the stage's declared range.
+func NewPostChain(cfg []PostStageConfig) (*PostChain, error) {
+	c := &PostChain{}
+	for i, sc := range cfg {
+		def, ok := lookupPostStage(sc.Stage)
+		if !ok {
+			return nil, fmt.Errorf("post[%d]: unknown stage %q", i, sc.Stage)
+		}
+		params, err := checkStageParams(def, sc.Params)
+		if err != nil {
+			return nil, fmt.Errorf("post[%d] %s: %w", i, sc.Stage, err)
+		}
+		c.cfg = append(c.cfg, PostStageConfig{Stage: sc.Stage, Params: params})
+		c.defs = append(c.defs, def)
+		c.ops = append(c.ops, def.New())
+	}
+	return c, nil
+}
+
+func checkStageParams(def *PostStageDef, in map[string]float64) (map[string]float64, error) {
+	if len(in) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]float64, len(in))
+	for k, v := range in {
+		s, ok := LookupParam(def.Params, k)
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownParam, k)
+		}
+		v, err := checkScoped(def.Params, true, k, v)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = s.Clamp(v)
+	}
+	return out, nil
+}
+
+// Config returns a copy of the chain's configuration.
+func (c *PostChain) Config() []PostStageConfig {
+	if c == nil {
+		return nil
+	}
+	out := make([]PostStageConfig, len(c.cfg))
+	for i, sc := range c.cfg {
+		out[i] = PostStageConfig{Stage: sc.Stage, Params: copyParams(sc.Params)}
+	}
+	return out
+}
+
+// withParam returns a chain with stage i's param key set to v, sharing
+// stage instances (and their state) with c.
+func (c *PostChain) withParam(i int, key string, v float64) (*PostChain, error) {
+	if c == nil || i < 0 || i >= len(c.cfg) {
+		return nil, fmt.Errorf("post stage %d out of range", i)
+	}
+	params, err := checkStageParams(c.defs[i], map[string]float64{key: v})
+	if err != nil {
+		return nil, fmt.Errorf("post[%d] %s: %w", i, c.cfg[i].Stage, err)
+	}
+	n := &PostChain{cfg: c.Config(), defs: c.defs, ops: c.ops}
+	if n.cfg[i].Params == nil {
+		n.cfg[i].Params = map[string]float64{}
+	}
+	n.cfg[i].Params[key] = params[key]
+	return n, nil
+}
+
+// Run applies every stage in order; u is the post view (global + post).
+// Per-stage timings are written into timing (reused) and returned.
+func (c *PostChain) Run(buf []Color, u *Uniforms, timing []PostTiming) []PostTiming {
+	return c.run(buf, u, nil, timing)
+}
+
+func (c *PostChain) run(buf []Color, u *Uniforms, model *CurrentModel, timing []PostTiming) []PostTiming {
+	timing = timing[:0]
+	if c == nil {
+		return timing
+	}
+	for i, op := range c.ops {
+		t0 := time.Now()
+		op(buf, PostArgs{set: c.cfg[i].Params, u: u, specs: c.defs[i].Params, model: model})
+		timing = append(timing, PostTiming{Stage: c.cfg[i].Stage, MS: float64(time.Since(t0).Microseconds()) / 1000.0})
+	}
+	return timing
+}
+
+// PostArgs resolves a stage's parameters for one frame.
+type PostArgs struct {
+	set   map[string]float64
+	u     *Uniforms
+	specs []ParamSpec
+	model *CurrentModel
+}
+
+// Get returns the chain setting for name, else the post uniform, else the
+// spec default (0 if undeclared).
+func (a PostArgs) Get(name string) float64 {
+	if v, ok := a.set[name]; ok {
+		return v
+	}
+	s, declared := LookupParam(a.specs, name)
+	if a.u != nil {
+		if v, ok := a.u.Params[name]; ok {
+			if declared {
+				return s.Clamp(v)
+			}
+			return v
+		}
+	}
+	return s.Default
+}
+
+// Model is the engine's LED current model, or a linear one at the stage's
+// LEDChan_mA when none is set.
+func (a PostArgs) Model() CurrentModel {
+	if a.model != nil {
+		return *a.model
+	}
+	return LinearModel(a.Get("LEDChan_mA"))
+}
+
+// Uniforms is the post view of the frame (global + post scopes).
+func (a PostArgs) Uniforms() *Uniforms { return a.u }
+
+// DefaultPostChain reproduces the original pipeline: exposure → ACES →
+// gamma → limiter → clamp, driven by the post uniforms.
+func DefaultPostChain() []PostStageConfig {
+	return []PostStageConfig{
+		{Stage: "exposure"}, {Stage: "tonemap"}, {Stage: "gamma"}, {Stage: "limiter"}, {Stage: "clamp"},
+	}
+}
+
+func copyParams(m map[string]float64) map[string]float64 {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]float64, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+package render
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPostChainMatchesFilmicAndLimiter(t *testing.T) {
+	u := &Uniforms{Params: map[string]float64{"ExposureEV": 1, "OutputGamma": 2.2, "Budget_mA": 50, "LEDChan_mA": 20}}
+	in := []Color{{0.2, 0.4, 0.8}, {1, 1, 1}, {0, 0.1, 0}}
+
+	want := append([]Color(nil), in...)
+	FilmicToneMap(want, u)
+	DefaultLimiter(want, u)
+
+	c, err := NewPostChain(DefaultPostChain())
+	if err != nil {
+		t.Fatalf("default chain: %v", err)
+	}
+	got := append([]Color(nil), in...)
+	timing := c.Run(got, u, nil)
+	for i := range want {
+		if d := math.Abs(float64(got[i].R-want[i].R)) + math.Abs(float64(got[i].G-want[i].G)) + math.Abs(float64(got[i].B-want[i].B)); d > 1e-5 {
+			t.Fatalf("led %d: chain %+v, legacy %+v", i, got[i], want[i])
+		}
+	}
+	if len(timing) != 5 || timing[0].Stage != "exposure" || timing[4].Stage != "clamp" {
+		t.Fatalf("timings = %+v", timing)
+	}
+}
+
+func TestLimiterIgnoresPreviewMode(t *testing.T) {
+	// the preview look is an output's chain; a global flag never turns the
+	// LED limiter off
+	c, _ := NewPostChain([]PostStageConfig{{Stage: "limiter", Params: map[string]float64{"Budget_mA": 30}}})
+	u := &Uniforms{Params: map[string]float64{"PreviewMode": 1, "PreviewBypass": 1, "LEDChan_mA": 20}, Bools: map[string]bool{"PreviewMode": true}}
+	buf := []Color{{1, 1, 1}}
+	c.Run(buf, u, nil)
+	if buf[0].R >= 1 {
+		t.Fatalf("limiter bypassed in preview mode: %+v", buf[0])
+	}
+}
+
+func TestPostChainOrderAndParams(t *testing.T) {
+	// exposure before clamp keeps the doubled value clipped; clamp first does not
+	a, _ := NewPostChain([]PostStageConfig{{Stage: "exposure", Params: map[string]float64{"ExposureEV": 1}}, {Stage: "clamp"}})
+	b, _ := NewPostChain([]PostStageConfig{{Stage: "clamp"}, {Stage: "exposure", Params: map[string]float64{"ExposureEV": 1}}})
+	x := []Color{{0.75, 0.25, 0}}
+	y := []Color{{0.75, 0.25, 0}}
+	a.Run(x, nil, nil)
+	b.Run(y, nil, nil)
+	if x[0].R != 1 || x[0].G != 0.5 || y[0].R != 1.5 {
+		t.Fatalf("order not honoured: %+v %+v", x[0], y[0])
+	}
+
+	// explicit stage params win over post uniforms
+	u := &Uniforms{Params: map[string]float64{"ExposureEV": 3}}
+	z := []Color{{0.25, 0, 0}}
+	a.Run(z, u, nil)
+	if z[0].R != 0.5 {
+		t.Fatalf("stage param should win over uniform, got %v", z[0].R)
+	}
+
+	// saturation 0 collapses to luma
+	s, _ := NewPostChain([]PostStageConfig{{Stage: "saturation", Params: map[string]float64{"PostSaturation": 0}}})
+	g := []Color{{1, 0, 0}}
+	s.Run(g, nil, nil)
+	if math.Abs(float64(g[0].R-0.2126)) > 1e-6 || g[0].R != g[0].G || g[0].G != g[0].B {
+		t.Fatalf("saturation 0 = %+v", g[0])
+	}
+
+	// hue shift keeps gray gray and 120° maps red to green
+	h, _ := NewPostChain([]PostStageConfig{{Stage: "hueshift", Params: map[string]float64{"HueShiftDeg": 120}}})
+	px := []Color{{0.5, 0.5, 0.5}, {1, 0, 0}}
+	h.Run(px, nil, nil)
+	if math.Abs(float64(px[0].R-0.5)) > 1e-5 || math.Abs(float64(px[1].G-1)) > 1e-5 || math.Abs(float64(px[1].R)) > 1e-5 {
+		t.Fatalf("hueshift = %+v", px)
+	}
+}
+
+func TestPostChainRejectsUnknown(t *testing.T) {
+	if _, err := NewPostChain([]PostStageConfig{{Stage: "exposure"}, {Stage: "bloom"}}); err == nil {
+		t.Fatal("unknown stage accepted")
+	}
+	if _, err := NewPostChain([]PostStageConfig{{Stage: "gamma", Params: map[string]float64{"ExposureEV": 1}}}); !errors.Is(err, ErrUnknownParam) {
+		t.Fatalf("param of another stage accepted: %v", err)
+	}
+	c, err := NewPostChain([]PostStageConfig{{Stage: "gamma", Params: map[string]float64{"OutputGamma": 9}}})
+	if err != nil || c.Config()[0].Params["OutputGamma"] != 3 {
+		t.Fatalf("expected clamp to 3, got %v %v", c.Config(), err)
+	}
+}
+
+func TestEnginePostChainRuntimeEdit(t *testing.T) {
+	drv := &fakeDriver{}
+	e, err := NewEngine(Dimensions{X: 1, Y: 1, Z: 1}, []Vec3{{}}, drv, &fakeRenderer{name: "A", r: 0.25}, nil, &Resources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.PostChain()) != len(DefaultPostChain()) {
+		t.Fatalf("engine should start on the default chain, got %+v", e.PostChain())
+	}
+	if err := e.SetPostChain([]PostStageConfig{{Stage: "exposure"}, {Stage: "clamp"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetPostStageParam(0, "ExposureEV", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetPostStageParam(2, "ExposureEV", 1); err == nil {
+		t.Fatal("out-of-range stage index accepted")
+	}
+	if err := e.RenderOnce(0); err != nil {
+		t.Fatal(err)
+	}
+	if drv.last[0].R != 0.5 {
+		t.Fatalf("expected exposure +1 EV, got %v", drv.last[0].R)
+	}
+	if len(e.Last.Post) != 2 || e.Last.Post[0].Stage != "exposure" || e.Last.Post[1].Stage != "clamp" {
+		t.Fatalf("Last.Post = %+v", e.Last.Post)
+	}
+	if err := e.SetPostChain([]PostStageConfig{{Stage: "nope"}}); err == nil || len(e.PostChain()) != 2 {
+		t.Fatalf("bad chain should be rejected and leave the old one: %v %+v", err, e.PostChain())
+	}
+}
@@ -0,0 +1,182 @@
+package render
+
+import "math"
+
+// Built-in post stages. Colors are linear until "gamma"; stages after it
+// (limiter, clamp) see encoded values, as the original pipeline did.
+func init() {
+	stateless := func(f PostFunc) func() PostFunc { return func() PostFunc { return f } }
+
+	RegisterPostStage(PostStageDef{
+		Name: "exposure", Desc: "Linear gain of 2^EV",
+		Params: []ParamSpec{
+			{Name: "ExposureEV", Type: ParamFloat, Min: -8, Max: 8, Default: 0, Step: 0.1, Units: "EV", Desc: "Exposure before tone mapping", Group: "post"},
+		},
+		New: stateless(func(buf []Color, a PostArgs) {
+			ev := a.Get("ExposureEV")
+			if ev == 0 {
+				return
+			}
+			s := float32(math.Exp2(ev))
+			for i := range buf {
+				buf[i].R *= s
+				buf[i].G *= s
+				buf[i].B *= s
+			}
+		}),
+	})
+	RegisterPostStage(PostStageDef{
+		Name: "tonemap", Desc: "Filmic curve (ACES approximation or Reinhard)",
+		Params: []ParamSpec{
+			{Name: "ToneCurve", Type: ParamInt, Min: 0, Max: 1, Default: 0, Step: 1, Desc: "0 = ACES, 1 = Reinhard", Group: "post"},
+		},
+		New: stateless(func(buf []Color, a PostArgs) {
+			curve := acesApprox
+			if a.Get("ToneCurve") == 1 {
+				curve = reinhard
+			}
+			for i := range buf {
+				buf[i] = Color{R: curve(buf[i].R), G: curve(buf[i].G), B: curve(buf[i].B)}
+			}
+		}),
+	})
+	RegisterPostStage(PostStageDef{
+		Name: "saturation", Desc: "Scale chroma around Rec.709 luma",
+		Params: []ParamSpec{
+			{Name: "PostSaturation", Type: ParamFloat, Min: 0, Max: 2, Default: 1, Step: 0.01, Desc: "0 = gray, 1 = unchanged", Group: "color"},
+		},
+		New: stateless(func(buf []Color, a PostArgs) {
+			s := float32(a.Get("PostSaturation"))
+			if s == 1 {
+				return
+			}
+			for i := range buf {
+				c := buf[i]
+				y := 0.2126*c.R + 0.7152*c.G + 0.0722*c.B
+				buf[i] = Color{R: y + (c.R-y)*s, G: y + (c.G-y)*s, B: y + (c.B-y)*s}
+			}
+		}),
+	})
+	RegisterPostStage(PostStageDef{
+		Name: "hueshift", Desc: "Rotate hue about the gray axis",
+		Params: []ParamSpec{
+			{Name: "HueShiftDeg", Type: ParamFloat, Min: -180, Max: 180, Default: 0, Step: 1, Units: "°", Group: "color"},
+		},
+		New: stateless(func(buf []Color, a PostArgs) {
+			deg := a.Get("HueShiftDeg")
+			if deg == 0 {
+				return
+			}
+			m := hueMatrix(deg)
+			for i := range buf {
+				buf[i] = m.apply(buf[i])
+			}
+		}),
+	})
+	RegisterPostStage(PostStageDef{
+		Name: "colortemp", Desc: "White balance toward a blackbody color (6500 K = neutral)",
+		Params: []ParamSpec{
+			{Name: "ColorTempK", Type: ParamFloat, Min: 1500, Max: 15000, Default: 6500, Step: 50, Units: "K", Group: "color"},
+		},
+		New: stateless(func(buf []Color, a PostArgs) {
+			k := a.Get("ColorTempK")
+			if k == 6500 {
+				return
+			}
+			w := kelvinRGB(k)
+			n := kelvinRGB(6500)
+			r, g, b := float32(w[0]/n[0]), float32(w[1]/n[1]), float32(w[2]/n[2])
+			for i := range buf {
+				buf[i].R *= r
+				buf[i].G *= g
+				buf[i].B *= b
+			}
+		}),
+	})
+	RegisterPostStage(PostStageDef{
+		Name: "gamma", Desc: "Encode with 1/gamma",
+		Params: []ParamSpec{
+			{Name: "OutputGamma", Type: ParamFloat, Min: 1, Max: 3, Default: 2.2, Step: 0.01, Desc: "Output encoding gamma", Group: "post"},
+		},
+		New: stateless(func(buf []Color, a PostArgs) {
+			g := a.Get("OutputGamma")
+			if g == 1 {
+				return
+			}
+			ig := 1 / g
+			for i := range buf {
+				buf[i] = Color{R: powf(maxf(buf[i].R, 0), ig), G: powf(maxf(buf[i].G, 0), ig), B: powf(maxf(buf[i].B, 0), ig)}
+			}
+		}),
+	})
+	RegisterPostStage(PostStageDef{
+		Name: "limiter", Desc: "Per-LED white cap and global current budget",
+		Params: []ParamSpec{
+			{Name: "WhiteCap", Type: ParamFloat, Min: 0, Max: 3, Default: 3, Step: 0.01, Desc: "Per-LED cap on R+G+B (3 = off)", Group: "power"},
+			{Name: "LEDChan_mA", Type: ParamFloat, Min: 1, Max: 60, Default: 20, Step: 0.5, Units: "mA", Desc: "Current per channel at full scale (without a strip model)", Group: "power"},
+			{Name: "Budget_mA", Type: ParamFloat, Min: 0, Max: 200000, Default: 0, Step: 100, Units: "mA", Desc: "Global current budget (0 = off)", Group: "power"},
+			{Name: "LimiterKnee", Type: ParamFloat, Min: 0.5, Max: 0.99, Default: 0.9, Step: 0.01, Desc: "Fraction of budget where soft limiting starts", Group: "power"},
+		},
+		New: stateless(func(buf []Color, a PostArgs) {
+			limit(buf, a.Get("WhiteCap"), a.Model(), a.Get("Budget_mA"), a.Get("LimiterKnee"))
+		}),
+	})
+	RegisterPostStage(PostStageDef{
+		Name: "clamp", Desc: "Clamp channels to [0,1]",
+		New: stateless(func(buf []Color, _ PostArgs) {
+			for i := range buf {
+				buf[i] = Color{R: clamp01(buf[i].R), G: clamp01(buf[i].G), B: clamp01(buf[i].B)}
+			}
+		}),
+	})
+}
+
+func reinhard(x float32) float32 {
+	if x <= 0 {
+		return 0
+	}
+	return x / (1 + x)
+}
+
+type mat3 [3][3]float32
+
+func (m mat3) apply(c Color) Color {
+	return Color{
+		R: m[0][0]*c.R + m[0][1]*c.G + m[0][2]*c.B,
+		G: m[1][0]*c.R + m[1][1]*c.G + m[1][2]*c.B,
+		B: m[2][0]*c.R + m[2][1]*c.G + m[2][2]*c.B,
+	}
+}
+
+// hueMatrix rotates RGB about the (1,1,1) axis by deg, preserving gray.
+func hueMatrix(deg float64) mat3 {
+	th := deg * math.Pi / 180
+	c, s := math.Cos(th), math.Sin(th)
+	k := (1 - c) / 3
+	q := math.Sqrt(1.0/3) * s
+	a, b, d := float32(c+k), float32(k-q), float32(k+q)
+	return mat3{{a, b, d}, {d, a, b}, {b, d, a}}
+}
+
+// kelvinRGB approximates a blackbody's sRGB color (Tanner Helland's fit).
+func kelvinRGB(k float64) [3]float64 {
+	t := k / 100
+	var r, g, b float64
+	if t <= 66 {
+		r = 255
+		g = 99.4708025861*math.Log(t) - 161.1195681661
+	} else {
+		r = 329.698727446 * math.Pow(t-60, -0.1332047592)
+		g = 288.1221695283 * math.Pow(t-60, -0.0755148492)
+	}
+	switch {
+	case t >= 66:
+		b = 255
+	case t <= 19:
+		b = 0
+	default:
+		b = 138.5177312231*math.Log(t-10) - 305.0447927307
+	}
+	cl := func(x float64) float64 { return math.Max(1, math.Min(255, x)) / 255 }
+	return [3]float64{cl(r), cl(g), cl(b)}
+}
@@ -15,8 +15,28 @@ func New(name string) *Renderer {
 	return &Renderer{name: name, preset: "PanelChanSweep"}
 }
 
-func (r *Renderer) Name() string      { return r.name }
-func (r *Renderer) Presets() []string { return []string{"PanelChanSweep"} }
+func (r *Renderer) Name() string { return r.name }
+
+func (r *Renderer) Presets() []string {
+	return []string{"PanelChanSweep", "WhiteField", "PrimaryField", "GrayRamp", "PanelCompare"}
+}
+
+// Measurement presets for fitting a render.CalibProfile. They write drive
+// values straight out (no preview lift, no gamma), so run them with a post
+// chain of just "clamp" and the calibration removed:
+//   - WhiteField:   every LED at Level on all channels; compare panels/LEDs.
+//   - PrimaryField: one channel (Channel 0=R, 1=G, 2=B, 3=white) at Level;
+//     measured XYZ of each primary gives the matrix columns.
+//   - GrayRamp:     rows step from 0 at the bottom to Level at the top; fit
+//     per-channel gamma from the measured luminance per row.
+//   - PanelCompare: even panels at Level white, odd panels show Channel, so
+//     a reference panel sits next to the one being adjusted.
+const (
+	presetWhite   = "WhiteField"
+	presetPrimary = "PrimaryField"
+	presetRamp    = "GrayRamp"
+	presetCompare = "PanelCompare"
+)
 
 // Satisfy your interface
 func (r *Renderer) ApplyPreset(p string, u *render.Uniforms) {
@@ -24,6 +44,11 @@ func (r *Renderer) ApplyPreset(p string, u *render.Uniforms) {
 	if u == nil {
 		return
 	}
+	switch p {
+	case presetWhite, presetPrimary, presetRamp, presetCompare:
+		ensure(u, map[string]float64{"Level": 0.5, "Channel": 3})
+		return
+	}
 	ensure(u, map[string]float64{
 		"PanelAxis":     2,
 		"FlipX":         0,
@@ -40,20 +65,27 @@ func (r *Renderer) ApplyPreset(p string, u *render.Uniforms) {
 	})
 }
 
-// Optional: advertise tweakable knobs
-func (r *Renderer) Params() map[string]float64 {
-	// In Params():
-	return map[string]float64{
-		"PanelAxis": 2, // 0=X, 1=Y, 2=Z
-		"FlipX":     0, "FlipY": 0, "FlipZ": 0,
-		"Gamma":         1.8, // preview gamma lift
-		"LRGamma":       1.2, // left→right darkening curve ( >1 steeper right edge )
-		"TopWhitePow":   0.6, // bottom→top blend curve ( <1 quicker toward white )
-		"TopWhiteMix":   1.0, // 0..1 how hard to pull toward white at top
-		"BaseIntensity": 0.17, // overall intensity (pre-post)
-		"RightFloor":    0.0, // minimum brightness at far-right (0..1)
-		"Saturation":    1.0, // 0 = grayscale, 1 = full RGB
-		"PreviewScale":  0.65,
+// ParamSchema declares the knobs Render reads; defaults match its fallbacks.
+func (r *Renderer) ParamSchema() []render.ParamSpec {
+	f := func(name string, min, max, def float64, desc string) render.ParamSpec {
+		return render.ParamSpec{Name: name, Type: render.ParamFloat, Min: min, Max: max, Default: def, Step: 0.01, Group: "calib", Desc: desc}
+	}
+	flip := func(name string) render.ParamSpec {
+		return render.ParamSpec{Name: name, Type: render.ParamBool, Min: 0, Max: 1, Group: "orientation", Desc: "Mirror " + name[len(name)-1:]}
+	}
+	return []render.ParamSpec{
+		{Name: "PanelAxis", Type: render.ParamInt, Min: 0, Max: 2, Default: 2, Step: 1, Group: "orientation", Desc: "Panel axis: 0=X, 1=Y, 2=Z"},
+		flip("FlipX"), flip("FlipY"), flip("FlipZ"),
+		f("Gamma", 0.5, 3, 1.8, "Preview gamma lift"),
+		f("LRGamma", 0.2, 4, 1.2, "Left→right darkening curve (>1 steeper right edge)"),
+		f("TopWhitePow", 0.1, 4, 0.6, "Bottom→top blend curve (<1 quicker toward white)"),
+		f("TopWhiteMix", 0, 1, 1.0, "How hard to pull toward white at the top"),
+		f("BaseIntensity", 0, 1, 1.0, "Overall intensity (pre-post)"),
+		f("RightFloor", 0, 1, 0, "Minimum brightness at the far right"),
+		f("Saturation", 0, 1, 1.0, "0 = grayscale, 1 = full RGB"),
+		f("PreviewScale", 0, 1, 0.65, "Preview brightness scale"),
+		{Name: "Level", Type: render.ParamFloat, Min: 0, Max: 1, Default: 0.5, Step: 0.01, Group: "measure", Desc: "Drive level of measurement patterns"},
+		{Name: "Channel", Type: render.ParamInt, Min: 0, Max: 3, Default: 3, Step: 1, Group: "measure", Desc: "Pattern channel: 0=R, 1=G, 2=B, 3=white"},
 	}
 }
 
@@ -107,6 +139,11 @@ func (r *Renderer) Render(dst []render.Color, _ []render.Vec3, dim render.Dimens
 	if len(dst) < X*Y*Z {
 		return
 	}
+	switch r.preset {
+	case presetWhite, presetPrimary, presetRamp, presetCompare:
+		r.renderMeasure(dst, X, Y, Z, u)
+		return
+	}
 
 	panelAxis := int(pget(u, "PanelAxis", 2)) // default Z panels
 	flipX := bget(u, "FlipX", false) || pget(u, "FlipX", 0) > 0.5
@@ -245,3 +282,42 @@ func (r *Renderer) Render(dst []render.Color, _ []render.Vec3, dim render.Dimens
 		}
 	}
 }
+
+// renderMeasure draws the flat measurement patterns (see the preset list).
+func (r *Renderer) renderMeasure(dst []render.Color, X, Y, Z int, u *render.Uniforms) {
+	level := float32(clamp01(pget(u, "Level", 0.5)))
+	white := render.Color{R: level, G: level, B: level}
+	primary := white
+	switch int(pget(u, "Channel", 3)) {
+	case 0:
+		primary = render.Color{R: level}
+	case 1:
+		primary = render.Color{G: level}
+	case 2:
+		primary = render.Color{B: level}
+	}
+	i := 0
+	for z := 0; z < Z; z++ {
+		for y := 0; y < Y; y++ {
+			for x := 0; x < X; x++ {
+				c := white
+				switch r.preset {
+				case presetPrimary:
+					c = primary
+				case presetRamp:
+					s := level
+					if Y > 1 {
+						s *= float32(y) / float32(Y-1)
+					}
+					c = render.Color{R: s, G: s, B: s}
+				case presetCompare:
+					if z%2 == 1 {
+						c = primary
+					}
+				}
+				dst[i] = c
+				i++
+			}
+		}
+	}
+}
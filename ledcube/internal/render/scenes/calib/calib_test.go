@@ -149,3 +149,34 @@ func TestPanelChanSweepColorAtlas(t *testing.T) {
 		t.Fatalf("expected near-black at bottom-right, got %+v", bottomRight)
 	}
 }
+
+func TestMeasurementPresets(t *testing.T) {
+	dim := render.Dimensions{X: 2, Y: 3, Z: 2}
+	dst := make([]render.Color, dim.X*dim.Y*dim.Z)
+	render1 := func(preset string, level, channel float64) {
+		r := New("calib")
+		u := &render.Uniforms{Params: map[string]float64{}, Bools: map[string]bool{}}
+		r.ApplyPreset(preset, u)
+		u.Params["Level"], u.Params["Channel"] = level, channel
+		r.Render(dst, nil, dim, 0, u, nil)
+	}
+
+	render1("WhiteField", 0.4, 3)
+	for i, c := range dst {
+		if c != (render.Color{R: 0.4, G: 0.4, B: 0.4}) {
+			t.Fatalf("WhiteField led %d = %+v", i, c)
+		}
+	}
+	render1("PrimaryField", 1, 1)
+	if c := colorAt(dst, dim, 1, 2, 1); c != (render.Color{G: 1}) {
+		t.Fatalf("PrimaryField green = %+v", c)
+	}
+	render1("GrayRamp", 0.8, 3)
+	if b, m, top := colorAt(dst, dim, 0, 0, 0), colorAt(dst, dim, 0, 1, 0), colorAt(dst, dim, 0, 2, 0); b.R != 0 || m.R != 0.4 || top.R != 0.8 {
+		t.Fatalf("GrayRamp rows = %v %v %v", b.R, m.R, top.R)
+	}
+	render1("PanelCompare", 0.5, 0)
+	if ref, test := colorAt(dst, dim, 0, 0, 0), colorAt(dst, dim, 0, 0, 1); ref.G != 0.5 || test != (render.Color{R: 0.5}) {
+		t.Fatalf("PanelCompare = %+v / %+v", ref, test)
+	}
+}
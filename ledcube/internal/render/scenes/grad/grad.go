@@ -18,6 +18,13 @@ func New(name string) *Grad { return &Grad{name: name} }
 
 func (g *Grad) Name() string { return g.name }
 
+func (g *Grad) ParamSchema() []render.ParamSpec {
+	return []render.ParamSpec{
+		{Name: "Speed", Type: render.ParamFloat, Min: -2, Max: 2, Default: 0, Step: 0.01, Units: "Hz", Desc: "Hue animation rate"},
+		{Name: "Axis", Type: render.ParamInt, Min: 0, Max: 2, Default: 2, Step: 1, Desc: "Gradient axis: 0=X, 1=Y, 2=Z"},
+	}
+}
+
 func (g *Grad) Presets() []string { return []string{"XZ", "XY", "YZ", "Rainbow"} }
 
 func (g *Grad) ApplyPreset(name string, u *render.Uniforms) {
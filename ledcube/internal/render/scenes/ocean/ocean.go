@@ -24,6 +24,14 @@ func New(name string) *Renderer {
 }
 
 func (r *Renderer) Name() string { return r.name }
+
+// Release drops the height field once the engine retires this instance.
+func (r *Renderer) Release() {
+	r.H, r.V = nil, nil
+	r.X, r.Z = 0, 0
+	r.initd = false
+}
+
 func (r *Renderer) Presets() []string {
 	return []string{"CalmDawn", "SunnyDay", "Sunset", "NightStorm"}
 }
@@ -78,17 +86,38 @@ func (r *Renderer) ApplyPreset(p string, u *render.Uniforms) {
 	}
 }
 
-func (r *Renderer) Params() map[string]float64 {
-	return map[string]float64{
-		"TideAmp": 0.22, "TidePeriodS": 180, "WaveSpeed": 1.1, "Damping": 0.012, "Wind": 0.08,
-		"Foaminess": 0.18, "Choppiness": 0.45, "SkySat": 1.0, "DayPeriodS": 240.0, "Storminess": 0.0,
-		"LightningRate": 0.0, "WaterHue": 0.56, "WaterAbsorb": 0.18, "BaseIntensity": 1.0, "PreviewGamma": 1.6,
-		"SeaLevel": 0.65, // 0..1 baseline waterline (fraction of height)
-		"WaveAmp":  0.10, // scale of H contribution
-		"HMax":     0.5,  // hard cap for |H| to avoid runaway
-		// orientation helpers if needed
-		"FlipX": 0, "FlipZ": 1,
-		"SkyCycleScale": 0.0,
+// ParamSchema declares the knobs Render reads; defaults match its fallbacks.
+func (r *Renderer) ParamSchema() []render.ParamSpec {
+	f := func(name string, min, max, def, step float64, units, group, desc string) render.ParamSpec {
+		return render.ParamSpec{Name: name, Type: render.ParamFloat, Min: min, Max: max, Default: def, Step: step, Units: units, Group: group, Desc: desc}
+	}
+	flip := func(name string, def float64, desc string) render.ParamSpec {
+		return render.ParamSpec{Name: name, Type: render.ParamBool, Min: 0, Max: 1, Default: def, Group: "orientation", Desc: desc}
+	}
+	return []render.ParamSpec{
+		f("TideAmp", 0, 1, 0.22, 0.01, "", "water", "Tide swing as a fraction of height"),
+		f("TidePeriodS", 5, 600, 180, 1, "s", "water", "Tide period"),
+		f("WaveSpeed", 0, 3, 1.1, 0.01, "", "water", "Wave propagation speed"),
+		f("Damping", 0, 0.2, 0.012, 0.001, "", "water", "Wave energy loss per step"),
+		f("Wind", 0, 1, 0.08, 0.01, "", "water", "Wind forcing"),
+		f("Foaminess", 0, 1, 0.18, 0.01, "", "water", "Foam on steep slopes"),
+		f("Choppiness", 0, 1, 0.45, 0.01, "", "water", "Short-wave chop"),
+		f("SeaLevel", 0, 1, 0.45, 0.01, "", "water", "Baseline waterline (fraction of height)"),
+		f("WaveAmp", 0, 0.5, 0.10, 0.01, "", "water", "Scale of the height field"),
+		f("HMax", 0, 1, 0.35, 0.01, "", "water", "Hard cap on |H| to avoid runaway"),
+		f("WaterHue", 0, 1, 0.56, 0.01, "", "color", "Water hue"),
+		f("WaterAbsorb", 0, 1, 0.18, 0.01, "", "color", "Depth absorption"),
+		f("SkySat", 0, 1.5, 1.0, 0.01, "", "sky", "Sky saturation"),
+		f("DayPeriodS", 10, 3600, 240, 1, "s", "sky", "Day/night cycle period"),
+		f("SkyCycleScale", 0, 10, 0, 0.01, "", "sky", "Speed of the sky cycle (0 = fixed)"),
+		f("Storminess", 0, 1, 0, 0.01, "", "sky", "Storm darkening"),
+		f("LightningRate", 0, 2, 0, 0.01, "1/s", "sky", "Lightning flashes per second"),
+		f("BaseIntensity", 0, 2, 1.0, 0.01, "", "output", "Overall intensity (pre-post)"),
+		f("PreviewGamma", 1, 2.4, 1.6, 0.01, "", "output", "Preview gamma lift"),
+		f("Saturation", 0, 1, 1.0, 0.01, "", "output", "Preview saturation"),
+		f("SimSpeed", 0, 5, 1.0, 0.05, "", "output", "Simulation speed (on top of the engine TimeScale)"),
+		flip("FlipX", 0, "Mirror X"),
+		flip("FlipZ", 1, "Mirror Z"),
 	}
 }
 
@@ -103,6 +132,20 @@ func pget(u *render.Uniforms, key string, def float64) float64 {
 	return def
 }
 
+// read an on/off knob: a bool uniform wins, else the numeric param (>0.5)
+func flag(u *render.Uniforms, key string, def bool) bool {
+	if u != nil && u.Bools != nil {
+		if b, ok := u.Bools[key]; ok {
+			return b
+		}
+	}
+	d := 0.0
+	if def {
+		d = 1
+	}
+	return pget(u, key, d) > 0.5
+}
+
 // clamp to [0,1]
 func clamp01(v float64) float64 {
 	if v < 0 {
@@ -121,12 +164,12 @@ func (r *Renderer) Render(dst []render.Color, _ []render.Vec3, dim render.Dimens
 		return
 	}
 
-	timeScale := pget(u, "TimeScale", 1.0)
-	if timeScale < 0 {
-		timeScale = 0
+	simSpeed := pget(u, "SimSpeed", 1.0)
+	if simSpeed < 0 {
+		simSpeed = 0
 	}
-	phaseT := t * timeScale
-	simScale := timeScale
+	phaseT := t * simSpeed
+	simScale := simSpeed
 	if simScale < 0.01 {
 		simScale = 0.01
 	}
@@ -161,8 +204,8 @@ func (r *Renderer) Render(dst []render.Color, _ []render.Vec3, dim render.Dimens
 	waveAmp := pget(u, "WaveAmp", 0.10)
 	hMax := clamp01(pget(u, "HMax", 0.35))
 
-	flipX := pget(u, "FlipX", 0) > 0.5
-	flipZ := pget(u, "FlipZ", 1) > 0.5
+	flipX := flag(u, "FlipX", false)
+	flipZ := flag(u, "FlipZ", true)
 
 	// integrate sim (small fixed dt; use t for phase drift)
 	dt := clamp(r.prevT, 0, 1e9)
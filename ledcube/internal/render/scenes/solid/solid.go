@@ -17,6 +17,12 @@ func New(name string, c render.Color) *Solid { return &Solid{name: name, c: c} }
 
 func (s *Solid) Name() string { return s.name }
 
+func (s *Solid) ParamSchema() []render.ParamSpec {
+	return []render.ParamSpec{
+		{Name: "PulseHz", Type: render.ParamFloat, Min: 0, Max: 10, Default: 0, Step: 0.1, Units: "Hz", Desc: "Brightness pulse rate (0 = steady)"},
+	}
+}
+
 func (s *Solid) Presets() []string { return []string{"Red", "Green", "Blue", "White", "Black"} }
 
 func (s *Solid) ApplyPreset(name string, u *render.Uniforms) {
@@ -0,0 +1,229 @@
+package render
+
+import (
+	"fmt"
+	"math"
+)
+
+// Uniform scopes. Every other scope name is a layer: SceneLayer, NextLayer or
+// an overlay added with AddLayer.
+//
+// Inheritance is one level deep and explicit:
+//   - a renderer sees global overlaid by its own layer's uniforms (own wins);
+//   - post stages see global overlaid by post;
+//   - post never leaks into renderers and one layer never sees another's.
+//
+// Layer uniforms belong to the renderer instance: SetRenderer, ArmNext and
+// AddLayer start from an empty set and ApplyPreset only writes there, so
+// presets cannot clobber global/post settings and crossfades do not carry
+// the outgoing scene's knobs into the incoming one.
+const (
+	ScopeGlobal = "global" // engine knobs every renderer inherits (TimeScale, PreviewMode, ...)
+	ScopePost   = "post"   // tone map / limiter knobs (PostParams)
+)
+
+// GlobalParams declares the engine-level knobs kept in the global scope.
+// TimeScale and GlobalBrightness map onto the Uniforms fields of the same name.
+// Undeclared global keys are accepted as shared values any renderer may read.
+func GlobalParams() []ParamSpec {
+	return []ParamSpec{
+		{Name: "TimeScale", Type: ParamFloat, Min: 0, Max: 5, Default: 1, Step: 0.05, Desc: "Engine clock rate", Group: "global"},
+		{Name: "GlobalBrightness", Type: ParamFloat, Min: 0, Max: 1, Default: 1, Step: 0.01, Desc: "Master brightness", Group: "global"},
+		{Name: "PreviewMode", Type: ParamBool, Min: 0, Max: 1, Default: 0, Desc: "Scenes write linear light and leave gamma to the output chains", Group: "global"},
+		{Name: "PreviewBypass", Type: ParamBool, Min: 0, Max: 1, Default: 0, Desc: "Legacy alias of PreviewMode", Group: "global"},
+	}
+}
+
+// routeLocked picks the scope for an unscoped key: the scene renderer's own
+// schema first, then post, then global; anything else goes to the scene.
+func (e *Engine) routeLocked(name string) string {
+	if specs, _ := SchemaOf(e.layers[0].R); hasParam(specs, name) {
+		return SceneLayer
+	}
+	if hasParam(PostParams(), name) {
+		return ScopePost
+	}
+	if hasParam(GlobalParams(), name) {
+		return ScopeGlobal
+	}
+	return SceneLayer
+}
+
+func hasParam(specs []ParamSpec, name string) bool {
+	_, ok := LookupParam(specs, name)
+	return ok
+}
+
+// SetScopedParam sets name in one scope: ScopeGlobal, ScopePost or a layer
+// name. Values are clamped to the scope's declared range; post and layers
+// with a schema reject undeclared keys.
+func (e *Engine) SetScopedParam(scope, name string, v float64) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.setScopedParamLocked(scope, name, v)
+}
+
+// SetScopedBool is SetScopedParam for boolean uniforms.
+func (e *Engine) SetScopedBool(scope, name string, b bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.setScopedBoolLocked(scope, name, b)
+}
+
+func (e *Engine) setScopedParamLocked(scope, name string, v float64) error {
+	switch scope {
+	case ScopeGlobal:
+		v, err := checkScoped(GlobalParams(), false, name, v)
+		if err != nil {
+			return err
+		}
+		switch name {
+		case "TimeScale":
+			e.global.TimeScale = v
+		case "GlobalBrightness":
+			e.global.GlobalBrightness = v
+		default:
+			putParam(e.global, name, v)
+		}
+		return nil
+	case ScopePost:
+		v, err := checkScoped(PostParams(), true, name, v)
+		if err != nil {
+			return err
+		}
+		putParam(e.postU, name, v)
+		return nil
+	}
+	l := e.findLocked(scope)
+	if l == nil {
+		return fmt.Errorf("unknown scope %q", scope)
+	}
+	v, err := CheckParam(l.R, nil, name, v)
+	if err != nil {
+		return err
+	}
+	putParam(l.U, name, v)
+	return nil
+}
+
+func (e *Engine) setScopedBoolLocked(scope, name string, b bool) error {
+	var u *Uniforms
+	switch scope {
+	case ScopeGlobal:
+		if s, ok := LookupParam(GlobalParams(), name); ok && s.Type != ParamBool {
+			return fmt.Errorf("param %s is %s, not bool", name, s.Type)
+		}
+		u = e.global
+	case ScopePost:
+		s, ok := LookupParam(PostParams(), name)
+		if !ok {
+			return fmt.Errorf("%w %q in scope post", ErrUnknownParam, name)
+		}
+		if s.Type != ParamBool {
+			return fmt.Errorf("param %s is %s, not bool", name, s.Type)
+		}
+		u = e.postU
+	default:
+		l := e.findLocked(scope)
+		if l == nil {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+		if err := CheckBool(l.R, nil, name); err != nil {
+			return err
+		}
+		u = l.U
+	}
+	if u.Bools == nil {
+		u.Bools = map[string]bool{}
+	}
+	u.Bools[name] = b
+	return nil
+}
+
+// checkScoped validates against a fixed spec list; strict rejects undeclared keys.
+func checkScoped(specs []ParamSpec, strict bool, name string, v float64) (float64, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("param %s: value %v is not finite", name, v)
+	}
+	if s, ok := LookupParam(specs, name); ok {
+		return s.Clamp(v), nil
+	}
+	if strict {
+		return 0, fmt.Errorf("%w %q in scope post", ErrUnknownParam, name)
+	}
+	return v, nil
+}
+
+func putParam(u *Uniforms, name string, v float64) {
+	if u.Params == nil {
+		u.Params = map[string]float64{}
+	}
+	u.Params[name] = v
+}
+
+// inherit returns parent overlaid by own (own's keys win). Fields
+// (TimeScale, GlobalBrightness, sun/moon) always come from parent.
+func inherit(parent, own *Uniforms) *Uniforms {
+	out := newUniforms()
+	inheritInto(out, parent, own)
+	return out
+}
+
+// inheritInto is inherit writing into dst, whose maps are cleared and
+// reused, so the per-frame snapshots do not allocate.
+func inheritInto(dst, parent, own *Uniforms) {
+	clear(dst.Params)
+	clear(dst.Bools)
+	*dst = Uniforms{Params: dst.Params, Bools: dst.Bools}
+	for _, u := range []*Uniforms{parent, own} {
+		if u == nil {
+			continue
+		}
+		for k, v := range u.Params {
+			dst.Params[k] = v
+		}
+		for k, v := range u.Bools {
+			dst.Bools[k] = v
+		}
+	}
+	if parent != nil {
+		dst.GlobalBrightness, dst.TimeScale = parent.GlobalBrightness, parent.TimeScale
+		dst.SunDir, dst.MoonDir = parent.SunDir, parent.MoonDir
+	}
+}
+
+// splitUniforms distributes a legacy flat Uniforms (as passed to NewEngine)
+// into global, post and scene scopes by declared name.
+func splitUniforms(u *Uniforms) (global, post, scene *Uniforms) {
+	global = &Uniforms{Params: map[string]float64{}, Bools: map[string]bool{}}
+	post = &Uniforms{Params: map[string]float64{}, Bools: map[string]bool{}}
+	scene = &Uniforms{Params: map[string]float64{}, Bools: map[string]bool{}}
+	if u == nil {
+		return
+	}
+	global.GlobalBrightness, global.TimeScale = u.GlobalBrightness, u.TimeScale
+	global.SunDir, global.MoonDir = u.SunDir, u.MoonDir
+	pick := func(name string) *Uniforms {
+		switch {
+		case hasParam(PostParams(), name):
+			return post
+		case hasParam(GlobalParams(), name):
+			return global
+		}
+		return scene
+	}
+	for k, v := range u.Params {
+		switch k {
+		case "TimeScale":
+			global.TimeScale = v
+		case "GlobalBrightness":
+			global.GlobalBrightness = v
+		default:
+			pick(k).Params[k] = v
+		}
+	}
+	for k, v := range u.Bools {
+		pick(k).Bools[k] = v
+	}
+	return
+}
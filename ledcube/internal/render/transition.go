@@ -0,0 +1,135 @@
+package render
+
+import "math"
+
+// TransitionKind selects how an incoming layer is revealed as its opacity
+// (the crossfade progress) goes 0→1.
+type TransitionKind string
+
+const (
+	TransFade     TransitionKind = "fade"     // uniform alpha (ADR-0001 crossfade)
+	TransWipe     TransitionKind = "wipe"     // plane sweeps along Normal
+	TransIris     TransitionKind = "iris"     // sphere grows from Center
+	TransDissolve TransitionKind = "dissolve" // seeded random per-voxel order
+	TransCascade  TransitionKind = "cascade"  // panel by panel along Z
+)
+
+// Transition is a spatial reveal mask evaluated against pLUT positions ([0,1]^3).
+// Every voxel gets an order value s in [0,1]; at progress p it is revealed with
+// opacity clamp((p*(1+w) - s) / w), where w is Softness (edge width), so p=0
+// shows nothing and p=1 shows everything regardless of kind.
+type Transition struct {
+	Kind     TransitionKind
+	Normal   Vec3    // wipe direction (need not be unit length)
+	Center   Vec3    // iris origin
+	Softness float64 // edge width in order units; 0 picks a per-kind default
+	Seed     int64   // dissolve
+	Reverse  bool    // flip the order (iris closes, cascade runs back→front)
+}
+
+// WipeNormal converts an axis name ("x","-y",...) or, when axis is "", an
+// azimuth (degrees in the XZ plane from +X toward +Z) and elevation (degrees
+// toward +Y) into a wipe direction.
+func WipeNormal(axis string, azimuthDeg, elevationDeg float64) Vec3 {
+	switch axis {
+	case "x", "+x":
+		return Vec3{X: 1}
+	case "-x":
+		return Vec3{X: -1}
+	case "y", "+y":
+		return Vec3{Y: 1}
+	case "-y":
+		return Vec3{Y: -1}
+	case "z", "+z":
+		return Vec3{Z: 1}
+	case "-z":
+		return Vec3{Z: -1}
+	}
+	az := azimuthDeg * math.Pi / 180
+	el := elevationDeg * math.Pi / 180
+	return Vec3{X: math.Cos(el) * math.Cos(az), Y: math.Sin(el), Z: math.Cos(el) * math.Sin(az)}
+}
+
+// Mask writes per-voxel opacity for progress p (0..1) into dst.
+func (tr *Transition) Mask(dst []float32, pLUT []Vec3, dim Dimensions, p float64) {
+	p = clampUnit(p)
+	if tr == nil || tr.Kind == "" || tr.Kind == TransFade {
+		for i := range dst {
+			dst[i] = float32(p)
+		}
+		return
+	}
+	w := tr.Softness
+	if w <= 0 {
+		w = tr.defaultSoftness(dim)
+	}
+	edge := p * (1 + w)
+	for i := range dst {
+		var pos Vec3
+		if i < len(pLUT) {
+			pos = pLUT[i]
+		}
+		s := tr.order(i, pos, dim)
+		if tr.Reverse {
+			s = 1 - s
+		}
+		dst[i] = float32(clampUnit((edge - s) / w))
+	}
+}
+
+func (tr *Transition) defaultSoftness(dim Dimensions) float64 {
+	switch tr.Kind {
+	case TransDissolve:
+		return 0.05
+	case TransCascade:
+		if dim.Z > 0 {
+			return 1.0 / float64(dim.Z)
+		}
+	}
+	return 0.1
+}
+
+// order returns the voxel's reveal order in [0,1].
+func (tr *Transition) order(i int, pos Vec3, dim Dimensions) float64 {
+	switch tr.Kind {
+	case TransWipe:
+		n := tr.Normal
+		if n == (Vec3{}) {
+			n = Vec3{X: 1}
+		}
+		// project onto n, normalised by the cube's extent along n
+		h := 0.5 * (math.Abs(n.X) + math.Abs(n.Y) + math.Abs(n.Z))
+		d := (pos.X-0.5)*n.X + (pos.Y-0.5)*n.Y + (pos.Z-0.5)*n.Z
+		return clampUnit((d + h) / (2 * h))
+	case TransIris:
+		c := tr.Center
+		dx, dy, dz := pos.X-c.X, pos.Y-c.Y, pos.Z-c.Z
+		// farthest cube corner from c
+		fx, fy, fz := math.Max(c.X, 1-c.X), math.Max(c.Y, 1-c.Y), math.Max(c.Z, 1-c.Z)
+		maxD := math.Sqrt(fx*fx + fy*fy + fz*fz)
+		if maxD == 0 {
+			return 0
+		}
+		return clampUnit(math.Sqrt(dx*dx+dy*dy+dz*dz) / maxD)
+	case TransDissolve:
+		return hashUnit(uint64(tr.Seed), uint64(i))
+	case TransCascade:
+		if dim.Z <= 1 {
+			return 0
+		}
+		panel := math.Round(pos.Z * float64(dim.Z-1))
+		return panel / float64(dim.Z)
+	}
+	return 0
+}
+
+// hashUnit maps (seed, i) to a stable pseudo-random value in [0,1) (splitmix64).
+func hashUnit(seed, i uint64) float64 {
+	x := seed*0x9E3779B97F4A7C15 + i + 1
+	x ^= x >> 30
+	x *= 0xBF58476D1CE4E5B9
+	x ^= x >> 27
+	x *= 0x94D049BB133111EB
+	x ^= x >> 31
+	return float64(x>>11) / float64(1<<53)
+}
@@ -0,0 +1,94 @@
+package render
+
+import "testing"
+
+func cubeLUT(dim Dimensions) []Vec3 {
+	norm := func(i, n int) float64 {
+		if n <= 1 {
+			return 0
+		}
+		return float64(i) / float64(n-1)
+	}
+	out := make([]Vec3, 0, dim.X*dim.Y*dim.Z)
+	for z := 0; z < dim.Z; z++ {
+		for y := 0; y < dim.Y; y++ {
+			for x := 0; x < dim.X; x++ {
+				out = append(out, Vec3{norm(x, dim.X), norm(y, dim.Y), norm(z, dim.Z)})
+			}
+		}
+	}
+	return out
+}
+
+func TestTransitionEndpoints(t *testing.T) {
+	dim := Dimensions{X: 4, Y: 4, Z: 4}
+	lut := cubeLUT(dim)
+	mask := make([]float32, len(lut))
+	for _, tr := range []Transition{
+		{Kind: TransWipe, Normal: WipeNormal("", 30, 20)},
+		{Kind: TransIris, Center: Vec3{0.5, 0.5, 0.5}},
+		{Kind: TransIris, Center: Vec3{0, 0, 0}, Reverse: true},
+		{Kind: TransDissolve, Seed: 7},
+		{Kind: TransCascade},
+	} {
+		tr.Mask(mask, lut, dim, 0)
+		for i, m := range mask {
+			if m != 0 {
+				t.Fatalf("%s: voxel %d visible at progress 0 (%v)", tr.Kind, i, m)
+			}
+		}
+		tr.Mask(mask, lut, dim, 1)
+		for i, m := range mask {
+			if m != 1 {
+				t.Fatalf("%s: voxel %d not fully revealed at progress 1 (%v)", tr.Kind, i, m)
+			}
+		}
+	}
+}
+
+func TestWipeAxisOrder(t *testing.T) {
+	dim := Dimensions{X: 5, Y: 1, Z: 1}
+	lut := cubeLUT(dim)
+	mask := make([]float32, len(lut))
+	tr := Transition{Kind: TransWipe, Normal: WipeNormal("x", 0, 0), Softness: 0.01}
+	tr.Mask(mask, lut, dim, 0.5)
+	if mask[0] != 1 || mask[4] != 0 {
+		t.Fatalf("expected left revealed, right hidden at half progress: %v", mask)
+	}
+	tr.Normal = WipeNormal("-x", 0, 0)
+	tr.Mask(mask, lut, dim, 0.5)
+	if mask[0] != 0 || mask[4] != 1 {
+		t.Fatalf("expected -x wipe to reveal right first: %v", mask)
+	}
+}
+
+func TestDissolveSeeded(t *testing.T) {
+	dim := Dimensions{X: 8, Y: 8, Z: 1}
+	lut := cubeLUT(dim)
+	a := make([]float32, len(lut))
+	b := make([]float32, len(lut))
+	(&Transition{Kind: TransDissolve, Seed: 1}).Mask(a, lut, dim, 0.5)
+	(&Transition{Kind: TransDissolve, Seed: 1}).Mask(b, lut, dim, 0.5)
+	on := 0
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("same seed must give the same mask")
+		}
+		if a[i] == 1 {
+			on++
+		}
+	}
+	if on < len(a)/4 || on > 3*len(a)/4 {
+		t.Fatalf("expected roughly half revealed at 0.5, got %d/%d", on, len(a))
+	}
+}
+
+func TestCascadePanelOrder(t *testing.T) {
+	dim := Dimensions{X: 1, Y: 1, Z: 4}
+	lut := cubeLUT(dim)
+	mask := make([]float32, len(lut))
+	(&Transition{Kind: TransCascade}).Mask(mask, lut, dim, 0.3)
+	if !(mask[0] == 1 && mask[1] > 0 && mask[1] < 1 && mask[3] == 0) {
+		t.Fatalf("expected front panel done, second fading, back hidden: %v", mask)
+	}
+}
@@ -1,5 +1,7 @@
 package render
 
+import "sort"
+
 type Vec3 struct{ X, Y, Z float64 }
 type Color struct{ R, G, B float32 }
 
@@ -27,22 +29,43 @@ type Renderer interface {
 	Render(dst []Color, pLUT []Vec3, dim Dimensions, t float64, u *Uniforms, r *Resources)
 }
 
-type Registry struct{ m map[string]Renderer }
+// Factory constructs a fresh, independently stateful renderer instance.
+type Factory func() Renderer
+
+// Releaser is optionally implemented by renderers that hold state worth
+// freeing (simulation buffers, handles) once the engine discards an instance.
+type Releaser interface {
+	Release()
+}
+
+// Registry maps renderer names to factories, so every SetRenderer/ArmNext/
+// AddLayer gets its own instance even when the same scene runs twice.
+type Registry struct{ m map[string]Factory }
 
-func NewRegistry() *Registry { return &Registry{m: map[string]Renderer{}} }
+func NewRegistry() *Registry { return &Registry{m: map[string]Factory{}} }
 
-func (r *Registry) Register(rr Renderer) {
-	if rr == nil {
+func (r *Registry) Register(name string, f Factory) {
+	if name == "" || f == nil {
 		return
 	}
-	r.m[rr.Name()] = rr
+	r.m[name] = f
+}
+
+// New constructs a fresh instance of the named renderer.
+func (r *Registry) New(name string) (Renderer, bool) {
+	f, ok := r.m[name]
+	if !ok {
+		return nil, false
+	}
+	rr := f()
+	return rr, rr != nil
 }
 
-func (r *Registry) Get(name string) (Renderer, bool) { rr, ok := r.m[name]; return rr, ok }
 func (r *Registry) List() []string {
 	out := make([]string, 0, len(r.m))
 	for k := range r.m {
 		out = append(out, k)
 	}
+	sort.Strings(out)
 	return out
 }
@@ -0,0 +1,90 @@
+package render
+
+import (
+	"fmt"
+	"math"
+)
+
+// White modes: how much of a color an RGBW strip's white die takes over.
+const (
+	WhiteMin = "min" // the common part of R, G and B, as if the die were neutral
+	WhiteCCT = "cct" // the largest amount of the die's own tint that fits
+	WhiteOff = "off" // never light the white die
+)
+
+// White splits linear colors for strips with a white die. Frames stay RGB
+// through the engine; drivers with an RGBW layout split them at
+// quantization, the current model uses the same split to estimate the W
+// channel, and previews recombine it with the die's tint so they show what
+// the strip emits.
+type White struct {
+	Mode   string  `json:"mode"`   // WhiteMin (default), WhiteCCT or WhiteOff
+	Kelvin float64 `json:"kelvin"` // color temperature of the white die; 0 = 4500 K
+	Amount float64 `json:"amount"` // fraction of the extractable white moved to W; 0 = 1
+}
+
+// Validate checks the mode and ranges.
+func (w White) Validate() error {
+	switch w.Mode {
+	case "", WhiteMin, WhiteCCT, WhiteOff:
+	default:
+		return fmt.Errorf("white: unknown mode %q (min, cct or off)", w.Mode)
+	}
+	if math.IsNaN(w.Kelvin) || w.Kelvin < 0 || (w.Kelvin > 0 && (w.Kelvin < 1500 || w.Kelvin > 15000)) {
+		return fmt.Errorf("white: kelvin = %v, want within [1500, 15000]", w.Kelvin)
+	}
+	if math.IsNaN(w.Amount) || w.Amount < 0 || w.Amount > 1 {
+		return fmt.Errorf("white: amount = %v, want within [0, 1]", w.Amount)
+	}
+	return nil
+}
+
+// Tint is the white die's color in linear RGB, brightest channel 1.
+func (w White) Tint() Color {
+	k := w.Kelvin
+	if k <= 0 {
+		k = 4500
+	}
+	t := kelvinRGB(k)
+	return Color{R: float32(t[0]), G: float32(t[1]), B: float32(t[2])}
+}
+
+func (w White) amount() float32 {
+	if w.Amount <= 0 {
+		return 1
+	}
+	return float32(w.Amount)
+}
+
+// Split returns the RGB remainder and the W level for c (clamped to [0,1]).
+func (w White) Split(c Color) (rgb Color, white float32) {
+	rgb = Color{R: clamp01(c.R), G: clamp01(c.G), B: clamp01(c.B)}
+	switch w.Mode {
+	case WhiteOff:
+		return rgb, 0
+	case WhiteCCT:
+		t := w.Tint()
+		white = min(rgb.R/t.R, rgb.G/t.G, rgb.B/t.B) * w.amount()
+		rgb.R = max(0, rgb.R-white*t.R)
+		rgb.G = max(0, rgb.G-white*t.G)
+		rgb.B = max(0, rgb.B-white*t.B)
+	default:
+		white = min(rgb.R, rgb.G, rgb.B) * w.amount()
+		rgb.R -= white
+		rgb.G -= white
+		rgb.B -= white
+	}
+	return rgb, white
+}
+
+// Emit is the color the strip shows for c: the split remainder plus W at
+// the die's tint. With WhiteCCT it matches c; WhiteMin on a warm or cool
+// die shifts whites toward the die's color.
+func (w White) Emit(c Color) Color {
+	rgb, white := w.Split(c)
+	if white <= 0 {
+		return rgb
+	}
+	t := w.Tint()
+	return Color{R: min(1, rgb.R+white*t.R), G: min(1, rgb.G+white*t.G), B: min(1, rgb.B+white*t.B)}
+}
@@ -0,0 +1,72 @@
+package render
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b Color, tol float32) bool {
+	d := func(x, y float32) bool { return x-y <= tol && y-x <= tol }
+	return d(a.R, b.R) && d(a.G, b.G) && d(a.B, b.B)
+}
+
+func TestWhiteSplit(t *testing.T) {
+	c := Color{R: 0.9, G: 0.7, B: 0.5}
+	rgb, w := White{}.Split(c)
+	if !near(rgb, Color{R: 0.4, G: 0.2}, 1e-6) || w != 0.5 {
+		t.Fatalf("min split = %v %v", rgb, w)
+	}
+	rgb, w = White{Amount: 0.5}.Split(c)
+	if !near(rgb, Color{R: 0.65, G: 0.45, B: 0.25}, 1e-6) || w != 0.25 {
+		t.Fatalf("half split = %v %v", rgb, w)
+	}
+	if rgb, w = (White{Mode: WhiteOff}).Split(c); rgb != c || w != 0 {
+		t.Fatalf("off split = %v %v", rgb, w)
+	}
+
+	// cct takes the die's own tint, so it reproduces the color exactly
+	// and leaves at least one channel dark
+	warm := White{Mode: WhiteCCT, Kelvin: 3000}
+	rgb, w = warm.Split(c)
+	if w <= 0 || w > 1 || min(rgb.R, rgb.G, rgb.B) > 1e-6 {
+		t.Fatalf("cct split = %v %v", rgb, w)
+	}
+	if e := warm.Emit(c); !near(e, c, 1e-5) {
+		t.Fatalf("cct emit = %v, want %v", e, c)
+	}
+	// min on a warm die shows neutral white as warm
+	e := White{Kelvin: 3000}.Emit(Color{R: 1, G: 1, B: 1})
+	if e.R != 1 || !(e.B < e.G && e.G < 1) {
+		t.Fatalf("min emit on a 3000 K die = %v", e)
+	}
+
+	for _, bad := range []White{{Mode: "max"}, {Kelvin: 900}, {Amount: 1.5}, {Amount: math.NaN()}} {
+		if bad.Validate() == nil {
+			t.Errorf("%+v accepted", bad)
+		}
+	}
+}
+
+func TestCurrentModelWhiteChannel(t *testing.T) {
+	m, ok := StripModel("sk6812rgbw")
+	if !ok || m.WhiteMilliamps <= 0 {
+		t.Fatalf("sk6812rgbw = %+v", m)
+	}
+	// full white runs on the white die alone
+	if got := m.DriveMilliamps(Color{R: 1, G: 1, B: 1}); got != m.WhiteMilliamps {
+		t.Fatalf("white drive = %v, want %v", got, m.WhiteMilliamps)
+	}
+	if got := m.DriveMilliamps(Color{R: 1}); got != m.ChanMilliamps[0] {
+		t.Fatalf("red drive = %v", got)
+	}
+	// with W off the same strip lights R, G and B
+	off := m.SetWhite(White{Mode: WhiteOff})
+	if got := off.DriveMilliamps(Color{R: 1, G: 1, B: 1}); got != m.ChanMilliamps[0]+m.ChanMilliamps[1]+m.ChanMilliamps[2] {
+		t.Fatalf("white drive with W off = %v", got)
+	}
+	// the split is linear, so the limiter's gain still holds
+	c := Color{R: 0.8, G: 0.6, B: 0.3}
+	if a, b := m.DriveMilliamps(Color{R: c.R / 2, G: c.G / 2, B: c.B / 2}), m.DriveMilliamps(c)*m.Gain(0.5); math.Abs(a-b) > 1e-6 {
+		t.Fatalf("half brightness = %v mA, gain predicts %v", a, b)
+	}
+}
@@ -70,7 +70,18 @@ func (e Envelope) Eval(t float64) float64 {
 	return e.Keys[n-1].V
 }
 
-// BoolEval thresholds the envelope at 0.5 into a boolean.
+// BoolEval treats the envelope as a step track: each key's value holds until
+// the next key, thresholded at 0.5. Before the first key, the first key holds.
 func (e Envelope) BoolEval(t float64) bool {
-	return e.Eval(t) >= 0.5
+	if len(e.Keys) == 0 {
+		return false
+	}
+	v := e.Keys[0].V
+	for _, k := range e.Keys {
+		if k.T > t {
+			break
+		}
+		v = k.V
+	}
+	return v >= 0.5
 }
@@ -3,6 +3,7 @@ package sequence
 import (
 	"errors"
 	"math"
+	"strings"
 	"sync"
 )
 
@@ -117,15 +118,11 @@ func (p *Player) Tick(dt float64) {
 
 	clip, localT := p.currentClipAndLocalT()
 	// Evaluate params/bools for the active clip
-	for name, env := range clip.Params {
-		if p.hooks.SetParam != nil {
-			p.hooks.SetParam(name, env.Eval(localT))
-		}
+	for key, env := range clip.Params {
+		p.setParam(key, env.Eval(localT))
 	}
-	for name, env := range clip.Bools {
-		if p.hooks.SetBool != nil {
-			p.hooks.SetBool(name, env.BoolEval(localT))
-		}
+	for key, env := range clip.Bools {
+		p.setBool(key, env.BoolEval(localT))
 	}
 	// Crossfade logic
 	if clip.XFadeS > 0 {
@@ -135,6 +132,9 @@ func (p *Player) Tick(dt float64) {
 			nextIdx := p.nextIndex()
 			if !p.armed && nextIdx != -1 && p.hooks.ArmNext != nil {
 				nc := p.prog.Clips[nextIdx]
+				if p.hooks.SetTransition != nil {
+					p.hooks.SetTransition(p.transitionFor(clip))
+				}
 				p.hooks.ArmNext(nc.Renderer, nc.Preset)
 				p.armed = true
 				p.armedIndex = nextIdx
@@ -147,6 +147,9 @@ func (p *Player) Tick(dt float64) {
 			if alpha > 1 {
 				alpha = 1
 			}
+			if clip.Transition != nil {
+				alpha = easeApply(clip.Transition.Ease, alpha)
+			}
 			if p.hooks.SetCrossfade != nil && alpha != p.lastAlpha {
 				if alpha < 0 {
 					alpha = 0
@@ -165,6 +168,37 @@ func (p *Player) Tick(dt float64) {
 	}
 }
 
+// setParam routes a track key to the scoped hook when it has a scope prefix.
+func (p *Player) setParam(key string, v float64) {
+	scope, name := SplitScope(key)
+	switch {
+	case scope != "" && p.hooks.SetScopedParam != nil:
+		p.hooks.SetScopedParam(scope, name, v)
+	case p.hooks.SetParam != nil:
+		p.hooks.SetParam(name, v)
+	}
+}
+
+func (p *Player) setBool(key string, b bool) {
+	scope, name := SplitScope(key)
+	switch {
+	case scope != "" && p.hooks.SetScopedBool != nil:
+		p.hooks.SetScopedBool(scope, name, b)
+	case p.hooks.SetBool != nil:
+		p.hooks.SetBool(name, b)
+	}
+}
+
+// SplitScope splits a track key "scope.name" at its last dot; unscoped keys
+// return scope "". Scopes may contain dots ("scene.next.Gamma").
+func SplitScope(key string) (scope, name string) {
+	i := strings.LastIndexByte(key, '.')
+	if i < 0 {
+		return "", key
+	}
+	return key[:i], key[i+1:]
+}
+
 func (p *Player) currentClipAndLocalT() (Clip, float64) {
 	acc := 0.0
 	for i := 0; i < p.idx; i++ {
@@ -174,6 +208,19 @@ func (p *Player) currentClipAndLocalT() (Clip, float64) {
 	return p.prog.Clips[p.idx], localT
 }
 
+// transitionFor returns the clip's outgoing transition with the program seed
+// filled in for dissolves that don't set their own.
+func (p *Player) transitionFor(c Clip) *Transition {
+	if c.Transition == nil {
+		return nil
+	}
+	t := *c.Transition
+	if t.Seed == 0 {
+		t.Seed = p.prog.Seed
+	}
+	return &t
+}
+
 func (p *Player) totalDuration() float64 {
 	total := 0.0
 	for _, c := range p.prog.Clips {
@@ -76,3 +76,58 @@ func TestSequencerCrossfade(t *testing.T) {
 		}
 	}
 }
+
+func TestSequencerTransitionAndEase(t *testing.T) {
+	var got *Transition
+	var alphas []float64
+	h := Hooks{
+		SetRenderer:   func(name, preset string) {},
+		ArmNext:       func(name, preset string) {},
+		SetTransition: func(tr *Transition) { got = tr },
+		SetCrossfade:  func(a float64) { alphas = append(alphas, a) },
+	}
+	p := NewPlayer(h)
+	_ = p.Load(Program{Version: VersionV2, Seed: 42, Clips: []Clip{
+		{Name: "A", Renderer: "solid", DurationS: 4, XFadeS: 2,
+			Transition: &Transition{Kind: "dissolve", Ease: "smooth"}},
+		{Name: "B", Renderer: "grad", DurationS: 4},
+	}})
+	p.Start()
+	p.Tick(2.5) // 0.5s into the 2s fade window -> linear 0.25
+	if got == nil || got.Kind != "dissolve" || got.Seed != 42 {
+		t.Fatalf("expected dissolve with program seed, got %+v", got)
+	}
+	last := alphas[len(alphas)-1]
+	if want := 0.25 * 0.25 * (3 - 2*0.25); last < want-1e-9 || last > want+1e-9 {
+		t.Fatalf("expected smooth-eased alpha %v, got %v", want, last)
+	}
+}
+
+func TestSequencerScopedTracks(t *testing.T) {
+	got := map[string]float64{}
+	h := Hooks{
+		SetParam:       func(name string, v float64) { got[name] = v },
+		SetBool:        func(name string, b bool) { got["bool:"+name] = 2 }, // unscoped fallback
+		SetScopedParam: func(scope, name string, v float64) { got[scope+"|"+name] = v },
+	}
+	p := NewPlayer(h)
+	flat := Envelope{Keys: []Keyframe{{T: 0, V: 2}}}
+	prog := Program{
+		Version: VersionV2,
+		Clips: []Clip{{
+			Name: "A", Renderer: "ocean", DurationS: 4,
+			Params: map[string]Envelope{"TideAmp": flat, "post.ExposureEV": flat, "scene.next.Gamma": flat},
+			Bools:  map[string]Envelope{"global.PreviewMode": flat},
+		}},
+	}
+	if err := p.Load(prog); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	p.Start()
+	p.Tick(0.1)
+	for _, k := range []string{"TideAmp", "post|ExposureEV", "scene.next|Gamma", "bool:PreviewMode"} {
+		if got[k] != 2 {
+			t.Fatalf("expected %s to be set, got %v", k, got)
+		}
+	}
+}
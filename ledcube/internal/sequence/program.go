@@ -0,0 +1,100 @@
+package sequence
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/docs"
+)
+
+// Program format versions understood by ParseProgram.
+const (
+	VersionV1 = "seq.v1" // clips only; automation is not serialized
+	VersionV2 = "seq.v2" // clips + per-clip param/bool envelopes and transitions
+)
+
+var (
+	schemaOnce sync.Once
+	schemas    map[string]*schema
+	schemaErr  error
+)
+
+func loadSchemas() (map[string]*schema, error) {
+	schemaOnce.Do(func() {
+		schemas = map[string]*schema{}
+		for _, v := range []string{VersionV1, VersionV2} {
+			b, err := docs.Schemas.ReadFile(v + ".schema.json")
+			if err != nil {
+				schemaErr = err
+				return
+			}
+			s, err := parseSchema(b)
+			if err != nil {
+				schemaErr = fmt.Errorf("%s schema: %w", v, err)
+				return
+			}
+			schemas[v] = s
+		}
+	})
+	return schemas, schemaErr
+}
+
+// ParseProgram validates a program document against the schema for its
+// "version" (docs/seq.v1.schema.json or docs/seq.v2.schema.json) and decodes it.
+// A document without one predates versioning and is read as seq.v1.
+// Violations are returned as a *ValidationError listing every offending field.
+// Envelope keys are sorted by time so Eval can assume ascending order.
+func ParseProgram(data []byte) (Program, error) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return Program{}, fmt.Errorf("program json: %w", err)
+	}
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return Program{}, &ValidationError{Errors: []FieldError{{"", "expected object, got " + typeName(doc)}}}
+	}
+	if _, present := obj["version"]; !present {
+		obj["version"] = VersionV1
+	}
+	version, _ := obj["version"].(string)
+	all, err := loadSchemas()
+	if err != nil {
+		return Program{}, err
+	}
+	s, ok := all[version]
+	if !ok {
+		msg := fmt.Sprintf("unsupported version %q (want %s or %s)", version, VersionV1, VersionV2)
+		return Program{}, &ValidationError{Version: version, Errors: []FieldError{{"version", msg}}}
+	}
+
+	var errs []FieldError
+	s.validate(s, "", doc, &errs)
+	if len(errs) > 0 {
+		return Program{}, &ValidationError{Version: version, Errors: errs}
+	}
+
+	var p Program
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Program{}, fmt.Errorf("program json: %w", err)
+	}
+	p.Version = version
+	for i := range p.Clips {
+		c := &p.Clips[i]
+		if version == VersionV1 {
+			// v1 never carried automation; keep old files behaving exactly as before.
+			c.Params, c.Bools, c.Transition = nil, nil, nil
+			continue
+		}
+		sortKeys(c.Params)
+		sortKeys(c.Bools)
+	}
+	return p, nil
+}
+
+func sortKeys(m map[string]Envelope) {
+	for _, env := range m {
+		sort.SliceStable(env.Keys, func(i, j int) bool { return env.Keys[i].T < env.Keys[j].T })
+	}
+}
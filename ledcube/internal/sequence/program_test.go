@@ -0,0 +1,91 @@
+package sequence
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseProgramV1Unchanged(t *testing.T) {
+	data, err := os.ReadFile("../../docs/examples/seq-demo.json")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	p, err := ParseProgram(data)
+	if err != nil {
+		t.Fatalf("parse v1: %v", err)
+	}
+	if p.Version != VersionV1 || len(p.Clips) != 2 || p.Clips[0].XFadeS != 2 {
+		t.Fatalf("unexpected program: %+v", p)
+	}
+}
+
+func TestParseProgramV2Envelopes(t *testing.T) {
+	data, err := os.ReadFile("../../docs/examples/seq-v2-demo.json")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	p, err := ParseProgram(data)
+	if err != nil {
+		t.Fatalf("parse v2: %v", err)
+	}
+	tide, ok := p.Clips[0].Params["TideAmp"]
+	if !ok || len(tide.Keys) != 2 {
+		t.Fatalf("expected TideAmp envelope, got %+v", p.Clips[0].Params)
+	}
+	if v := tide.Eval(8); v != 0.35 {
+		t.Fatalf("expected 0.35 at t=8, got %v", v)
+	}
+	if !p.Clips[0].Bools["FlipZ"].BoolEval(3) {
+		t.Fatalf("expected FlipZ bool track to be on")
+	}
+}
+
+func TestParseProgramV2SortsKeys(t *testing.T) {
+	p, err := ParseProgram([]byte(`{"version":"seq.v2","clips":[{"name":"a","renderer":"solid","durationS":4,
+		"params":{"X":{"keys":[{"t":4,"v":1},{"t":0,"v":0}]}}}]}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if v := p.Clips[0].Params["X"].Eval(2); v != 0.5 {
+		t.Fatalf("expected 0.5 mid-segment after sort, got %v", v)
+	}
+}
+
+func TestParseProgramFieldErrors(t *testing.T) {
+	_, err := ParseProgram([]byte(`{"version":"seq.v2","clips":[{"name":"a","renderer":"solid","durationS":0,
+		"params":{"TideAmp":{"keys":[{"t":"soon","v":1,"ease":"bouncy"}]}},"duration":3}]}`))
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	msg := err.Error()
+	for _, want := range []string{
+		"clips[0].durationS: must be > 0",
+		"clips[0].duration: unknown field",
+		"clips[0].params.TideAmp.keys[0].t: expected number, got string",
+		"clips[0].params.TideAmp.keys[0].ease: must be one of linear, smooth, cubic",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("missing %q in %q", want, msg)
+		}
+	}
+}
+
+func TestParseProgramVersion(t *testing.T) {
+	// show files from before versioning load as v1, automation dropped
+	p, err := ParseProgram([]byte(`{"clips":[{"name":"a","renderer":"grad","durationS":5,"params":{"x":{"keys":[{"t":0,"v":1}]}}}]}`))
+	if err != nil {
+		t.Fatalf("unversioned program: %v", err)
+	}
+	if p.Version != VersionV1 || len(p.Clips) != 1 || p.Clips[0].Params != nil {
+		t.Fatalf("unversioned program read as %+v, want v1", p)
+	}
+	if _, err := ParseProgram([]byte(`{"version":null,"clips":[]}`)); err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Fatalf("expected unsupported version error for null, got %v", err)
+	}
+	if _, err := ParseProgram([]byte(`{"version":"seq.v9","clips":[]}`)); err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Fatalf("expected unsupported version error, got %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package sequence
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// schema is the subset of JSON Schema (draft 2020-12) used by docs/seq.*.schema.json:
+// type, const, enum, required, properties, additionalProperties, items,
+// minItems, minimum/exclusiveMinimum/maximum and local $ref into $defs.
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Defs                 map[string]*schema `json:"$defs"`
+	Type                 json.RawMessage    `json:"type"`
+	Const                json.RawMessage    `json:"const"`
+	Enum                 []any              `json:"enum"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	Minimum              *float64           `json:"minimum"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum"`
+	Maximum              *float64           `json:"maximum"`
+}
+
+// FieldError reports one schema violation at a JSON path like "clips[1].params.TideAmp.keys[0].t".
+type FieldError struct {
+	Path string
+	Msg  string
+}
+
+func (e FieldError) Error() string {
+	if e.Path == "" {
+		return e.Msg
+	}
+	return e.Path + ": " + e.Msg
+}
+
+// ValidationError collects every FieldError found in a program document.
+type ValidationError struct {
+	Version string
+	Errors  []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		lines[i] = fe.Error()
+	}
+	return fmt.Sprintf("invalid %s program: %s", e.Version, strings.Join(lines, "; "))
+}
+
+func parseSchema(b []byte) (*schema, error) {
+	var s schema
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// validate appends violations of v against s to errs. root resolves $ref.
+func (s *schema) validate(root *schema, path string, v any, errs *[]FieldError) {
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/$defs/")
+		def, ok := root.Defs[name]
+		if !ok {
+			*errs = append(*errs, FieldError{path, "schema: unresolved $ref " + s.Ref})
+			return
+		}
+		def.validate(root, path, v, errs)
+		return
+	}
+
+	if types := s.types(); len(types) > 0 {
+		ok := false
+		for _, t := range types {
+			if hasType(v, t) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			*errs = append(*errs, FieldError{path, fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), typeName(v))})
+			return
+		}
+	}
+	if len(s.Const) > 0 {
+		var c any
+		_ = json.Unmarshal(s.Const, &c)
+		if !reflect.DeepEqual(c, v) {
+			*errs = append(*errs, FieldError{path, fmt.Sprintf("must be %s", s.Const)})
+		}
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if reflect.DeepEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			opts := make([]string, len(s.Enum))
+			for i, e := range s.Enum {
+				opts[i] = fmt.Sprint(e)
+			}
+			*errs = append(*errs, FieldError{path, fmt.Sprintf("must be one of %s, got %v", strings.Join(opts, ", "), v)})
+		}
+	}
+
+	switch x := v.(type) {
+	case float64:
+		if s.Minimum != nil && x < *s.Minimum {
+			*errs = append(*errs, FieldError{path, fmt.Sprintf("must be >= %v, got %v", *s.Minimum, x)})
+		}
+		if s.ExclusiveMinimum != nil && x <= *s.ExclusiveMinimum {
+			*errs = append(*errs, FieldError{path, fmt.Sprintf("must be > %v, got %v", *s.ExclusiveMinimum, x)})
+		}
+		if s.Maximum != nil && x > *s.Maximum {
+			*errs = append(*errs, FieldError{path, fmt.Sprintf("must be <= %v, got %v", *s.Maximum, x)})
+		}
+	case []any:
+		if s.MinItems != nil && len(x) < *s.MinItems {
+			*errs = append(*errs, FieldError{path, fmt.Sprintf("must have at least %d item(s)", *s.MinItems)})
+		}
+		if s.Items != nil {
+			for i, item := range x {
+				s.Items.validate(root, fmt.Sprintf("%s[%d]", path, i), item, errs)
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := x[name]; !ok {
+				*errs = append(*errs, FieldError{join(path, name), "required"})
+			}
+		}
+		// Walk keys in order so error lists are stable.
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if ps, ok := s.Properties[k]; ok {
+				ps.validate(root, join(path, k), x[k], errs)
+				continue
+			}
+			switch ap := strings.TrimSpace(string(s.AdditionalProperties)); {
+			case ap == "" || ap == "true":
+			case ap == "false":
+				*errs = append(*errs, FieldError{join(path, k), "unknown field"})
+			default:
+				var sub schema
+				if err := json.Unmarshal(s.AdditionalProperties, &sub); err == nil {
+					sub.validate(root, join(path, k), x[k], errs)
+				}
+			}
+		}
+	}
+}
+
+func (s *schema) types() []string {
+	if len(s.Type) == 0 {
+		return nil
+	}
+	var one string
+	if err := json.Unmarshal(s.Type, &one); err == nil {
+		return []string{one}
+	}
+	var many []string
+	_ = json.Unmarshal(s.Type, &many)
+	return many
+}
+
+func hasType(v any, t string) bool {
+	switch t {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "null":
+		return v == nil
+	}
+	return false
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
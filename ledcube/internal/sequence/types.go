@@ -1,5 +1,7 @@
 package sequence
 
+import "encoding/json"
+
 // Keyframe represents a value at time T (seconds) with an easing function
 // that applies to the segment starting at this keyframe.
 type Keyframe struct {
@@ -8,26 +10,65 @@ type Keyframe struct {
 	Ease string  `json:"ease,omitempty"` // "linear","smooth","cubic"
 }
 
+// UnmarshalJSON accepts a boolean "v" (bool tracks) as 1/0.
+func (k *Keyframe) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		T    float64         `json:"t"`
+		V    json.RawMessage `json:"v"`
+		Ease string          `json:"ease"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	k.T, k.Ease, k.V = raw.T, raw.Ease, 0
+	switch string(raw.V) {
+	case "", "null", "false":
+	case "true":
+		k.V = 1
+	default:
+		if err := json.Unmarshal(raw.V, &k.V); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Envelope is a sorted list of keyframes; Eval(t) interpolates a value.
 type Envelope struct {
-	Keys []Keyframe `json:"-"` // populated after unmarshal or by code
+	Keys []Keyframe `json:"keys"`
 }
 
 // Clip is one segment of a show: selects a renderer + preset, sets duration,
 // optional crossfade into the NEXT clip, and controls parameter automation.
 type Clip struct {
-	Name      string               `json:"name"`
-	Renderer  string               `json:"renderer"`
-	Preset    string               `json:"preset,omitempty"`
-	DurationS float64              `json:"durationS"`
-	XFadeS    float64              `json:"xFadeS,omitempty"`
-	Params    map[string]Envelope  `json:"-"` // numeric params over time
-	Bools     map[string]Envelope  `json:"-"` // 0..1 thresholded to bool
+	Name      string  `json:"name"`
+	Renderer  string  `json:"renderer"`
+	Preset    string  `json:"preset,omitempty"`
+	DurationS float64 `json:"durationS"`
+	XFadeS    float64 `json:"xFadeS,omitempty"`
+	// Transition shapes the crossfade into the NEXT clip (nil = uniform fade).
+	Transition *Transition         `json:"transition,omitempty"`
+	Params     map[string]Envelope `json:"params,omitempty"` // numeric params over time (seq.v2)
+	Bools      map[string]Envelope `json:"bools,omitempty"`  // step tracks, v>=0.5 is true (seq.v2)
+}
+
+// Transition describes how the next clip is revealed during the XFadeS window.
+// The player's crossfade alpha, shaped by Ease, drives the transition progress.
+type Transition struct {
+	Kind         string    `json:"kind,omitempty"`         // "fade","wipe","iris","dissolve","cascade"
+	Axis         string    `json:"axis,omitempty"`         // wipe: "x","-x","y","-y","z","-z"
+	AzimuthDeg   float64   `json:"azimuthDeg,omitempty"`   // wipe without axis: direction in XZ from +X toward +Z
+	ElevationDeg float64   `json:"elevationDeg,omitempty"` // wipe without axis: tilt toward +Y
+	Center       []float64 `json:"center,omitempty"`       // iris: [x,y,z] in 0..1 (default cube centre)
+	Softness     float64   `json:"softness,omitempty"`     // edge width 0..1 (0 = per-kind default)
+	Seed         int64     `json:"seed,omitempty"`         // dissolve (0 = program seed)
+	Reverse      bool      `json:"reverse,omitempty"`      // iris closes, wipes/cascade run backwards
+	Ease         string    `json:"ease,omitempty"`         // "linear","smooth","cubic" applied to alpha
 }
 
 // Program is a full sequence of clips.
 type Program struct {
-	Version string `json:"version"` // e.g., "seq.v1"
+	Version string `json:"version"` // VersionV1 or VersionV2
 	Loop    bool   `json:"loop,omitempty"`
 	Seed    int64  `json:"seed,omitempty"`
 	Clips   []Clip `json:"clips"`
@@ -49,9 +90,17 @@ type Hooks struct {
 	// Parameter and boolean setters for the ACTIVE renderer.
 	SetParam func(name string, v float64)
 	SetBool  func(name string, b bool)
+	// Scoped setters for "scope.name" track keys (e.g. "post.ExposureEV",
+	// "global.TimeScale", "fx.Speed"). Optional: without them the scope is
+	// dropped and the key goes to SetParam/SetBool.
+	SetScopedParam func(scope, name string, v float64)
+	SetScopedBool  func(scope, name string, b bool)
 	// Prepare the next renderer/preset for crossfade.
-	ArmNext      func(name, preset string)
-	SetCrossfade func(alpha float64) // 0..1 mix between active and armed
+	ArmNext func(name, preset string)
+	// Select the transition for the upcoming crossfade; called before ArmNext
+	// with nil when the clip uses the plain fade. Optional.
+	SetTransition func(t *Transition)
+	SetCrossfade  func(alpha float64) // 0..1 mix between active and armed
 }
 
 // Player owns the current Program timeline and uses Hooks to drive the engine.
@@ -63,9 +112,9 @@ type Player struct {
 	idx  int     // current clip index
 
 	// crossfade bookkeeping
-	armedIndex   int  // which clip is armed next (-1 means none)
-	armed        bool // whether next is armed
-	lastAlpha    float64
+	armedIndex int  // which clip is armed next (-1 means none)
+	armed      bool // whether next is armed
+	lastAlpha  float64
 
 	// injection
 	hooks Hooks
@@ -0,0 +1,91 @@
+package ws
+
+import (
+	"sync"
+
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/app"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/led"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render/post"
+)
+
+// PreviewOutput is the engine output that feeds the /ws frame stream with
+// the tone-mapped preview look, while Driver gets the LED post.
+func PreviewOutput() render.OutputConfig {
+	return render.OutputConfig{Name: "preview", Post: post.Preview(), MaxFPS: 30, Enabled: true, Calib: true}
+}
+
+// frameTap keeps the latest frame written to it as 8-bit RGB; with white
+// set, as an RGBW strip with that split shows it.
+type frameTap struct {
+	mu    sync.Mutex
+	rgb   []byte
+	fresh bool
+	white *render.White
+}
+
+func (t *frameTap) Caps() render.Caps {
+	return render.Caps{Layout: render.LayoutRGB, BitDepth: 8, Order: "RGB"}
+}
+func (t *frameTap) Open() error  { return nil }
+func (t *frameTap) Blank() error { return nil }
+func (t *frameTap) Close() error { return nil }
+
+func (t *frameTap) Write(buf []render.Color) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.rgb) != len(buf)*3 {
+		t.rgb = make([]byte, len(buf)*3)
+	}
+	led.ShowRGB(t.rgb, buf, 1, t.white)
+	t.fresh = true
+	return nil
+}
+
+func (t *frameTap) setWhite(w *render.White) {
+	t.mu.Lock()
+	t.white = w
+	t.mu.Unlock()
+}
+
+// take returns a copy of the frame written since the last take, if any.
+func (t *frameTap) take() ([]byte, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.fresh {
+		return nil, false
+	}
+	t.fresh = false
+	return append([]byte(nil), t.rgb...), true
+}
+
+// SetCore installs core (with the preview output) as the running engine.
+func (s *State) SetCore(core *app.Core) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attachCore(core)
+}
+
+// attachCore adds the preview output to core and makes it current. The
+// caller holds s.mu.
+func (s *State) attachCore(core *app.Core) {
+	if s.Preview.Name == "" {
+		s.Preview = PreviewOutput()
+	}
+	s.tap = &frameTap{white: s.showWhite}
+	if err := core.Eng.AddOutput(s.Preview, s.tap); err != nil {
+		s.tap = nil
+	}
+	s.Core = core
+}
+
+// previewFrame reports whether the preview output feeds the stream and, if
+// it wrote since the last call, its frame (nil while throttled). The caller
+// holds s.mu.
+func (s *State) previewFrame() (rgb []byte, active bool) {
+	if s.tap == nil || !s.Preview.Enabled {
+		return nil, false
+	}
+	rgb, _ = s.tap.take()
+	return rgb, true
+}
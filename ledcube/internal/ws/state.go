@@ -1,7 +1,9 @@
 package ws
 
 import (
+	"cmp"
 	"encoding/json"
+	"fmt"
 	"math"
 	"net/http"
 	"sync"
@@ -10,10 +12,14 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog/log"
 
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/app"
 	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/config"
 	diag "github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/diagnostics"
 	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/layout"
 	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/led"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/power"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/render"
+	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/sequence"
 	"github.com/coreman2200/funtimes-arcaluminis/ledcube/internal/tests"
 )
 
@@ -25,9 +31,58 @@ type State struct {
 	SimOnly    bool
 
 	ConfigPath string
-	Driver     led.Driver
+	// Driver gets the LED frames; Brightness and Dither reach it through
+	// SetGain/SetDither when it has them (led.Adapter does).
+	Driver render.Driver
+	// Writer is the persisted write policy Driver was wrapped with;
+	// Outputs the persisted SPI bus split behind it, if any, APA102 the
+	// clocked-LED settings, Serial the USB-serial controller's and DMX
+	// the Art-Net/sACN patch.
+	Writer  config.Writer
+	Outputs []config.Output
+	APA102  config.APA102Cfg
+	Serial  config.SerialCfg
+	DMX     config.DMXCfg
+	// ColorOrder and SPI are the persisted wire settings Driver was built
+	// with ("" / zero = GRB on /dev/spidev0.0 at the driver defaults).
+	ColorOrder string
+	SPI        config.SPI
 
-	rgb         []byte
+	// DMXIn is the persisted console input section; dmxIn runs it on the
+	// Core while its mode is set. dimmer is the console's master dimmer
+	// (1 without one), applied on top of Brightness.
+	DMXIn   config.DMXInCfg
+	dmxIn   *app.DMXInput
+	dmxLive bool
+	dimmer  float64
+
+	// White is the persisted RGBW split; it reaches Driver through
+	// SetWhite, the power model through applyPower and, while Driver is
+	// RGBW, the streamed frames (showWhite) so they look like the strip.
+	White     config.WhiteCfg
+	showWhite *render.White
+
+	// DitherCfg holds the per-driver dither switches (persisted); Dither is
+	// the active quantizer for CurrentDriver, nil for plain rounding.
+	DitherCfg map[string]config.DitherCfg
+	Dither    *led.Dither
+
+	// Power is the supply section persisted to config.yaml; the Core's
+	// power manager is built from it.
+	Power config.PowerCfg
+
+	// Core runs the render engine + sequencer; its frames are written to
+	// Driver each tick. NewCore rebuilds it when the layout changes.
+	Core    *app.Core
+	NewCore func(l layout.Layout) (*app.Core, error)
+
+	// Preview is the engine output behind the /ws frame stream (see
+	// PreviewOutput); tap receives its frames.
+	Preview render.OutputConfig
+	tap     *frameTap
+
+	rgb         []byte         // stream frame (test pattern or quantized Eng.Out)
+	frame       []render.Color // LED frame
 	frameID     uint64
 	startTime   time.Time
 	clients     map[*websocket.Conn]bool
@@ -35,6 +90,12 @@ type State struct {
 
 	testRunner    *tests.Runner
 	CurrentDriver string
+
+	zoneLimiting map[string]bool // last seen per power zone, for edge-triggered diagnostics
+	panelHot     map[int]bool    // last seen thermal throttling per panel
+
+	stop    chan struct{} // closed by Stop
+	stopped chan struct{} // closed once RunRenderLoop returns
 }
 
 func NewState(l layout.Layout, fps int, brightness float64, simOnly bool) *State {
@@ -43,65 +104,85 @@ func NewState(l layout.Layout, fps int, brightness float64, simOnly bool) *State
 		FPS:         fps,
 		Brightness:  brightness,
 		SimOnly:     simOnly,
+		dimmer:      1,
 		rgb:         make([]byte, l.Count()*3),
+		frame:       make([]render.Color, l.Count()),
 		startTime:   time.Now(),
 		clients:     map[*websocket.Conn]bool{},
 		diagClients: map[*websocket.Conn]bool{},
+		stop:        make(chan struct{}),
+		stopped:     make(chan struct{}),
 	}
 }
 
 func (s *State) RunRenderLoop() {
+	defer close(s.stopped)
 	ticker := time.NewTicker(time.Second / time.Duration(max(1, s.FPS)))
 	defer ticker.Stop()
-	phase := 0.0
-	for range ticker.C {
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.stop:
+			return
+		}
 		s.mu.Lock()
-		n := s.Layout.Count()
-
+		var stream []byte
+		streamed := false
 		if s.testRunner != nil {
 			done := !s.testRunner.Step(s.Layout, s.rgb)
 			if done {
 				s.testRunner = nil
 				s.pushDiag(diag.Diagnostic{Severity: diag.Info, Code: "TEST.DONE", Summary: "Test complete"})
-			} else {
-				// fallthrough to frame send
 			}
-		} else {
-			// Demo effect: rotating rainbow
-			for i := 0; i < n; i++ {
-				perPanel := s.Layout.Dim.X * s.Layout.Dim.Y
-				z := i / perPanel
-				rem := i % perPanel
-				y := rem / s.Layout.Dim.X
-				x := rem % s.Layout.Dim.X
-				u := float64(x) / float64(max(1, s.Layout.Dim.X-1))
-				v := float64(y) / float64(max(1, s.Layout.Dim.Y-1))
-				w := float64(z) / float64(max(1, s.Layout.Dim.Z-1))
-				h := math.Mod(u+v+w+phase, 1.0)
-				r, g, b := hsvToRGB(h, 1.0, s.Brightness)
-				s.rgb[i*3+0] = byte(r * 255)
-				s.rgb[i*3+1] = byte(g * 255)
-				s.rgb[i*3+2] = byte(b * 255)
+			led.FromRGB(s.frame, s.rgb)
+		} else if s.Core != nil {
+			if s.dmxIn != nil {
+				s.updateDMXInput(time.Now())
+			}
+			if err := s.Core.Tick(1.0 / float64(max(1, s.FPS))); err != nil {
+				log.Debug().Err(err).Msg("render frame")
 			}
-			phase += 0.01
+			s.reportPowerZones(s.Core.Power.Last())
+			// Eng.Out already passed the power manager (white cap,
+			// soft start, amp limit), which accounts for Brightness
+			// and the console's dimmer; the driver applies them when
+			// quantizing
+			copy(s.frame, s.Core.Eng.Out)
+			led.ShowRGB(s.rgb, s.Core.Eng.Out, s.gain(), s.showWhite)
+			stream, streamed = s.previewFrame()
 		}
 
-		// Apply simple white-cap limiter before sending
-		applyWhiteCap(s.rgb, 0.85)
-
+		// Only this goroutine writes into rgb and frame (control messages
+		// replace them), so they are handed on without copies.
 		s.frameID++
-		buf := append([]byte{}, s.rgb...)
+		buf, frame := s.rgb, s.frame
 		drv := s.Driver
 		s.mu.Unlock()
 
-		// Write to hardware if driver present
+		// Write to hardware if driver present; an AsyncDriver only queues
+		// the frame and the device write overlaps the next tick
 		if drv != nil {
-			_ = drv.Write(buf)
+			if err := drv.Write(frame); err != nil {
+				log.Debug().Err(err).Msg("driver write")
+			}
+		}
+		if !streamed {
+			stream = buf
+		}
+		if stream != nil {
+			s.broadcastFrame(stream)
 		}
-		s.broadcastFrame(buf)
 	}
 }
 
+// Stop ends RunRenderLoop and waits until its last frame has been handed
+// to the driver, so the caller can blank and close it. Call it once, after
+// RunRenderLoop has started.
+func (s *State) Stop() {
+	close(s.stop)
+	<-s.stopped
+}
+
 func (s *State) HandleFramesWS(w http.ResponseWriter, r *http.Request) {
 	up := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
 	conn, err := up.Upgrade(w, r, nil)
@@ -183,6 +264,23 @@ func (s *State) HandleHealth(w http.ResponseWriter, r *http.Request) {
 		"fps":        s.FPS,
 		"brightness": s.Brightness,
 	}
+	if s.Core != nil {
+		if r := s.Core.Eng.Active(); r != nil {
+			resp["renderer"] = r.Name()
+		}
+		resp["layers"] = s.Core.Eng.Layers()
+		resp["sequencer"] = s.Core.Seq.State
+		resp["render_ms"] = s.Core.Eng.Last.TotalMS
+		resp["post"] = s.Core.Eng.Last.Post
+		resp["power"] = s.Core.Power.Last()
+		resp["outputs"] = s.Core.Eng.Outputs()
+	}
+	if s.dmxIn != nil {
+		resp["dmx_in"] = map[string]any{"mode": s.dmxIn.Mode, "live": s.dmxLive, "dimmer": s.dimmer}
+	}
+	if a, ok := s.Driver.(*render.AsyncDriver); ok {
+		resp["writer"] = a.Stats()
+	}
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
@@ -200,6 +298,8 @@ func (s *State) applyControl(msg map[string]any) {
 			s.Layout.Dim.Z = int(z)
 		}
 		s.rgb = make([]byte, s.Layout.Count()*3)
+		s.frame = make([]render.Color, s.Layout.Count())
+		s.rebuildCore()
 	}
 	if v, ok := msg["panelGapMM"].(float64); ok {
 		s.Layout.PanelGapMM = v
@@ -212,6 +312,65 @@ func (s *State) applyControl(msg map[string]any) {
 	}
 	if v, ok := msg["brightness"].(float64); ok {
 		s.Brightness = clamp(v, 0, 1)
+		if s.Core != nil {
+			s.Core.Power.SetOutputGain(s.gain())
+		}
+		s.configureDriver()
+	}
+	if v, ok := msg["power"].(map[string]any); ok {
+		if x, ok := v["limitAmps"].(float64); ok {
+			s.Power.LimitAmps = math.Max(0, x)
+		}
+		if x, ok := v["whiteCap"].(float64); ok {
+			s.Power.WhiteCap = clamp(x, 0, 1)
+		}
+		if x, ok := v["softStartMs"].(float64); ok {
+			s.Power.SoftStartMs = int(math.Max(0, x))
+		}
+		if x, ok := v["panelLimitAmps"].(float64); ok {
+			s.Power.Thermal.PanelLimitAmps = math.Max(0, x)
+		}
+		if x, ok := v["strip"].(string); ok {
+			s.Power.Strip = x
+		}
+		if s.Core != nil {
+			if err := s.applyPower(s.Core); err != nil {
+				s.pushDiag(diag.Diagnostic{Severity: diag.Warn, Code: "POWER.REJECTED", Summary: "Power settings rejected", Detail: err.Error()})
+			}
+		}
+	}
+	if v, ok := msg["dither"].(bool); ok {
+		dc := s.DitherCfg[s.CurrentDriver]
+		dc.Enabled = v
+		if t, ok := msg["ditherThreshold"].(float64); ok {
+			dc.Threshold = clamp(t, 0, 255)
+		}
+		if s.DitherCfg == nil {
+			s.DitherCfg = map[string]config.DitherCfg{}
+		}
+		s.DitherCfg[s.CurrentDriver] = dc
+		s.applyDither()
+	}
+	if v, ok := msg["white"].(map[string]any); ok {
+		wc := s.White
+		if x, ok := v["mode"].(string); ok {
+			wc.Mode = x
+		}
+		if x, ok := v["kelvin"].(float64); ok {
+			wc.Kelvin = x
+		}
+		if x, ok := v["amount"].(float64); ok {
+			wc.Amount = x
+		}
+		if _, err := app.WhiteFromConfig(wc); err != nil {
+			s.pushDiag(diag.Diagnostic{Severity: diag.Warn, Code: "WHITE.REJECTED", Summary: "White settings rejected", Detail: err.Error()})
+		} else {
+			s.White = wc
+			s.configureDriver()
+			if s.Core != nil {
+				_ = s.applyPower(s.Core)
+			}
+		}
 	}
 	if v, ok := msg["runTest"].(string); ok {
 		s.pushDiag(diag.Diagnostic{Severity: diag.Info, Code: "TEST.RUNNING", Summary: "Running test", Detail: v})
@@ -230,23 +389,505 @@ func (s *State) applyControl(msg map[string]any) {
 		}
 	}
 
+	if s.Core != nil {
+		s.applyCoreControl(msg)
+	}
+
 	// Persist config after any change
 	s.saveConfig()
 }
 
+// applyCoreControl handles renderer/preset/param/program messages, e.g.
+//
+//	{"renderer":"ocean","preset":"NightStorm"}
+//	{"preset":"Sunset"}                       // re-preset the active renderer
+//	{"params":{"TideAmp":0.3,"post.ExposureEV":1},"bools":{"FlipZ":true}}
+//	{"program":{...seq.v1|seq.v2...},"seq":"start"}  // seq: start|stop|pause|resume
+//	{"layer":{"name":"fx","renderer":"grad","preset":"Rainbow","blend":"screen","opacity":0.5}}
+//	{"removeLayer":"fx"}
+//	{"post":[{"stage":"exposure","params":{"ExposureEV":1}},{"stage":"tonemap"}]}
+//	{"postParam":{"index":0,"key":"ExposureEV","v":0.5}}
+//	{"calibration":{"global":{"gamma":[1,1.1,1]},"panels":{"2":{"matrix":[...9]}}}}  // null clears
+func (s *State) applyCoreControl(msg map[string]any) {
+	c := s.Core
+	name, hasName := msg["renderer"].(string)
+	preset, hasPreset := msg["preset"].(string)
+	if r := c.Eng.Active(); !hasName && hasPreset && r != nil {
+		name, hasName = r.Name(), true
+	}
+	if hasName {
+		c.Seq.Stop() // manual selection wins over the running program
+		if err := c.Eng.SetRenderer(name, preset, c.Reg); err != nil {
+			s.pushDiag(diag.Diagnostic{
+				Severity: diag.Warn, Code: "RENDER.UNKNOWN", Summary: "Unknown renderer",
+				Detail: err.Error(), Evidence: map[string]any{"renderer": name, "available": c.Reg.List()},
+			})
+		}
+	}
+	if v, ok := msg["params"].(map[string]any); ok {
+		for k, x := range v {
+			f, ok := x.(float64)
+			if !ok {
+				s.rejectParam(k, fmt.Errorf("param %s: expected a number, got %T", k, x))
+				continue
+			}
+			if err := setParam(c.Eng, k, f); err != nil {
+				s.rejectParam(k, err)
+			}
+		}
+	}
+	if v, ok := msg["bools"].(map[string]any); ok {
+		for k, x := range v {
+			b, ok := x.(bool)
+			if !ok {
+				s.rejectParam(k, fmt.Errorf("bool %s: expected true/false, got %T", k, x))
+				continue
+			}
+			if err := setBool(c.Eng, k, b); err != nil {
+				s.rejectParam(k, err)
+			}
+		}
+	}
+	if v, ok := msg["program"]; ok {
+		b, _ := json.Marshal(v)
+		if err := c.LoadProgram(b); err != nil {
+			s.pushDiag(diag.Diagnostic{Severity: diag.Warn, Code: "SEQ.LOAD_FAILED", Summary: "Program rejected", Detail: err.Error()})
+		}
+	}
+	if v, ok := msg["layer"].(map[string]any); ok {
+		if err := s.applyLayerControl(v); err != nil {
+			s.pushDiag(diag.Diagnostic{Severity: diag.Warn, Code: "LAYER.REJECTED", Summary: "Layer update rejected", Detail: err.Error()})
+		}
+	}
+	if v, ok := msg["removeLayer"].(string); ok {
+		if err := c.Eng.RemoveLayer(v); err != nil {
+			s.pushDiag(diag.Diagnostic{Severity: diag.Warn, Code: "LAYER.REJECTED", Summary: "Layer removal rejected", Detail: err.Error()})
+		}
+	}
+	if v, ok := msg["post"]; ok {
+		if err := applyPostChain(c.Eng, v); err != nil {
+			s.rejectPost(err)
+		}
+	}
+	if v, ok := msg["postParam"].(map[string]any); ok {
+		i, _ := v["index"].(float64)
+		key, _ := v["key"].(string)
+		x, ok := v["v"].(float64)
+		if !ok {
+			s.rejectPost(fmt.Errorf("postParam %s: expected a number, got %T", key, v["v"]))
+		} else if err := c.Eng.SetPostStageParam(int(i), key, x); err != nil {
+			s.rejectPost(err)
+		}
+	}
+	if v, ok := msg["calibration"]; ok {
+		if err := applyCalibration(c.Eng, v); err != nil {
+			s.pushDiag(diag.Diagnostic{Severity: diag.Warn, Code: "CALIB.REJECTED", Summary: "Calibration rejected", Detail: err.Error()})
+		}
+	}
+	if v, ok := msg["output"].(map[string]any); ok {
+		if err := s.applyOutput(c.Eng, v); err != nil {
+			s.pushDiag(diag.Diagnostic{Severity: diag.Warn, Code: "OUTPUT.REJECTED", Summary: "Output update rejected", Detail: err.Error()})
+		}
+	}
+	if v, ok := msg["seq"].(string); ok {
+		switch v {
+		case "start":
+			c.Seq.Start()
+		case "stop":
+			c.Seq.Stop()
+		case "pause":
+			c.Seq.Pause()
+		case "resume":
+			c.Seq.Resume()
+		default:
+			s.pushDiag(diag.Diagnostic{
+				Severity: diag.Warn, Code: "SEQ.UNKNOWN", Summary: "Unknown sequencer command",
+				Evidence: map[string]any{"cmd": v},
+			})
+		}
+	}
+}
+
+// setParam applies a /control key; "scope.name" keys (e.g. "post.ExposureEV",
+// "fx.Speed") target that scope, bare names are routed by the engine.
+func setParam(eng *render.Engine, key string, v float64) error {
+	if scope, name := sequence.SplitScope(key); scope != "" {
+		return eng.SetScopedParam(scope, name, v)
+	}
+	return eng.SetParam(key, v)
+}
+
+func setBool(eng *render.Engine, key string, b bool) error {
+	if scope, name := sequence.SplitScope(key); scope != "" {
+		return eng.SetScopedBool(scope, name, b)
+	}
+	return eng.SetBool(key, b)
+}
+
+// rejectParam reports a /control param the active renderer's schema refused.
+func (s *State) rejectParam(name string, err error) {
+	ev := map[string]any{"param": name}
+	if r := s.Core.Eng.Active(); r != nil {
+		ev["renderer"] = r.Name()
+	}
+	s.pushDiag(diag.Diagnostic{
+		Severity: diag.Warn, Code: "PARAM.REJECTED", Summary: "Parameter rejected",
+		Detail: err.Error(), Evidence: ev,
+	})
+}
+
+// applyPostChain replaces the engine's post chain from a decoded JSON array.
+func applyPostChain(eng *render.Engine, v any) error {
+	b, _ := json.Marshal(v)
+	var cfg []render.PostStageConfig
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return fmt.Errorf("post: %w", err)
+	}
+	return eng.SetPostChain(cfg)
+}
+
+// applyCalibration installs a render.CalibProfile from decoded JSON; null removes it.
+func applyCalibration(eng *render.Engine, v any) error {
+	if v == nil {
+		return eng.SetCalibration(nil)
+	}
+	b, _ := json.Marshal(v)
+	var p render.CalibProfile
+	if err := json.Unmarshal(b, &p); err != nil {
+		return fmt.Errorf("calibration: %w", err)
+	}
+	return eng.SetCalibration(&p)
+}
+
+// applyOutput overlays the fields present in v on the named output's
+// current configuration.
+func (s *State) applyOutput(eng *render.Engine, v map[string]any) error {
+	name, _ := v["name"].(string)
+	var cfg *render.OutputConfig
+	for _, o := range eng.Outputs() {
+		if o.Name == name {
+			cfg = &o.OutputConfig
+			break
+		}
+	}
+	if cfg == nil {
+		return fmt.Errorf("output not found: %q", name)
+	}
+	b, _ := json.Marshal(v)
+	if err := json.Unmarshal(b, cfg); err != nil {
+		return fmt.Errorf("output %s: %w", name, err)
+	}
+	if err := eng.SetOutput(*cfg); err != nil {
+		return err
+	}
+	if name == s.Preview.Name {
+		s.Preview = *cfg
+	}
+	return nil
+}
+
+func (s *State) rejectPost(err error) {
+	s.pushDiag(diag.Diagnostic{
+		Severity: diag.Warn, Code: "POST.REJECTED", Summary: "Post chain update rejected",
+		Detail: err.Error(), Evidence: map[string]any{"stages": postStageNames()},
+	})
+}
+
+func postStageNames() []string {
+	var out []string
+	for _, d := range render.PostStages() {
+		out = append(out, d.Name)
+	}
+	return out
+}
+
+// applyLayerControl creates the named overlay if it is new (renderer required),
+// then applies any opacity/blend/params/bools in the message.
+func (s *State) applyLayerControl(v map[string]any) error {
+	eng, reg := s.Core.Eng, s.Core.Reg
+	name, _ := v["name"].(string)
+	blend := render.BlendAlpha
+	if b, ok := v["blend"].(string); ok {
+		m, known := render.ParseBlendMode(b)
+		if !known {
+			return fmt.Errorf("unknown blend mode %q", b)
+		}
+		blend = m
+	}
+	opacity, hasOpacity := v["opacity"].(float64)
+	if r, ok := v["renderer"].(string); ok {
+		preset, _ := v["preset"].(string)
+		if !hasOpacity {
+			opacity = 1
+		}
+		_ = eng.RemoveLayer(name) // re-adding replaces the renderer
+		if err := eng.AddLayer(name, r, preset, reg, blend, opacity); err != nil {
+			return err
+		}
+	} else {
+		if _, ok := v["blend"]; ok {
+			if err := eng.SetLayerBlend(name, blend); err != nil {
+				return err
+			}
+		}
+		if hasOpacity {
+			if err := eng.SetLayerOpacity(name, opacity); err != nil {
+				return err
+			}
+		}
+	}
+	if p, ok := v["params"].(map[string]any); ok {
+		for k, x := range p {
+			if f, ok := x.(float64); ok {
+				if err := eng.SetLayerParam(name, k, f); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if p, ok := v["bools"].(map[string]any); ok {
+		for k, x := range p {
+			if b, ok := x.(bool); ok {
+				if err := eng.SetLayerBool(name, k, b); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// rebuildCore swaps in a Core sized for the current layout. The caller holds s.mu.
+func (s *State) rebuildCore() {
+	if s.NewCore == nil {
+		return
+	}
+	core, err := s.NewCore(s.Layout)
+	if err != nil {
+		s.pushDiag(diag.Diagnostic{Severity: diag.Err, Code: "RENDER.INIT_FAILED", Summary: "Engine rebuild failed", Detail: err.Error()})
+		return
+	}
+	if err := s.applyPower(core); err != nil {
+		s.pushDiag(diag.Diagnostic{Severity: diag.Warn, Code: "POWER.REJECTED", Summary: "Power zones do not fit the new layout", Detail: err.Error()})
+	}
+	core.Power.SetOutputGain(s.gain())
+	if s.Core != nil {
+		// carry the edited post chain (and the calibration, if it still
+		// fits) over to the resized engine
+		_ = core.Eng.SetPostChain(s.Core.Eng.PostChain())
+		if err := core.Eng.SetCalibration(s.Core.Eng.Calibration()); err != nil {
+			s.pushDiag(diag.Diagnostic{Severity: diag.Warn, Code: "CALIB.REJECTED", Summary: "Calibration dropped after resize", Detail: err.Error()})
+		}
+		s.Core.Close()
+	}
+	s.attachCore(core)
+	if s.dmxIn != nil {
+		// the pixel patch follows the layout
+		if err := s.startDMXInput(); err != nil {
+			s.pushDiag(diag.Diagnostic{Severity: diag.Err, Code: "DMX.INPUT_FAILED", Summary: "Console input stopped after resize", Detail: err.Error()})
+		}
+	}
+}
+
+// StartDMXInput (re)starts the console input for DMXIn on the current
+// layout and Core; an empty mode stops it.
+func (s *State) StartDMXInput() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startDMXInput()
+}
+
+func (s *State) startDMXInput() error {
+	if s.dmxIn != nil {
+		_ = s.dmxIn.Attach(nil)
+		_ = s.dmxIn.Close()
+		s.dmxIn, s.dmxLive = nil, false
+		s.setDimmer(1)
+	}
+	if s.DMXIn.Mode == "" {
+		return nil
+	}
+	in, err := app.NewDMXInput(s.DMXIn, s.Layout)
+	if err != nil {
+		return err
+	}
+	if err := in.Open(); err != nil {
+		return err
+	}
+	if s.Core != nil {
+		if err := in.Attach(s.Core); err != nil {
+			_ = in.Close()
+			return err
+		}
+	}
+	s.dmxIn = in
+	return nil
+}
+
+// updateDMXInput hands the console's latest data to the Core, reports when
+// it takes over or times out and applies its master dimmer. The caller
+// holds s.mu.
+func (s *State) updateDMXInput(now time.Time) {
+	live := s.dmxIn.Update(now)
+	if live != s.dmxLive {
+		s.dmxLive = live
+		d := diag.Diagnostic{
+			Severity: diag.Info, Code: "DMX.INPUT_LIVE", Summary: "Console input live; it drives the cube",
+			Evidence: map[string]any{"mode": s.dmxIn.Mode, "universes": s.dmxIn.Universes()},
+		}
+		if !live {
+			d.Severity, d.Code, d.Summary = diag.Warn, "DMX.INPUT_LOST", "Console input timed out; back to the local show"
+		}
+		s.pushDiag(d)
+	}
+	s.setDimmer(s.dmxIn.Dimmer())
+}
+
+// setDimmer applies the console's master dimmer. The caller holds s.mu.
+func (s *State) setDimmer(v float64) {
+	if v == s.dimmer {
+		return
+	}
+	s.dimmer = v
+	if s.Core != nil {
+		s.Core.Power.SetOutputGain(s.gain())
+	}
+	s.configureDriver()
+}
+
+// gain is Brightness under the console's master dimmer.
+func (s *State) gain() float64 {
+	return s.Brightness * s.dimmer
+}
+
+// applyPower installs s.Power on core, estimating RGBW strips with s.White.
+func (s *State) applyPower(core *app.Core) error {
+	cfg, err := power.FromConfig(s.Power)
+	if err != nil {
+		return err
+	}
+	return core.SetPower(cfg.WithWhite(s.white()))
+}
+
+// white is s.White converted; an invalid section (rejected at load) is the
+// default split.
+func (s *State) white() render.White {
+	w, err := app.WhiteFromConfig(s.White)
+	if err != nil {
+		return render.White{}
+	}
+	return w
+}
+
+// reportPowerZones emits a diagnostic when a power zone starts or stops
+// limiting, or a panel starts or stops thermal throttling, with the measured
+// load and rating as evidence. The caller holds s.mu.
+func (s *State) reportPowerZones(r power.Report) {
+	if s.zoneLimiting == nil {
+		s.zoneLimiting = map[string]bool{}
+	}
+	for _, z := range r.Zones {
+		if z.Limiting == s.zoneLimiting[z.Name] {
+			continue
+		}
+		s.zoneLimiting[z.Name] = z.Limiting
+		d := diag.Diagnostic{
+			Severity: diag.Warn, Code: "POWER.ZONE_LIMIT", Summary: "Power zone over budget; dimming it",
+			Evidence: map[string]any{"zone": z.Name, "estimated_a": z.EstimatedAmps, "limited_a": z.LimitedAmps, "limit_a": z.LimitAmps, "scale": z.Scale},
+		}
+		if !z.Limiting {
+			d.Severity, d.Code, d.Summary = diag.Info, "POWER.ZONE_OK", "Power zone back within budget"
+		}
+		s.pushDiag(d)
+	}
+	if s.panelHot == nil {
+		s.panelHot = map[int]bool{}
+	}
+	for _, p := range r.Thermal {
+		if p.Throttling == s.panelHot[p.Panel] {
+			continue
+		}
+		s.panelHot[p.Panel] = p.Throttling
+		d := diag.Diagnostic{
+			Severity: diag.Warn, Code: "POWER.THERMAL_THROTTLE", Summary: "Panel over its sustained load; throttling",
+			Detail:   "The panel's average current stayed above power.thermal.panel_limit_amps; its brightness is lowered gradually until the load drops below the hysteresis band.",
+			Evidence: map[string]any{"panel": p.Panel, "avg_a": p.AvgAmps, "scale": p.Scale, "limit_a": s.Power.Thermal.PanelLimitAmps},
+		}
+		if !p.Throttling {
+			d.Severity, d.Code, d.Summary, d.Detail = diag.Info, "POWER.THERMAL_OK", "Panel thermal throttling ended", ""
+		}
+		s.pushDiag(d)
+	}
+}
+
+// ApplyDither (re)builds the quantizer for CurrentDriver from DitherCfg and
+// hands it and Brightness to Driver.
+func (s *State) ApplyDither() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.applyDither()
+}
+
+func (s *State) applyDither() {
+	defer s.configureDriver()
+	dc, ok := s.DitherCfg[s.CurrentDriver]
+	if !ok || !dc.Enabled {
+		s.Dither = nil
+		return
+	}
+	t := dc.Threshold
+	if t <= 0 {
+		t = led.DefaultDitherThreshold
+	}
+	if s.Dither == nil {
+		s.Dither = led.NewDither(t)
+	}
+	s.Dither.Threshold = float32(t)
+}
+
+// configureDriver passes the gain, Dither and White to Driver, if it
+// takes them, and has the streamed frames show the white die when Driver is
+// RGBW. The caller holds s.mu.
+func (s *State) configureDriver() {
+	drv := s.Driver
+	if a, ok := drv.(*render.AsyncDriver); ok {
+		drv = a.Unwrap()
+	}
+	if d, ok := drv.(interface{ SetGain(float64) }); ok {
+		d.SetGain(s.gain())
+	}
+	if d, ok := drv.(interface{ SetDither(*led.Dither) }); ok {
+		d.SetDither(s.Dither)
+	}
+	s.showWhite = nil
+	if drv != nil && drv.Caps().Layout == render.LayoutRGBW {
+		w := s.white()
+		s.showWhite = &w
+		if d, ok := drv.(interface{ SetWhite(render.White) }); ok {
+			d.SetWhite(w)
+		}
+	}
+	if s.tap != nil {
+		s.tap.setWhite(s.showWhite)
+	}
+}
+
 func (s *State) saveConfig() {
 	if s.ConfigPath == "" {
 		return
 	}
 	cfg := &config.Config{
 		Driver: func() string {
-			if s.SimOnly {
+			switch {
+			case s.SimOnly:
 				return "sim"
+			case s.CurrentDriver != "":
+				return s.CurrentDriver
 			}
 			return "spi"
 		}(),
 		GPIO:            18,
-		ColorOrder:      "GRB",
+		ColorOrder:      cmp.Or(s.ColorOrder, "GRB"),
 		Brightness:      s.Brightness,
 		FPS:             s.FPS,
 		Dim:             config.Dim{X: s.Layout.Dim.X, Y: s.Layout.Dim.Y, Z: s.Layout.Dim.Z},
@@ -254,17 +895,29 @@ func (s *State) saveConfig() {
 		PanelGapMM:      s.Layout.PanelGapMM,
 		XFlipEveryRow:   s.Layout.Order.XFlipEveryRow,
 		YFlipEveryPanel: s.Layout.Order.YFlipEveryPanel,
-		Power: config.PowerCfg{
-			LimitAmps:   35.0,
-			WhiteCap:    0.85,
-			SoftStartMs: 800,
-		},
+		Power:           s.Power,
+		Writer:          s.Writer,
+		Outputs:         s.Outputs,
+		APA102:          s.APA102,
+		Serial:          s.Serial,
+		DMX:             s.DMX,
+		DMXIn:           s.DMXIn,
+		White:           s.White,
 		SPI: config.SPI{
-			Dev:     "/dev/spidev0.0",
-			SpeedHz: 2400000,
-			ResetUs: 300,
+			Dev:     cmp.Or(s.SPI.Dev, "/dev/spidev0.0"),
+			SpeedHz: s.SPI.SpeedHz,
+			ResetUs: cmp.Or(s.SPI.ResetUs, 300),
 		},
 	}
+	if len(s.DitherCfg) > 0 {
+		cfg.Dither = s.DitherCfg
+	}
+	if s.Core != nil {
+		cfg.Calibration = app.CalibToConfig(s.Core.Eng.Calibration())
+		for _, st := range s.Core.Eng.PostChain() {
+			cfg.Post = append(cfg.Post, config.PostStage{Stage: st.Stage, Params: st.Params})
+		}
+	}
 	_ = config.Save(s.ConfigPath, cfg)
 }
 
@@ -278,6 +931,14 @@ func (s *State) sendTopology(conn *websocket.Conn) {
 		"pitchMM":    s.Layout.PitchMM,
 		"driver":     s.CurrentDriver,
 	}
+	if s.Core != nil {
+		top["renderers"] = s.Core.Reg.List()
+		if r := s.Core.Eng.Active(); r != nil {
+			top["renderer"] = r.Name()
+		}
+		top["layers"] = s.Core.Eng.Layers()
+		top["sequencer"] = s.Core.Seq.State
+	}
 	b, _ := json.Marshal(top)
 	_ = conn.WriteMessage(websocket.TextMessage, b)
 }
@@ -307,28 +968,6 @@ func (s *State) pushDiag(d diag.Diagnostic) {
 	}
 }
 
-func hsvToRGB(h, s, v float64) (float64, float64, float64) {
-	i := int(h * 6.0)
-	f := h*6.0 - float64(i)
-	p := v * (1.0 - s)
-	q := v * (1.0 - f*s)
-	t := v * (1.0 - (1.0-f)*s)
-	switch i % 6 {
-	case 0:
-		return v, t, p
-	case 1:
-		return q, v, p
-	case 2:
-		return p, v, t
-	case 3:
-		return p, q, v
-	case 4:
-		return t, p, v
-	default:
-		return v, p, q
-	}
-}
-
 func clamp(x, lo, hi float64) float64 {
 	if x < lo {
 		return lo
@@ -345,34 +984,3 @@ func max(a, b int) int {
 	}
 	return b
 }
-
-// estimateCurrent returns estimated amps given rgb frame (20mA/chan full-scale)
-func estimateCurrent(rgb []byte) float64 {
-	var sum float64
-	for i := 0; i+2 < len(rgb); i += 3 {
-		sum += float64(rgb[i]) + float64(rgb[i+1]) + float64(rgb[i+2])
-	}
-	// Approx: each color up to 20mA; scale 0..255
-	return sum / 255.0 * 0.020
-}
-
-// applyWhiteCap clamps per-LED RGB so r+g+b <= whiteCap*3*255
-func applyWhiteCap(rgb []byte, whiteCap float64) {
-	if whiteCap <= 0 || whiteCap >= 1 {
-		return
-	}
-	limit := whiteCap * 3.0 * 255.0
-	for i := 0; i+2 < len(rgb); i += 3 {
-		s := float64(rgb[i]) + float64(rgb[i+1]) + float64(rgb[i+2])
-		if s > limit && s > 0 {
-			scale := limit / s
-			r := float64(rgb[i]) * scale
-			g := float64(rgb[i+1]) * scale
-			b := float64(rgb[i+2]) * scale
-			rb := byte(math.Round(r))
-			gb := byte(math.Round(g))
-			bb := byte(math.Round(b))
-			rgb[i], rgb[i+1], rgb[i+2] = rb, gb, bb
-		}
-	}
-}
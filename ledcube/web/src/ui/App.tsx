@@ -5,40 +5,15 @@ import { OrbitControls, Stats } from '@react-three/drei'
 import * as THREE from 'three'
 import create from 'zustand'
 import * as AppAPI from '../../wailsjs/go/main/App';
+import { render } from '../../wailsjs/go/models';
 
 function TinyToolbar(){
   const [preview, setPreview] = React.useState(true);
   const [renderer, setRenderer] = React.useState<"ocean"|"calib"|"solid"|"grad">("ocean");
   const [preset, setPreset] = React.useState("CalmDawn");
 
-  // universal knobs
-  const [intensity, setIntensity] = React.useState(1.0);
-  const [ev, setEV] = React.useState(3);
-  const [gamma, setGamma] = React.useState(1.6);
-  const [sat, setSat] = React.useState(1.0);
-  const [speed, setSpeed] = React.useState(1.0); // your renderers read this as a phase scale
-
-  // ocean short-list (the only per-renderer group we show for now)
-  const [oTide, setOTide] = React.useState(0.22);
-  const [oWave, setOWave] = React.useState(1.1);
-  const [oFoam, setOFoam] = React.useState(0.18);
-  const [oChop, setOChop] = React.useState(0.45);
-
-  const applyGlobals = async ()=>{
-    await AppAPI.SetParam("BaseIntensity", intensity);
-    await AppAPI.SetParam("ExposureEV", ev);
-    await AppAPI.SetParam("OutputGamma", 2.2);
-    await AppAPI.SetParam("PreviewGamma", gamma);
-    await AppAPI.SetParam("Saturation", sat);
-    await AppAPI.SetParam("TimeScale", speed); // add: renderers multiply t by TimeScale
-  };
-
-  const applyOcean = async ()=>{
-    await AppAPI.SetParam("TideAmp", oTide);
-    await AppAPI.SetParam("WaveSpeed", oWave);
-    await AppAPI.SetParam("Foaminess", oFoam);
-    await AppAPI.SetParam("Choppiness", oChop);
-  };
+  // sliders are built from the backend's param schema (scene renderer, post, global)
+  const [params, setParams] = React.useState<render.ParamInfo[]>([]);
 
   const togglePreview = async ()=>{
     const next = !preview; setPreview(next);
@@ -46,56 +21,43 @@ function TinyToolbar(){
   };
 
   const hydrateFromBackend = React.useCallback(async ()=>{
-  try {
-    const p = await AppAPI.GetParams();
-    if (typeof p.BaseIntensity === 'number') setIntensity(p.BaseIntensity);
-    if (typeof p.ExposureEV    === 'number') setEV(Math.round(p.ExposureEV));
-    if (typeof p.PreviewGamma  === 'number') setGamma(p.PreviewGamma);
-    if (typeof p.Saturation    === 'number') setSat(p.Saturation);
-    if (typeof p.TimeScale     === 'number') setSpeed(p.TimeScale);
-    if (typeof p.TideAmp       === 'number') setOTide(p.TideAmp);
-    if (typeof p.WaveSpeed     === 'number') setOWave(p.WaveSpeed);
-    if (typeof p.Foaminess     === 'number') setOFoam(p.Foaminess);
-    if (typeof p.Choppiness    === 'number') setOChop(p.Choppiness);
-  } catch(e) {
-    console.warn("GetParams failed", e);
-  }
-}, []);
-
+    try {
+      setParams(await AppAPI.GetParams());
+    } catch(e) {
+      console.warn("GetParams failed", e);
+    }
+  }, []);
 
   const oceanPresets = ["CalmDawn","SunnyDay","Sunset","NightStorm"];
   const solidPresets = ["Red","Green","Blue","White","Black"];
-  const gradPresets  = ["Rainbow","IndexSweep"];
+  const gradPresets  = ["XZ","XY","YZ","Rainbow"];
   const calibPresets = ["PanelChanSweep"];
 
   const presets = renderer==="ocean" ? oceanPresets :
                   renderer==="grad"  ? gradPresets  :
                   renderer==="calib" ? calibPresets : solidPresets;
 
-  // 1) Hydrate slider defaults from backend on mount
+  // 1) Hydrate sliders from backend on mount
   React.useEffect(()=>{ hydrateFromBackend(); }, [hydrateFromBackend]);
 
-  // 2) Debounced setter (so changes take effect without “Run”, but don’t spam)
-  const debouncedSet = React.useRef<ReturnType<typeof setTimeout>|null>(null);
-  const applyParam = (k:string, v:number)=>{
-    if (debouncedSet.current) clearTimeout(debouncedSet.current);
-    debouncedSet.current = setTimeout(()=>{ AppAPI.SetParam(k, v); }, 120);
+  // 2) Debounced setter per key (so changes take effect without “Run”, but don’t spam)
+  const timers = React.useRef<Record<string, ReturnType<typeof setTimeout>>>({});
+  const applyParam = (p:render.ParamInfo, v:number)=>{
+    const id = `${p.scope}.${p.name}`;
+    setParams(ps=>ps.map(q=>q.scope===p.scope && q.name===p.name ? {...q, value:v} : q));
+    clearTimeout(timers.current[id]);
+    timers.current[id] = setTimeout(()=>{
+      const call = p.type==="bool" ? AppAPI.SetScopedBool(p.scope, p.name, v>0.5)
+                                   : AppAPI.SetScopedParam(p.scope, p.name, v);
+      call.catch(e=>{ console.warn("SetParam rejected", p.name, e); hydrateFromBackend(); });
+    }, 120);
   };
 
-  // 3) Wire sliders to call applyParam on change
-  //   Example: Intensity slider -> applyParam("BaseIntensity", intensity)
-  //   You can keep your Range component, just pass a callback:
-  const onIntensityChange = (n:number)=>{ setIntensity(n); applyParam("BaseIntensity", n); };
-  const onEVChange        = (n:number)=>{ const m=Math.round(n); setEV(m); applyParam("ExposureEV", m); };
-  const onGammaChange     = (n:number)=>{ setGamma(n); applyParam("PreviewGamma", n); };
-  const onSatChange       = (n:number)=>{ setSat(n); applyParam("Saturation", n); };
-  const onSpeedChange     = (n:number)=>{ setSpeed(n); applyParam("TimeScale", n); };
-
-  // 4) Replace the Run button so it ONLY selects renderer/preset
+  // 3) Run ONLY selects renderer/preset, then re-reads the schema for it
   const run = async ()=>{
     await AppAPI.SeqCmd("stop");
     await AppAPI.UIRenderPreset(renderer, preset);
-    await hydrateFromBackend(); // reflect any preset-set defaults
+    await hydrateFromBackend(); // reflect the new renderer's params + preset values
   };
 
   const resetAll = async ()=>{
@@ -103,18 +65,18 @@ function TinyToolbar(){
     await hydrateFromBackend();               // refresh sliders to match
   };
 
-
-  // 5) For ocean-only sliders:
-  const onTide   = (n:number)=>{ setOTide(n); applyParam("TideAmp", n); };
-  const onWave   = (n:number)=>{ setOWave(n); applyParam("WaveSpeed", n); };
-  const onFoam   = (n:number)=>{ setOFoam(n); applyParam("Foaminess", n); };
-  const onChop   = (n:number)=>{ setOChop(n); applyParam("Choppiness", n); };
+  const groups = new Map<string, render.ParamInfo[]>();
+  for (const p of params) {
+    const g = p.group || "params";
+    if (!groups.has(g)) groups.set(g, []);
+    groups.get(g)!.push(p);
+  }
 
   return (
     <div style={{
       position:'fixed', top:300, left:8, padding:'8px 10px',
       background:'rgba(20,20,24,.9)', color:'#ddd', borderRadius:12, zIndex:1000,
-      display:'grid', gridAutoFlow:'row', gap:8, width:340
+      display:'grid', gridAutoFlow:'row', gap:8, width:340, maxHeight:'60vh', overflowY:'auto'
     }}>
       <div style={{display:'flex', gap:8}}>
         <select value={renderer} onChange={e=>{ setRenderer(e.target.value as any); }}>
@@ -133,34 +95,19 @@ function TinyToolbar(){
         </label>
       </div>
 
-      {/* Universal small row */}
-      <Row label="Intensity">
-        <Range v={intensity} set={onIntensityChange} min={0} max={2} step={0.01}/>
-      </Row>
-      <Row label="Exposure EV">
-        <Range v={ev} set={onEVChange} min={-4} max={6} step={1}/>
-      </Row>
-      <Row label="Preview Gamma">
-        <Range v={gamma} set={onGammaChange} min={1.0} max={2.4} step={0.01}/>
-      </Row>
-      <Row label="Saturation">
-        <Range v={sat} set={onSatChange} min={0} max={1} step={0.01}/>
-      </Row>
-      <Row label="Speed (TimeScale)">
-        <Range v={speed} set={onSpeedChange} min={0} max={3} step={0.05}/>
-      </Row>
-
-      {/* Tiny per-render group (only shows when needed) */}
-      {renderer==="ocean" && (
-        <div style={{marginTop:2, paddingTop:6, borderTop:'1px solid rgba(255,255,255,.12)'}}>
-          <div style={{opacity:.7, marginBottom:6}}>Ocean</div>
-            <Row label="TideAmp"><Range v={oTide} set={onTide} min={0} max={0.5} step={0.01}/></Row>
-            <Row label="WaveSpeed"><Range v={oWave} set={onWave} min={0.3} max={2.0} step={0.01}/></Row>
-            <Row label="Foaminess"><Range v={oFoam} set={onFoam} min={0} max={1.0} step={0.01}/></Row>
-            <Row label="Choppiness"><Range v={oChop} set={onChop} min={0} max={1.0} step={0.01}/></Row>
-          <button onClick={applyOcean}>Apply Ocean</button>
+      {[...groups.entries()].map(([g, ps])=>(
+        <div key={g} style={{marginTop:2, paddingTop:6, borderTop:'1px solid rgba(255,255,255,.12)'}}>
+          <div style={{opacity:.7, marginBottom:6}}>{g}</div>
+          {ps.map(p=>(
+            <Row key={`${p.scope}.${p.name}`} label={p.units ? `${p.name} (${p.units})` : p.name}>
+              {p.type==="bool"
+                ? <input type="checkbox" title={p.desc} checked={p.value>0.5} onChange={e=>applyParam(p, e.target.checked?1:0)}/>
+                : <Range v={p.value} set={n=>applyParam(p, n)} min={p.min} max={p.max}
+                    step={p.step || (p.type==="int" ? 1 : (p.max-p.min)/100)}/>}
+            </Row>
+          ))}
         </div>
-      )}
+      ))}
     </div>
   );
 }
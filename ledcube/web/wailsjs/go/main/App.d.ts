@@ -1,22 +1,64 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {render} from '../models';
+import {power} from '../models';
+
+export function AddLayer(arg1:string,arg2:string,arg3:string,arg4:string,arg5:number):Promise<void>;
 
 export function ArmNext(arg1:string,arg2:string):Promise<void>;
 
-export function GetParams():Promise<Record<string, number>>;
+export function GetCalibration():Promise<render.CalibProfile>;
+
+export function GetLayerParams(arg1:string):Promise<Array<render.ParamInfo>>;
+
+export function GetParams():Promise<Array<render.ParamInfo>>;
+
+export function GetPostChain():Promise<Array<render.PostStageConfig>>;
+
+export function GetPower():Promise<power.Report>;
+
+export function ListLayers():Promise<Array<render.LayerInfo>>;
+
+export function ListOutputs():Promise<Array<render.OutputInfo>>;
+
+export function ListPostStages():Promise<Array<render.PostStageDef>>;
 
 export function ListRenderers():Promise<Array<string>>;
 
+export function ListStrips():Promise<Array<render.CurrentModel>>;
+
 export function LoadProgram(arg1:string):Promise<void>;
 
+export function RemoveLayer(arg1:string):Promise<void>;
+
 export function RunTest(arg1:string):Promise<string>;
 
 export function SeqCmd(arg1:string):Promise<void>;
 
 export function SetBool(arg1:string,arg2:boolean):Promise<void>;
 
+export function SetCalibration(arg1:render.CalibProfile):Promise<void>;
+
+export function SetLayerBlend(arg1:string,arg2:string):Promise<void>;
+
+export function SetLayerOpacity(arg1:string,arg2:number):Promise<void>;
+
+export function SetLayerParam(arg1:string,arg2:string,arg3:number):Promise<void>;
+
+export function SetOutput(arg1:render.OutputConfig):Promise<void>;
+
 export function SetParam(arg1:string,arg2:number):Promise<void>;
 
+export function SetPostChain(arg1:Array<render.PostStageConfig>):Promise<void>;
+
+export function SetPostStageParam(arg1:number,arg2:string,arg3:number):Promise<void>;
+
+export function SetPreviewDither(arg1:boolean):Promise<void>;
+
+export function SetScopedBool(arg1:string,arg2:string,arg3:boolean):Promise<void>;
+
+export function SetScopedParam(arg1:string,arg2:string,arg3:number):Promise<void>;
+
 export function SetRenderer(arg1:string,arg2:string):Promise<void>;
 
 export function UIRenderPreset(arg1:string,arg2:string):Promise<void>;
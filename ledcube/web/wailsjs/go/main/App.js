@@ -2,22 +2,62 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddLayer(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['AddLayer'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function ArmNext(arg1, arg2) {
   return window['go']['main']['App']['ArmNext'](arg1, arg2);
 }
 
+export function GetCalibration() {
+  return window['go']['main']['App']['GetCalibration']();
+}
+
+export function GetLayerParams(arg1) {
+  return window['go']['main']['App']['GetLayerParams'](arg1);
+}
+
 export function GetParams() {
   return window['go']['main']['App']['GetParams']();
 }
 
+export function GetPostChain() {
+  return window['go']['main']['App']['GetPostChain']();
+}
+
+export function GetPower() {
+  return window['go']['main']['App']['GetPower']();
+}
+
+export function ListLayers() {
+  return window['go']['main']['App']['ListLayers']();
+}
+
+export function ListOutputs() {
+  return window['go']['main']['App']['ListOutputs']();
+}
+
+export function ListPostStages() {
+  return window['go']['main']['App']['ListPostStages']();
+}
+
 export function ListRenderers() {
   return window['go']['main']['App']['ListRenderers']();
 }
 
+export function ListStrips() {
+  return window['go']['main']['App']['ListStrips']();
+}
+
 export function LoadProgram(arg1) {
   return window['go']['main']['App']['LoadProgram'](arg1);
 }
 
+export function RemoveLayer(arg1) {
+  return window['go']['main']['App']['RemoveLayer'](arg1);
+}
+
 export function RunTest(arg1) {
   return window['go']['main']['App']['RunTest'](arg1);
 }
@@ -30,10 +70,50 @@ export function SetBool(arg1, arg2) {
   return window['go']['main']['App']['SetBool'](arg1, arg2);
 }
 
+export function SetCalibration(arg1) {
+  return window['go']['main']['App']['SetCalibration'](arg1);
+}
+
+export function SetLayerBlend(arg1, arg2) {
+  return window['go']['main']['App']['SetLayerBlend'](arg1, arg2);
+}
+
+export function SetLayerOpacity(arg1, arg2) {
+  return window['go']['main']['App']['SetLayerOpacity'](arg1, arg2);
+}
+
+export function SetLayerParam(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetLayerParam'](arg1, arg2, arg3);
+}
+
+export function SetOutput(arg1) {
+  return window['go']['main']['App']['SetOutput'](arg1);
+}
+
 export function SetParam(arg1, arg2) {
   return window['go']['main']['App']['SetParam'](arg1, arg2);
 }
 
+export function SetPostChain(arg1) {
+  return window['go']['main']['App']['SetPostChain'](arg1);
+}
+
+export function SetPostStageParam(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetPostStageParam'](arg1, arg2, arg3);
+}
+
+export function SetPreviewDither(arg1) {
+  return window['go']['main']['App']['SetPreviewDither'](arg1);
+}
+
+export function SetScopedBool(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetScopedBool'](arg1, arg2, arg3);
+}
+
+export function SetScopedParam(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetScopedParam'](arg1, arg2, arg3);
+}
+
 export function SetRenderer(arg1, arg2) {
   return window['go']['main']['App']['SetRenderer'](arg1, arg2);
 }
@@ -0,0 +1,392 @@
+export namespace power {
+	
+	export class PanelThermal {
+	    panel: number;
+	    avg_a: number;
+	    scale: number;
+	    throttling: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new PanelThermal(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.panel = source["panel"];
+	        this.avg_a = source["avg_a"];
+	        this.scale = source["scale"];
+	        this.throttling = source["throttling"];
+	    }
+	}
+	export class ZoneReport {
+	    name: string;
+	    estimated_a: number;
+	    limited_a: number;
+	    limit_a: number;
+	    scale: number;
+	    limiting: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ZoneReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.estimated_a = source["estimated_a"];
+	        this.limited_a = source["limited_a"];
+	        this.limit_a = source["limit_a"];
+	        this.scale = source["scale"];
+	        this.limiting = source["limiting"];
+	    }
+	}
+	export class Report {
+	    strip: string;
+	    idle_a: number;
+	    estimated_a: number;
+	    limited_a: number;
+	    limit_a: number;
+	    ramp: number;
+	    scale: number;
+	    limiting: boolean;
+	    zones?: ZoneReport[];
+	    thermal?: PanelThermal[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Report(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.strip = source["strip"];
+	        this.idle_a = source["idle_a"];
+	        this.estimated_a = source["estimated_a"];
+	        this.limited_a = source["limited_a"];
+	        this.limit_a = source["limit_a"];
+	        this.ramp = source["ramp"];
+	        this.scale = source["scale"];
+	        this.limiting = source["limiting"];
+	        this.zones = this.convertValues(source["zones"], ZoneReport);
+	        this.thermal = this.convertValues(source["thermal"], PanelThermal);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace render {
+	
+	export class CalibEntry {
+	    matrix: number[];
+	    gamma: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CalibEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.matrix = source["matrix"];
+	        this.gamma = source["gamma"];
+	    }
+	}
+	export class CalibProfile {
+	    global?: CalibEntry;
+	    panels?: Record<number, CalibEntry>;
+	    leds?: Record<number, CalibEntry>;
+	
+	    static createFrom(source: any = {}) {
+	        return new CalibProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.global = this.convertValues(source["global"], CalibEntry);
+	        this.panels = this.convertValues(source["panels"], CalibEntry, true);
+	        this.leds = this.convertValues(source["leds"], CalibEntry, true);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CurrentModel {
+	    name: string;
+	    chan_ma: number[];
+	    idle_ma: number;
+	    exponent: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CurrentModel(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.chan_ma = source["chan_ma"];
+	        this.idle_ma = source["idle_ma"];
+	        this.exponent = source["exponent"];
+	    }
+	}
+	export class LayerInfo {
+	    name: string;
+	    renderer: string;
+	    preset?: string;
+	    opacity: number;
+	    blend: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LayerInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.renderer = source["renderer"];
+	        this.preset = source["preset"];
+	        this.opacity = source["opacity"];
+	        this.blend = source["blend"];
+	    }
+	}
+	
+	export class ParamInfo {
+	    name: string;
+	    type: string;
+	    min: number;
+	    max: number;
+	    default: number;
+	    step?: number;
+	    units?: string;
+	    desc?: string;
+	    group?: string;
+	    scope: string;
+	    value: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ParamInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.min = source["min"];
+	        this.max = source["max"];
+	        this.default = source["default"];
+	        this.step = source["step"];
+	        this.units = source["units"];
+	        this.desc = source["desc"];
+	        this.group = source["group"];
+	        this.scope = source["scope"];
+	        this.value = source["value"];
+	    }
+	}
+	export class ParamSpec {
+	    name: string;
+	    type: string;
+	    min: number;
+	    max: number;
+	    default: number;
+	    step?: number;
+	    units?: string;
+	    desc?: string;
+	    group?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ParamSpec(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.min = source["min"];
+	        this.max = source["max"];
+	        this.default = source["default"];
+	        this.step = source["step"];
+	        this.units = source["units"];
+	        this.desc = source["desc"];
+	        this.group = source["group"];
+	    }
+	}
+	export class PostStageConfig {
+	    stage: string;
+	    params?: Record<string, number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new PostStageConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.stage = source["stage"];
+	        this.params = source["params"];
+	    }
+	}
+	export class PostStageDef {
+	    name: string;
+	    desc?: string;
+	    params?: ParamSpec[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PostStageDef(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.desc = source["desc"];
+	        this.params = this.convertValues(source["params"], ParamSpec);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PostTiming {
+	    stage: string;
+	    ms: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PostTiming(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.stage = source["stage"];
+	        this.ms = source["ms"];
+	    }
+	}
+	export class OutputConfig {
+	    name: string;
+	    post: PostStageConfig[];
+	    maxFps: number;
+	    enabled: boolean;
+	    calibrate: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new OutputConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.post = this.convertValues(source["post"], PostStageConfig);
+	        this.maxFps = source["maxFps"];
+	        this.enabled = source["enabled"];
+	        this.calibrate = source["calibrate"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class OutputInfo {
+	    name: string;
+	    post: PostStageConfig[];
+	    maxFps: number;
+	    enabled: boolean;
+	    calibrate: boolean;
+	    frames: number;
+	    skipped: number;
+	    lastMs: number;
+	    timing?: PostTiming[];
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OutputInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.post = this.convertValues(source["post"], PostStageConfig);
+	        this.maxFps = source["maxFps"];
+	        this.enabled = source["enabled"];
+	        this.calibrate = source["calibrate"];
+	        this.frames = source["frames"];
+	        this.skipped = source["skipped"];
+	        this.lastMs = source["lastMs"];
+	        this.timing = this.convertValues(source["timing"], PostTiming);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}